@@ -2,18 +2,26 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	rms "github.com/andibalo/payd-test/backend"
 	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/repository"
+	"github.com/andibalo/payd-test/backend/internal/service"
 	"github.com/andibalo/payd-test/backend/pkg/db"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 )
 
+// retentionCommand runs the retention job once instead of starting the server.
+const retentionCommand = "retention"
+
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -22,6 +30,10 @@ func main() {
 
 	database := db.InitDB(cfg)
 
+	if len(os.Args) > 1 && os.Args[1] == retentionCommand {
+		os.Exit(runRetention(ctx, cfg, database))
+	}
+
 	server := rms.NewServer(cfg, database)
 
 	cfg.Logger().Info(fmt.Sprintf("Server starting at port %s", cfg.AppAddress()))
@@ -32,12 +44,21 @@ func main() {
 		}
 	}()
 
+	go server.StartShiftRequestExpiryJob(ctx)
+
+	if cfg.GetFlags().EnableRetentionJob {
+		go server.StartRetentionJob(ctx)
+	}
+
 	quit := make(chan os.Signal, 1)
 
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	cfg.Logger().Info("shutting down gracefully, press Ctrl+C again to force")
 
+	// stop the background jobs before the database goes away
+	cancel()
+
 	// The context is used to inform the server it has 5 seconds to finish
 	// the request it is currently handling
 	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
@@ -50,3 +71,24 @@ func main() {
 
 	cfg.Logger().Info("Server exiting")
 }
+
+// runRetention purges and archives once, prints what it removed and returns the exit code.
+func runRetention(ctx context.Context, cfg config.Config, database *sql.DB) int {
+	defer database.Close()
+
+	retentionSvc := service.NewRetentionService(cfg, repository.NewRetentionRepository(database))
+
+	result, err := retentionSvc.RunRetention(ctx)
+	if err != nil {
+		return 1
+	}
+
+	report, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return 1
+	}
+
+	fmt.Println(string(report))
+
+	return 0
+}
@@ -0,0 +1,64 @@
+package v1
+
+import (
+	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/middleware"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/internal/service"
+	"github.com/andibalo/payd-test/backend/pkg/apperr"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type AuditLogController struct {
+	cfg         config.Config
+	auditLogSvc service.AuditLogService
+}
+
+func NewAuditLogController(cfg config.Config, auditLogSvc service.AuditLogService) *AuditLogController {
+
+	return &AuditLogController{
+		cfg:         cfg,
+		auditLogSvc: auditLogSvc,
+	}
+}
+
+func (h *AuditLogController) AddRoutes(r *gin.Engine) {
+	ar := r.Group("/api/v1/audit", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg))
+
+	ar.GET("", h.GetAuditLogList)
+}
+
+// GetAuditLogList returns the change history, newest first, filtered by entity, actor
+// and time range.
+func (h *AuditLogController) GetAuditLogList(c *gin.Context) {
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var req request.GetAuditLogListReq
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetAuditLogList] Failed to bind query parameters", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	req.UserEmail = claims.Email
+
+	auditLogs, err := h.auditLogSvc.GetAuditLogList(c.Request.Context(), req)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetAuditLogList] Failed to get audit log list", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, auditLogs, nil)
+	return
+}
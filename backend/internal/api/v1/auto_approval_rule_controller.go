@@ -0,0 +1,168 @@
+package v1
+
+import (
+	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/middleware"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/internal/service"
+	"github.com/andibalo/payd-test/backend/pkg"
+	"github.com/andibalo/payd-test/backend/pkg/apperr"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type AutoApprovalRuleController struct {
+	cfg                 config.Config
+	autoApprovalRuleSvc service.AutoApprovalRuleService
+}
+
+func NewAutoApprovalRuleController(cfg config.Config, autoApprovalRuleSvc service.AutoApprovalRuleService) *AutoApprovalRuleController {
+
+	return &AutoApprovalRuleController{
+		cfg:                 cfg,
+		autoApprovalRuleSvc: autoApprovalRuleSvc,
+	}
+}
+
+func (h *AutoApprovalRuleController) AddRoutes(r *gin.Engine) {
+	ar := r.Group("/api/v1/auto-approval-rule", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg))
+
+	ar.GET("", h.GetAutoApprovalRuleList)
+	ar.GET("/:id", h.GetAutoApprovalRuleByID)
+	ar.POST("", h.CreateAutoApprovalRule)
+	ar.PUT("/:id", h.UpdateAutoApprovalRuleByID)
+	ar.DELETE("/:id", h.DeleteAutoApprovalRuleByID)
+}
+
+func (h *AutoApprovalRuleController) GetAutoApprovalRuleList(c *gin.Context) {
+	var req request.GetAutoApprovalRuleListReq
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetAutoApprovalRuleList] Failed to bind query parameters", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	rules, err := h.autoApprovalRuleSvc.GetAutoApprovalRuleList(c.Request.Context(), req)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetAutoApprovalRuleList] Failed to get auto-approval rule list", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, rules, nil)
+	return
+}
+
+func (h *AutoApprovalRuleController) GetAutoApprovalRuleByID(c *gin.Context) {
+
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetAutoApprovalRuleByID] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	rule, err := h.autoApprovalRuleSvc.GetAutoApprovalRuleByID(c.Request.Context(), id)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetAutoApprovalRuleByID] Failed to get auto-approval rule", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, rule, nil)
+	return
+}
+
+func (h *AutoApprovalRuleController) CreateAutoApprovalRule(c *gin.Context) {
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.CreateAutoApprovalRuleReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CreateAutoApprovalRule] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserEmail = claims.Email
+
+	rule, err := h.autoApprovalRuleSvc.CreateAutoApprovalRule(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CreateAutoApprovalRule] Failed to create auto-approval rule", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, rule, nil)
+	return
+}
+
+func (h *AutoApprovalRuleController) UpdateAutoApprovalRuleByID(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[UpdateAutoApprovalRuleByID] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.UpdateAutoApprovalRuleReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[UpdateAutoApprovalRuleByID] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserEmail = claims.Email
+
+	rule, err := h.autoApprovalRuleSvc.UpdateAutoApprovalRuleByID(c.Request.Context(), id, data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[UpdateAutoApprovalRuleByID] Failed to update auto-approval rule", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, rule, nil)
+	return
+}
+
+func (h *AutoApprovalRuleController) DeleteAutoApprovalRuleByID(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[DeleteAutoApprovalRuleByID] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	err = h.autoApprovalRuleSvc.DeleteAutoApprovalRuleByID(c.Request.Context(), id, claims.Email)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[DeleteAutoApprovalRuleByID] Failed to delete auto-approval rule", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, nil, nil)
+	return
+}
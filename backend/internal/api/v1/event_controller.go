@@ -0,0 +1,68 @@
+package v1
+
+import (
+	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/middleware"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/internal/service"
+	"github.com/andibalo/payd-test/backend/pkg/apperr"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type EventController struct {
+	cfg      config.Config
+	eventSvc service.EventService
+}
+
+func NewEventController(cfg config.Config, eventSvc service.EventService) *EventController {
+
+	return &EventController{
+		cfg:      cfg,
+		eventSvc: eventSvc,
+	}
+}
+
+func (h *EventController) AddRoutes(r *gin.Engine) {
+	er := r.Group("/api/v1/event", middleware.JwtMiddleware(h.cfg))
+
+	er.GET("", h.GetEventList)
+}
+
+// GetEventList returns the caller's own events. Admins may look at any worker's events
+// through user_id, or at every event when it is left out.
+func (h *EventController) GetEventList(c *gin.Context) {
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var req request.GetEventListReq
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetEventList] Failed to bind query parameters", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	if !middleware.IsAdmin(claims) {
+		req.UserID = claims.ID
+	}
+
+	req.UserEmail = claims.Email
+
+	events, err := h.eventSvc.GetEventList(c.Request.Context(), req)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetEventList] Failed to get event list", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, events, nil)
+	return
+}
@@ -0,0 +1,168 @@
+package v1
+
+import (
+	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/middleware"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/internal/service"
+	"github.com/andibalo/payd-test/backend/pkg"
+	"github.com/andibalo/payd-test/backend/pkg/apperr"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type LocationController struct {
+	cfg         config.Config
+	locationSvc service.LocationService
+}
+
+func NewLocationController(cfg config.Config, locationSvc service.LocationService) *LocationController {
+
+	return &LocationController{
+		cfg:         cfg,
+		locationSvc: locationSvc,
+	}
+}
+
+func (h *LocationController) AddRoutes(r *gin.Engine) {
+	lr := r.Group("/api/v1/location", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg))
+
+	lr.GET("", h.GetLocationList)
+	lr.GET("/:id", h.GetLocationByID)
+	lr.POST("", h.CreateLocation)
+	lr.PUT("/:id", h.UpdateLocationByID)
+	lr.DELETE("/:id", h.DeleteLocationByID)
+}
+
+func (h *LocationController) GetLocationList(c *gin.Context) {
+	var req request.GetLocationListReq
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetLocationList] Failed to bind query parameters", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	locations, err := h.locationSvc.GetLocationList(c.Request.Context(), req)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetLocationList] Failed to get location list", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, locations, nil)
+	return
+}
+
+func (h *LocationController) GetLocationByID(c *gin.Context) {
+
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetLocationByID] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	location, err := h.locationSvc.GetLocationByID(c.Request.Context(), id)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetLocationByID] Failed to get location", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, location, nil)
+	return
+}
+
+func (h *LocationController) CreateLocation(c *gin.Context) {
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.CreateLocationReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CreateLocation] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserEmail = claims.Email
+
+	location, err := h.locationSvc.CreateLocation(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CreateLocation] Failed to create location", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, location, nil)
+	return
+}
+
+func (h *LocationController) UpdateLocationByID(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[UpdateLocationByID] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.UpdateLocationReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[UpdateLocationByID] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserEmail = claims.Email
+
+	location, err := h.locationSvc.UpdateLocationByID(c.Request.Context(), id, data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[UpdateLocationByID] Failed to update location", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, location, nil)
+	return
+}
+
+func (h *LocationController) DeleteLocationByID(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[DeleteLocationByID] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	err = h.locationSvc.DeleteLocationByID(c.Request.Context(), id, claims.Email)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[DeleteLocationByID] Failed to delete location", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, nil, nil)
+	return
+}
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/constants"
 	"github.com/andibalo/payd-test/backend/internal/middleware"
 	"github.com/andibalo/payd-test/backend/internal/request"
 	"github.com/andibalo/payd-test/backend/internal/response"
@@ -12,7 +13,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/samber/oops"
 	"go.uber.org/zap"
+	"io"
 	"net/http"
+	"path/filepath"
+	"strings"
 )
 
 type ShiftController struct {
@@ -32,15 +36,28 @@ func (h *ShiftController) AddRoutes(r *gin.Engine) {
 	ar := r.Group("/api/v1/shift")
 
 	ar.POST("", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.CreateShift)
+	ar.POST("/import", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.ImportShifts)
+	ar.POST("/copy", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.CopyShiftRoster)
+	ar.GET("/trash", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.GetDeletedShiftList)
 	ar.GET("/:id", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.GetShiftByID)
-	ar.GET("", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.GetShiftList)
+	ar.GET("/:id/queue", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.GetShiftRequestQueue)
+	ar.GET("", middleware.JwtMiddleware(h.cfg), h.GetShiftList)
+	ar.POST("/publish", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.PublishShifts)
+	ar.GET("/cost", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.GetShiftCost)
+	ar.PUT("/:id/complete", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.CompleteShiftByID)
+	ar.PUT("/:id/cancel", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.CancelShiftByID)
+	ar.PUT("/:id/restore", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.RestoreShiftByID)
 	ar.PUT("/:id", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.UpdateShiftByID)
 	ar.DELETE("/:id", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.DeleteShiftByID)
 	ar.GET("/assignment", middleware.JwtMiddleware(h.cfg), h.GetShiftAssignmentsList)
+	ar.PUT("/assignment/:id/release", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.ReleaseShiftAssignment)
 	ar.GET("/request", middleware.JwtMiddleware(h.cfg), h.GetShiftRequestList)
 	ar.POST("/request", middleware.JwtMiddleware(h.cfg), h.CreateShiftRequest)
+	ar.PUT("/request/approve", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.BulkApproveShiftRequests)
+	ar.PUT("/request/reject", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.BulkRejectShiftRequests)
 	ar.PUT("/request/:id/approve", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.ApproveShiftRequest)
 	ar.PUT("/request/:id/reject", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.RejectShiftRequest)
+	ar.PUT("/request/:id/withdraw", middleware.JwtMiddleware(h.cfg), h.WithdrawShiftRequest)
 }
 
 func (h *ShiftController) CreateShift(c *gin.Context) {
@@ -74,6 +91,111 @@ func (h *ShiftController) CreateShift(c *gin.Context) {
 	return
 }
 
+// ImportShifts accepts either a multipart "file" upload, whose format follows the file
+// extension, or a raw CSV/JSON body, whose format follows the format query or Content-Type.
+func (h *ShiftController) ImportShifts(c *gin.Context) {
+	//_, endFunc := trace.Start(c.Copy().Request.Context(), "ShiftController.ImportShifts", "controller")
+	//defer endFunc()
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.ImportShiftReq
+
+	if err := c.ShouldBindQuery(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[ImportShifts] Failed to bind query", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	var body io.Reader = c.Request.Body
+
+	if c.ContentType() == gin.MIMEMultipartPOSTForm {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[ImportShifts] Failed to get import file", zap.Error(err))
+			httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Import file is required"))
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[ImportShifts] Failed to open import file", zap.Error(err))
+			httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Failed to open import file"))
+			return
+		}
+		defer file.Close()
+
+		body = file
+		if data.Format == "" {
+			data.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+		}
+	}
+
+	if data.Format == "" {
+		switch c.ContentType() {
+		case "text/csv":
+			data.Format = request.ShiftImportFormatCSV
+		case gin.MIMEJSON:
+			data.Format = request.ShiftImportFormatJSON
+		}
+	}
+
+	rows, err := request.ParseImportShiftRows(data.Format, body)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[ImportShifts] Failed to parse import file", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("%s", err))
+		return
+	}
+
+	data.Rows = rows
+	data.UserEmail = claims.Email
+
+	result, err := h.shiftSvc.ImportShifts(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[ImportShifts] Failed to import shifts", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, result, nil)
+	return
+}
+
+func (h *ShiftController) CopyShiftRoster(c *gin.Context) {
+	//_, endFunc := trace.Start(c.Copy().Request.Context(), "ShiftController.CopyShiftRoster", "controller")
+	//defer endFunc()
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.CopyShiftRosterReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CopyShiftRoster] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserEmail = claims.Email
+
+	result, err := h.shiftSvc.CopyShiftRoster(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CopyShiftRoster] Failed to copy shift roster", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, result, nil)
+	return
+}
+
 func (h *ShiftController) GetShiftByID(c *gin.Context) {
 
 	id, err := pkg.GetIntParam(c, "id")
@@ -90,10 +212,30 @@ func (h *ShiftController) GetShiftByID(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", pkg.FormatETag(shift.Version))
 	httpresp.HttpRespSuccess(c, shift, nil)
 	return
 }
 
+func (h *ShiftController) GetShiftRequestQueue(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetShiftRequestQueue] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	queue, err := h.shiftSvc.GetShiftRequestQueue(c.Request.Context(), id)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetShiftRequestQueue] Failed to get shift request queue", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, queue, nil)
+	return
+}
+
 func (h *ShiftController) GetShiftList(c *gin.Context) {
 	var req request.GetShiftListReq
 
@@ -103,6 +245,11 @@ func (h *ShiftController) GetShiftList(c *gin.Context) {
 		return
 	}
 
+	// Workers only ever see the published roster.
+	if !middleware.IsAdmin(middleware.ParseToken(c)) {
+		req.Status = constants.SHIFT_STATUS_PUBLISHED
+	}
+
 	shifts, err := h.shiftSvc.GetShiftList(c.Request.Context(), req)
 	if err != nil {
 		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetShiftList] Failed to get shift list", zap.Error(err))
@@ -114,6 +261,30 @@ func (h *ShiftController) GetShiftList(c *gin.Context) {
 	return
 }
 
+// GetDeletedShiftList lists the soft-deleted shifts, newest deletion first unless sort_by
+// says otherwise. It takes the same filters as GetShiftList.
+func (h *ShiftController) GetDeletedShiftList(c *gin.Context) {
+	var req request.GetShiftListReq
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetDeletedShiftList] Failed to bind query parameters", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	req.Deleted = true
+
+	shifts, err := h.shiftSvc.GetShiftList(c.Request.Context(), req)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetDeletedShiftList] Failed to get deleted shift list", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, shifts, nil)
+	return
+}
+
 func (h *ShiftController) UpdateShiftByID(c *gin.Context) {
 	id, err := pkg.GetIntParam(c, "id")
 	if err != nil {
@@ -122,6 +293,12 @@ func (h *ShiftController) UpdateShiftByID(c *gin.Context) {
 		return
 	}
 
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
 	var data request.UpdateShiftReq
 
 	if err := c.ShouldBindJSON(&data); err != nil {
@@ -130,17 +307,178 @@ func (h *ShiftController) UpdateShiftByID(c *gin.Context) {
 		return
 	}
 
-	err = h.shiftSvc.UpdateShiftByID(c.Request.Context(), id, data)
+	data.UserEmail = claims.Email
+
+	data.Version, err = pkg.GetIfMatchVersion(c)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[UpdateShiftByID] Invalid If-Match header", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	shift, err := h.shiftSvc.UpdateShiftByID(c.Request.Context(), id, data)
 	if err != nil {
 		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[UpdateShiftByID] Failed to update shift", zap.Error(err))
 		httpresp.HttpRespError(c, err)
 		return
 	}
 
+	c.Header("ETag", pkg.FormatETag(shift.Version))
+	httpresp.HttpRespSuccess(c, shift, nil)
+	return
+}
+
+func (h *ShiftController) PublishShifts(c *gin.Context) {
+	//_, endFunc := trace.Start(c.Copy().Request.Context(), "ShiftController.PublishShifts", "controller")
+	//defer endFunc()
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.PublishShiftsReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[PublishShifts] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserEmail = claims.Email
+
+	result, err := h.shiftSvc.PublishShifts(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[PublishShifts] Failed to publish shifts", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, result, nil)
+	return
+}
+
+func (h *ShiftController) GetShiftCost(c *gin.Context) {
+	//_, endFunc := trace.Start(c.Copy().Request.Context(), "ShiftController.GetShiftCost", "controller")
+	//defer endFunc()
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.GetShiftCostReq
+
+	if err := c.ShouldBindQuery(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetShiftCost] Failed to bind query parameters", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserEmail = claims.Email
+
+	result, err := h.shiftSvc.GetShiftCost(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetShiftCost] Failed to get shift cost", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, result, nil)
+	return
+}
+
+func (h *ShiftController) CompleteShiftByID(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CompleteShiftByID] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	err = h.shiftSvc.CompleteShiftByID(c.Request.Context(), id, claims.Email)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CompleteShiftByID] Failed to complete shift", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
 	httpresp.HttpRespSuccess(c, nil, nil)
 	return
 }
 
+func (h *ShiftController) CancelShiftByID(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CancelShiftByID] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.CancelShiftReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CancelShiftByID] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.ShiftID = id
+	data.UserEmail = claims.Email
+
+	result, err := h.shiftSvc.CancelShiftByID(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CancelShiftByID] Failed to cancel shift", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, result, nil)
+	return
+}
+
+func (h *ShiftController) RestoreShiftByID(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[RestoreShiftByID] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	result, err := h.shiftSvc.RestoreShiftByID(c.Request.Context(), request.RestoreShiftReq{
+		ShiftID:   id,
+		UserEmail: claims.Email,
+	})
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[RestoreShiftByID] Failed to restore shift", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, result, nil)
+	return
+}
+
 func (h *ShiftController) DeleteShiftByID(c *gin.Context) {
 	id, err := pkg.GetIntParam(c, "id")
 	if err != nil {
@@ -218,14 +556,22 @@ func (h *ShiftController) ApproveShiftRequest(c *gin.Context) {
 	data.UserEmail = claims.Email
 	data.RequestedShiftID = id
 
-	err = h.shiftSvc.ApproveShiftRequest(c.Request.Context(), data)
+	data.Version, err = pkg.GetIfMatchVersion(c)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[ApproveShiftRequest] Invalid If-Match header", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	shiftRequest, err := h.shiftSvc.ApproveShiftRequest(c.Request.Context(), data)
 	if err != nil {
 		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[ApproveShiftRequest] Failed to approve shift request", zap.Error(err))
 		httpresp.HttpRespError(c, err)
 		return
 	}
 
-	httpresp.HttpRespSuccess(c, nil, nil)
+	c.Header("ETag", pkg.FormatETag(shiftRequest.Version))
+	httpresp.HttpRespSuccess(c, shiftRequest, nil)
 	return
 }
 
@@ -256,14 +602,156 @@ func (h *ShiftController) RejectShiftRequest(c *gin.Context) {
 	data.UserEmail = claims.Email
 	data.RequestedShiftID = id
 
-	err = h.shiftSvc.RejectShiftRequest(c.Request.Context(), data)
+	data.Version, err = pkg.GetIfMatchVersion(c)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[RejectShiftRequest] Invalid If-Match header", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	shiftRequest, err := h.shiftSvc.RejectShiftRequest(c.Request.Context(), data)
 	if err != nil {
 		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[RejectShiftRequest] Failed to reject shift request", zap.Error(err))
 		httpresp.HttpRespError(c, err)
 		return
 	}
 
-	httpresp.HttpRespSuccess(c, nil, nil)
+	c.Header("ETag", pkg.FormatETag(shiftRequest.Version))
+	httpresp.HttpRespSuccess(c, shiftRequest, nil)
+	return
+}
+
+func (h *ShiftController) BulkApproveShiftRequests(c *gin.Context) {
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.BulkShiftRequestDecisionReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[BulkApproveShiftRequests] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserEmail = claims.Email
+
+	result, err := h.shiftSvc.BulkApproveShiftRequests(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[BulkApproveShiftRequests] Failed to approve shift requests", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, result, nil)
+	return
+}
+
+func (h *ShiftController) BulkRejectShiftRequests(c *gin.Context) {
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.BulkShiftRequestDecisionReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[BulkRejectShiftRequests] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserEmail = claims.Email
+
+	result, err := h.shiftSvc.BulkRejectShiftRequests(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[BulkRejectShiftRequests] Failed to reject shift requests", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, result, nil)
+	return
+}
+
+func (h *ShiftController) WithdrawShiftRequest(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[WithdrawShiftRequest] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	data := request.WithdrawShiftRequestReq{
+		RequestedShiftID: id,
+		UserID:           claims.ID,
+		UserEmail:        claims.Email,
+	}
+
+	data.Version, err = pkg.GetIfMatchVersion(c)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[WithdrawShiftRequest] Invalid If-Match header", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	shiftRequest, err := h.shiftSvc.WithdrawShiftRequest(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[WithdrawShiftRequest] Failed to withdraw shift request", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	c.Header("ETag", pkg.FormatETag(shiftRequest.Version))
+	httpresp.HttpRespSuccess(c, shiftRequest, nil)
+	return
+}
+
+func (h *ShiftController) ReleaseShiftAssignment(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[ReleaseShiftAssignment] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.ReleaseShiftAssignmentReq
+
+	// the reason is optional, so an empty body is fine
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&data); err != nil {
+			h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[ReleaseShiftAssignment] Failed to bind json", zap.Error(err))
+			httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+			return
+		}
+	}
+
+	data.AssignmentID = id
+	data.UserEmail = claims.Email
+
+	result, err := h.shiftSvc.ReleaseShiftAssignment(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[ReleaseShiftAssignment] Failed to release shift assignment", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, result, nil)
 	return
 }
 
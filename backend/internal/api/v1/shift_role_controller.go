@@ -0,0 +1,173 @@
+package v1
+
+import (
+	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/middleware"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/internal/service"
+	"github.com/andibalo/payd-test/backend/pkg"
+	"github.com/andibalo/payd-test/backend/pkg/apperr"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type ShiftRoleController struct {
+	cfg          config.Config
+	shiftRoleSvc service.ShiftRoleService
+}
+
+func NewShiftRoleController(cfg config.Config, shiftRoleSvc service.ShiftRoleService) *ShiftRoleController {
+
+	return &ShiftRoleController{
+		cfg:          cfg,
+		shiftRoleSvc: shiftRoleSvc,
+	}
+}
+
+func (h *ShiftRoleController) AddRoutes(r *gin.Engine) {
+	sr := r.Group("/api/v1/shift/role", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg))
+
+	sr.GET("", h.GetShiftRoleList)
+	sr.POST("", h.CreateShiftRole)
+	sr.PUT("/:id", h.UpdateShiftRoleByID)
+	sr.DELETE("/:id", h.DeleteShiftRoleByID)
+	sr.PUT("/:id/restore", h.RestoreShiftRoleByID)
+}
+
+func (h *ShiftRoleController) GetShiftRoleList(c *gin.Context) {
+	var req request.GetShiftRoleListReq
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetShiftRoleList] Failed to bind query parameters", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	roles, err := h.shiftRoleSvc.GetShiftRoleList(c.Request.Context(), req)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetShiftRoleList] Failed to get shift role list", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, roles, nil)
+	return
+}
+
+func (h *ShiftRoleController) CreateShiftRole(c *gin.Context) {
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.CreateShiftRoleReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CreateShiftRole] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserEmail = claims.Email
+
+	role, err := h.shiftRoleSvc.CreateShiftRole(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CreateShiftRole] Failed to create shift role", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, role, nil)
+	return
+}
+
+func (h *ShiftRoleController) UpdateShiftRoleByID(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[UpdateShiftRoleByID] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.UpdateShiftRoleReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[UpdateShiftRoleByID] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserEmail = claims.Email
+
+	role, err := h.shiftRoleSvc.UpdateShiftRoleByID(c.Request.Context(), id, data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[UpdateShiftRoleByID] Failed to update shift role", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, role, nil)
+	return
+}
+
+func (h *ShiftRoleController) DeleteShiftRoleByID(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[DeleteShiftRoleByID] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	err = h.shiftRoleSvc.DeleteShiftRoleByID(c.Request.Context(), id, claims.Email)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[DeleteShiftRoleByID] Failed to delete shift role", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, nil, nil)
+	return
+}
+
+func (h *ShiftRoleController) RestoreShiftRoleByID(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[RestoreShiftRoleByID] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	role, err := h.shiftRoleSvc.RestoreShiftRoleByID(c.Request.Context(), id, claims.Email)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[RestoreShiftRoleByID] Failed to restore shift role", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, role, nil)
+	return
+}
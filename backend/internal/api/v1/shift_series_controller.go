@@ -0,0 +1,204 @@
+package v1
+
+import (
+	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/middleware"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/internal/service"
+	"github.com/andibalo/payd-test/backend/pkg"
+	"github.com/andibalo/payd-test/backend/pkg/apperr"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type ShiftSeriesController struct {
+	cfg            config.Config
+	shiftSeriesSvc service.ShiftSeriesService
+}
+
+func NewShiftSeriesController(cfg config.Config, shiftSeriesSvc service.ShiftSeriesService) *ShiftSeriesController {
+
+	return &ShiftSeriesController{
+		cfg:            cfg,
+		shiftSeriesSvc: shiftSeriesSvc,
+	}
+}
+
+func (h *ShiftSeriesController) AddRoutes(r *gin.Engine) {
+	sr := r.Group("/api/v1/shift/series", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg))
+
+	sr.POST("", h.CreateShiftSeries)
+	sr.GET("", h.GetShiftSeriesList)
+	sr.GET("/:id", h.GetShiftSeriesByID)
+	sr.PUT("/:id", h.UpdateShiftSeries)
+	sr.DELETE("/:id", h.CancelShiftSeries)
+	sr.POST("/:id/generate", h.GenerateShiftSeries)
+}
+
+func (h *ShiftSeriesController) CreateShiftSeries(c *gin.Context) {
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.CreateShiftSeriesReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CreateShiftSeries] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserEmail = claims.Email
+
+	result, err := h.shiftSeriesSvc.CreateShiftSeries(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CreateShiftSeries] Failed to create shift series", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, result, nil)
+	return
+}
+
+func (h *ShiftSeriesController) GetShiftSeriesList(c *gin.Context) {
+	var req request.GetShiftSeriesListReq
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetShiftSeriesList] Failed to bind query parameters", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	seriesList, err := h.shiftSeriesSvc.GetShiftSeriesList(c.Request.Context(), req)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetShiftSeriesList] Failed to get shift series list", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, seriesList, nil)
+	return
+}
+
+func (h *ShiftSeriesController) GetShiftSeriesByID(c *gin.Context) {
+
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetShiftSeriesByID] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	series, err := h.shiftSeriesSvc.GetShiftSeriesByID(c.Request.Context(), id)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetShiftSeriesByID] Failed to get shift series", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, series, nil)
+	return
+}
+
+func (h *ShiftSeriesController) UpdateShiftSeries(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[UpdateShiftSeries] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.UpdateShiftSeriesReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[UpdateShiftSeries] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserEmail = claims.Email
+
+	result, err := h.shiftSeriesSvc.UpdateShiftSeries(c.Request.Context(), id, data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[UpdateShiftSeries] Failed to update shift series", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, result, nil)
+	return
+}
+
+func (h *ShiftSeriesController) CancelShiftSeries(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CancelShiftSeries] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.CancelShiftSeriesReq
+
+	if err := c.ShouldBindQuery(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CancelShiftSeries] Failed to bind query parameters", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserEmail = claims.Email
+
+	result, err := h.shiftSeriesSvc.CancelShiftSeries(c.Request.Context(), id, data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CancelShiftSeries] Failed to cancel shift series", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, result, nil)
+	return
+}
+
+func (h *ShiftSeriesController) GenerateShiftSeries(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GenerateShiftSeries] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	result, err := h.shiftSeriesSvc.GenerateShiftSeries(c.Request.Context(), id, claims.Email)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GenerateShiftSeries] Failed to generate shift series", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, result, nil)
+	return
+}
@@ -0,0 +1,285 @@
+package v1
+
+import (
+	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/middleware"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/internal/service"
+	"github.com/andibalo/payd-test/backend/pkg"
+	"github.com/andibalo/payd-test/backend/pkg/apperr"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type ShiftSwapController struct {
+	cfg          config.Config
+	shiftSwapSvc service.ShiftSwapService
+}
+
+func NewShiftSwapController(cfg config.Config, shiftSwapSvc service.ShiftSwapService) *ShiftSwapController {
+
+	return &ShiftSwapController{
+		cfg:          cfg,
+		shiftSwapSvc: shiftSwapSvc,
+	}
+}
+
+func (h *ShiftSwapController) AddRoutes(r *gin.Engine) {
+	ssr := r.Group("/api/v1/shift/swap", middleware.JwtMiddleware(h.cfg))
+
+	ssr.POST("", h.CreateShiftSwap)
+	ssr.GET("", h.GetShiftSwapList)
+	ssr.GET("/:id", h.GetShiftSwapByID)
+	ssr.PUT("/:id/accept", h.AcceptShiftSwap)
+	ssr.PUT("/:id/decline", h.DeclineShiftSwap)
+	ssr.PUT("/:id/cancel", h.CancelShiftSwap)
+	ssr.PUT("/:id/approve", middleware.IsAdminMiddleware(h.cfg), h.ApproveShiftSwap)
+	ssr.PUT("/:id/reject", middleware.IsAdminMiddleware(h.cfg), h.RejectShiftSwap)
+}
+
+func (h *ShiftSwapController) CreateShiftSwap(c *gin.Context) {
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.CreateShiftSwapReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CreateShiftSwap] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserID = claims.ID
+	data.UserEmail = claims.Email
+
+	swap, err := h.shiftSwapSvc.CreateShiftSwap(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CreateShiftSwap] Failed to create shift swap", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	c.Header("ETag", pkg.FormatETag(swap.Version))
+	httpresp.HttpRespSuccess(c, swap, nil)
+	return
+}
+
+func (h *ShiftSwapController) GetShiftSwapList(c *gin.Context) {
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var req request.GetShiftSwapListReq
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetShiftSwapList] Failed to bind query parameters", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	if !middleware.IsAdmin(claims) {
+		req.UserID = claims.ID
+	}
+
+	req.UserEmail = claims.Email
+
+	swaps, err := h.shiftSwapSvc.GetShiftSwapList(c.Request.Context(), req)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetShiftSwapList] Failed to get shift swap list", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, swaps, nil)
+	return
+}
+
+func (h *ShiftSwapController) GetShiftSwapByID(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetShiftSwapByID] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var userID int64
+	if !middleware.IsAdmin(claims) {
+		userID = claims.ID
+	}
+
+	swap, err := h.shiftSwapSvc.GetShiftSwapByID(c.Request.Context(), id, userID)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetShiftSwapByID] Failed to get shift swap", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	c.Header("ETag", pkg.FormatETag(swap.Version))
+	httpresp.HttpRespSuccess(c, swap, nil)
+	return
+}
+
+func (h *ShiftSwapController) AcceptShiftSwap(c *gin.Context) {
+	data, err := h.bindShiftSwapAction(c, "AcceptShiftSwap")
+	if err != nil {
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	swap, err := h.shiftSwapSvc.AcceptShiftSwap(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[AcceptShiftSwap] Failed to accept shift swap", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	c.Header("ETag", pkg.FormatETag(swap.Version))
+	httpresp.HttpRespSuccess(c, swap, nil)
+	return
+}
+
+func (h *ShiftSwapController) DeclineShiftSwap(c *gin.Context) {
+	data, err := h.bindShiftSwapAction(c, "DeclineShiftSwap")
+	if err != nil {
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	swap, err := h.shiftSwapSvc.DeclineShiftSwap(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[DeclineShiftSwap] Failed to decline shift swap", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	c.Header("ETag", pkg.FormatETag(swap.Version))
+	httpresp.HttpRespSuccess(c, swap, nil)
+	return
+}
+
+func (h *ShiftSwapController) CancelShiftSwap(c *gin.Context) {
+	data, err := h.bindShiftSwapAction(c, "CancelShiftSwap")
+	if err != nil {
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	swap, err := h.shiftSwapSvc.CancelShiftSwap(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CancelShiftSwap] Failed to cancel shift swap", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	c.Header("ETag", pkg.FormatETag(swap.Version))
+	httpresp.HttpRespSuccess(c, swap, nil)
+	return
+}
+
+func (h *ShiftSwapController) ApproveShiftSwap(c *gin.Context) {
+	data, err := h.bindShiftSwapAction(c, "ApproveShiftSwap")
+	if err != nil {
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	swap, err := h.shiftSwapSvc.ApproveShiftSwap(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[ApproveShiftSwap] Failed to approve shift swap", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	c.Header("ETag", pkg.FormatETag(swap.Version))
+	httpresp.HttpRespSuccess(c, swap, nil)
+	return
+}
+
+func (h *ShiftSwapController) RejectShiftSwap(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[RejectShiftSwap] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.RejectShiftSwapReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[RejectShiftSwap] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.SwapID = id
+	data.UserEmail = claims.Email
+
+	data.Version, err = pkg.GetIfMatchVersion(c)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[RejectShiftSwap] Invalid If-Match header", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	swap, err := h.shiftSwapSvc.RejectShiftSwap(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[RejectShiftSwap] Failed to reject shift swap", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	c.Header("ETag", pkg.FormatETag(swap.Version))
+	httpresp.HttpRespSuccess(c, swap, nil)
+	return
+}
+
+// bindShiftSwapAction reads the swap ID, the caller and the If-Match version shared by the
+// bodyless swap actions.
+func (h *ShiftSwapController) bindShiftSwapAction(c *gin.Context, caller string) (request.ShiftSwapActionReq, error) {
+	var data request.ShiftSwapActionReq
+
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "["+caller+"] Error getting param", zap.Error(err))
+		return data, err
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		return data, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized)
+	}
+
+	data.SwapID = id
+	data.UserID = claims.ID
+	data.UserEmail = claims.Email
+
+	data.Version, err = pkg.GetIfMatchVersion(c)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "["+caller+"] Invalid If-Match header", zap.Error(err))
+		return data, err
+	}
+
+	return data, nil
+}
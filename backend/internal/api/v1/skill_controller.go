@@ -0,0 +1,252 @@
+package v1
+
+import (
+	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/middleware"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/internal/service"
+	"github.com/andibalo/payd-test/backend/pkg"
+	"github.com/andibalo/payd-test/backend/pkg/apperr"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type SkillController struct {
+	cfg      config.Config
+	skillSvc service.SkillService
+}
+
+func NewSkillController(cfg config.Config, skillSvc service.SkillService) *SkillController {
+
+	return &SkillController{
+		cfg:      cfg,
+		skillSvc: skillSvc,
+	}
+}
+
+func (h *SkillController) AddRoutes(r *gin.Engine) {
+	sr := r.Group("/api/v1/skill", middleware.JwtMiddleware(h.cfg))
+
+	sr.GET("", h.GetSkillList)
+	sr.POST("", middleware.IsAdminMiddleware(h.cfg), h.CreateSkill)
+	sr.PUT("/:id", middleware.IsAdminMiddleware(h.cfg), h.UpdateSkillByID)
+	sr.DELETE("/:id", middleware.IsAdminMiddleware(h.cfg), h.DeleteSkillByID)
+
+	wr := r.Group("/api/v1/user/worker/:id/skill", middleware.JwtMiddleware(h.cfg))
+
+	wr.GET("", h.GetWorkerSkills)
+	wr.PUT("", middleware.IsAdminMiddleware(h.cfg), h.AssignWorkerSkill)
+	wr.DELETE("/:skill_id", middleware.IsAdminMiddleware(h.cfg), h.RemoveWorkerSkill)
+}
+
+func (h *SkillController) GetSkillList(c *gin.Context) {
+	var req request.GetSkillListReq
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetSkillList] Failed to bind query parameters", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	skills, err := h.skillSvc.GetSkillList(c.Request.Context(), req)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetSkillList] Failed to get skill list", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, skills, nil)
+	return
+}
+
+func (h *SkillController) CreateSkill(c *gin.Context) {
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.CreateSkillReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CreateSkill] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserEmail = claims.Email
+
+	skill, err := h.skillSvc.CreateSkill(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[CreateSkill] Failed to create skill", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, skill, nil)
+	return
+}
+
+func (h *SkillController) UpdateSkillByID(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[UpdateSkillByID] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.UpdateSkillReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[UpdateSkillByID] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserEmail = claims.Email
+
+	skill, err := h.skillSvc.UpdateSkillByID(c.Request.Context(), id, data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[UpdateSkillByID] Failed to update skill", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, skill, nil)
+	return
+}
+
+func (h *SkillController) DeleteSkillByID(c *gin.Context) {
+	id, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[DeleteSkillByID] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	err = h.skillSvc.DeleteSkillByID(c.Request.Context(), id, claims.Email)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[DeleteSkillByID] Failed to delete skill", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, nil, nil)
+	return
+}
+
+// GetWorkerSkills lists a worker's skills. Workers may only look at their own.
+func (h *SkillController) GetWorkerSkills(c *gin.Context) {
+	userID, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetWorkerSkills] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	if !middleware.IsAdmin(claims) && claims.ID != userID {
+		httpresp.HttpRespError(c, oops.Code(response.Forbidden.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusForbidden).Errorf("Cannot view another worker's skills"))
+		return
+	}
+
+	workerSkills, err := h.skillSvc.GetWorkerSkills(c.Request.Context(), userID)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetWorkerSkills] Failed to get worker skills", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, workerSkills, nil)
+	return
+}
+
+func (h *SkillController) AssignWorkerSkill(c *gin.Context) {
+	userID, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[AssignWorkerSkill] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.AssignWorkerSkillReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[AssignWorkerSkill] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserID = userID
+	data.UserEmail = claims.Email
+
+	workerSkills, err := h.skillSvc.AssignWorkerSkill(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[AssignWorkerSkill] Failed to assign worker skill", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, workerSkills, nil)
+	return
+}
+
+func (h *SkillController) RemoveWorkerSkill(c *gin.Context) {
+	userID, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[RemoveWorkerSkill] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	skillID, err := pkg.GetIntParam(c, "skill_id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[RemoveWorkerSkill] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	err = h.skillSvc.RemoveWorkerSkill(c.Request.Context(), userID, skillID, claims.Email)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[RemoveWorkerSkill] Failed to remove worker skill", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, nil, nil)
+	return
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/andibalo/payd-test/backend/internal/request"
 	"github.com/andibalo/payd-test/backend/internal/response"
 	"github.com/andibalo/payd-test/backend/internal/service"
+	"github.com/andibalo/payd-test/backend/pkg"
 	"github.com/andibalo/payd-test/backend/pkg/apperr"
 	"github.com/andibalo/payd-test/backend/pkg/httpresp"
 	"github.com/gin-gonic/gin"
@@ -31,6 +32,7 @@ func (h *UserController) AddRoutes(r *gin.Engine) {
 	ur := r.Group("/api/v1/user")
 
 	ur.GET("/worker", middleware.JwtMiddleware(h.cfg), h.GetWorkerList)
+	ur.PUT("/worker/:id/pay-rate", middleware.JwtMiddleware(h.cfg), middleware.IsAdminMiddleware(h.cfg), h.SetWorkerPayRate)
 }
 
 func (h *UserController) GetWorkerList(c *gin.Context) {
@@ -46,6 +48,7 @@ func (h *UserController) GetWorkerList(c *gin.Context) {
 	var data request.GetWorkerListReq
 
 	data.UserEmail = claims.Email
+	data.IncludePayRates = middleware.IsAdmin(claims)
 	user, err := h.userSvc.GetWorkerList(c.Request.Context(), data)
 	if err != nil {
 		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[GetWorkerList] Failed to get worker list", zap.Error(err))
@@ -56,3 +59,39 @@ func (h *UserController) GetWorkerList(c *gin.Context) {
 	httpresp.HttpRespSuccess(c, user, nil)
 	return
 }
+
+func (h *UserController) SetWorkerPayRate(c *gin.Context) {
+	userID, err := pkg.GetIntParam(c, "id")
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[SetWorkerPayRate] Error getting param", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	claims := middleware.ParseToken(c)
+	if len(claims.Token) == 0 {
+		httpresp.HttpRespError(c, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(apperr.ErrUnauthorized))
+		return
+	}
+
+	var data request.SetWorkerPayRateReq
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[SetWorkerPayRate] Failed to bind json", zap.Error(err))
+		httpresp.HttpRespError(c, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf(apperr.ErrBadRequest))
+		return
+	}
+
+	data.UserID = userID
+	data.UserEmail = claims.Email
+
+	user, err := h.userSvc.SetWorkerPayRate(c.Request.Context(), data)
+	if err != nil {
+		h.cfg.Logger().ErrorWithContext(c.Request.Context(), "[SetWorkerPayRate] Failed to set worker pay rate", zap.Error(err))
+		httpresp.HttpRespError(c, err)
+		return
+	}
+
+	httpresp.HttpRespSuccess(c, user, nil)
+	return
+}
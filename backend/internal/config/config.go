@@ -2,8 +2,10 @@ package config
 
 import (
 	"fmt"
+	"github.com/andibalo/payd-test/backend/internal/constants"
 	"github.com/andibalo/payd-test/backend/pkg/logger"
 	"github.com/spf13/viper"
+	"strings"
 )
 
 const (
@@ -26,14 +28,18 @@ type Config interface {
 
 	GetAuthCfg() Auth
 	GetFlags() Flag
+	GetShiftCfg() Shift
+	GetRetentionCfg() Retention
 }
 
 type AppConfig struct {
-	logger logger.Logger
-	App    app
-	Db     db
-	Flag   Flag
-	Auth   Auth
+	logger    logger.Logger
+	App       app
+	Db        db
+	Flag      Flag
+	Auth      Auth
+	Shift     Shift
+	Retention Retention
 }
 
 type app struct {
@@ -56,7 +62,8 @@ type db struct {
 }
 
 type Flag struct {
-	EnableSeedDB bool
+	EnableSeedDB       bool
+	EnableRetentionJob bool
 }
 
 type Auth struct {
@@ -64,6 +71,31 @@ type Auth struct {
 	JWTStaticToken string
 }
 
+// Shift holds the scheduling settings. A pending request expires when its shift starts, or
+// RequestApprovalDeadlineHours after it was made when that is set. The expiry sweeper runs
+// every RequestExpiryIntervalMinutes. RequestQueueOrder ranks competing requests for a
+// shift, and RequestOverflowAction says whether the requests left over once a shift is
+// full are waitlisted or rejected. SwapRequiresApproval holds shift swaps the recipient
+// accepted until an admin approves them.
+type Shift struct {
+	SeriesHorizonDays            int
+	RequestApprovalDeadlineHours int
+	RequestExpiryIntervalMinutes int
+	RequestQueueOrder            string
+	RequestOverflowAction        string
+	SwapRequiresApproval         bool
+}
+
+// Retention decides how long history stays in the main database. Rows soft-deleted more
+// than DeletedDays ago are purged, and completed shifts that ended more than ArchiveMonths
+// ago are moved to the SQLite file at ArchivePath. The server job runs every IntervalHours.
+type Retention struct {
+	DeletedDays   int
+	ArchiveMonths int
+	ArchivePath   string
+	IntervalHours int
+}
+
 func InitConfig() *AppConfig {
 	viper.SetConfigType("env")
 	viper.SetConfigName(".env") // name of Config file (without extension)
@@ -120,12 +152,27 @@ func InitConfig() *AppConfig {
 			MaxPool:  viper.GetInt("DB_MAX_POOLING_CONNECTION"),
 		},
 		Flag: Flag{
-			EnableSeedDB: viper.GetBool("ENABLE_SEED_DB"),
+			EnableSeedDB:       viper.GetBool("ENABLE_SEED_DB"),
+			EnableRetentionJob: viper.GetBool("ENABLE_RETENTION_JOB"),
 		},
 		Auth: Auth{
 			JWTSecret:      viper.GetString("JWT_SECRET"),
 			JWTStaticToken: viper.GetString("JWT_STATIC_TOKEN"),
 		},
+		Shift: Shift{
+			SeriesHorizonDays:            getIntOrDefault("SHIFT_SERIES_HORIZON_DAYS", constants.DEFAULT_SHIFT_SERIES_HORIZON_DAYS),
+			RequestApprovalDeadlineHours: getIntOrDefault("SHIFT_REQUEST_APPROVAL_DEADLINE_HOURS", 0),
+			RequestExpiryIntervalMinutes: getIntOrDefault("SHIFT_REQUEST_EXPIRY_INTERVAL_MINUTES", constants.DEFAULT_SHIFT_REQUEST_EXPIRY_INTERVAL_MINUTES),
+			RequestQueueOrder:            getOneOfOrDefault("SHIFT_REQUEST_QUEUE_ORDER", constants.SHIFT_REQUEST_QUEUE_ORDER_FIRST_COME, constants.SHIFT_REQUEST_QUEUE_ORDER_FEWEST_SHIFTS),
+			RequestOverflowAction:        getOneOfOrDefault("SHIFT_REQUEST_OVERFLOW_ACTION", constants.SHIFT_REQUEST_OVERFLOW_WAITLIST, constants.SHIFT_REQUEST_OVERFLOW_REJECT),
+			SwapRequiresApproval:         viper.GetBool("SHIFT_SWAP_REQUIRES_APPROVAL"),
+		},
+		Retention: Retention{
+			DeletedDays:   getIntOrDefault("RETENTION_DELETED_DAYS", constants.DEFAULT_RETENTION_DELETED_DAYS),
+			ArchiveMonths: getIntOrDefault("RETENTION_ARCHIVE_MONTHS", constants.DEFAULT_RETENTION_ARCHIVE_MONTHS),
+			ArchivePath:   getStringOrDefault("RETENTION_ARCHIVE_PATH", constants.DEFAULT_RETENTION_ARCHIVE_PATH),
+			IntervalHours: getIntOrDefault("RETENTION_INTERVAL_HOURS", constants.DEFAULT_RETENTION_INTERVAL_HOURS),
+		},
 	}
 }
 
@@ -137,6 +184,35 @@ func getRequiredString(key string) string {
 	panic(fmt.Errorf("KEY %s IS MISSING", key))
 }
 
+func getIntOrDefault(key string, defaultVal int) int {
+	if viper.IsSet(key) && viper.GetInt(key) > 0 {
+		return viper.GetInt(key)
+	}
+
+	return defaultVal
+}
+
+func getStringOrDefault(key string, defaultVal string) string {
+	if viper.IsSet(key) && viper.GetString(key) != "" {
+		return viper.GetString(key)
+	}
+
+	return defaultVal
+}
+
+// getOneOfOrDefault returns the value of key when it is one of the allowed values, and
+// defaultVal otherwise.
+func getOneOfOrDefault(key string, defaultVal string, allowed ...string) string {
+	value := strings.ToUpper(viper.GetString(key))
+	for _, allowedVal := range allowed {
+		if value == allowedVal {
+			return value
+		}
+	}
+
+	return defaultVal
+}
+
 func (c *AppConfig) Logger() logger.Logger {
 	return c.logger
 }
@@ -172,3 +248,11 @@ func (c *AppConfig) GetFlags() Flag {
 func (c *AppConfig) GetAuthCfg() Auth {
 	return c.Auth
 }
+
+func (c *AppConfig) GetShiftCfg() Shift {
+	return c.Shift
+}
+
+func (c *AppConfig) GetRetentionCfg() Retention {
+	return c.Retention
+}
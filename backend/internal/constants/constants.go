@@ -1,14 +1,121 @@
 package constants
 
+import "time"
+
 const (
 	EMAIL_ADMIN_RMS = "rms.admin@test.com"
 
+	// SYSTEM_ACTOR is recorded as the actor of changes made by background jobs.
+	SYSTEM_ACTOR = "system"
+
 	WORKER_ROLE = "WORKER"
 	ADMIN_ROLE  = "ADMIN"
 
 	SHIFT_REQUEST_STATUS_PENDING  = "PENDING"
 	SHIFT_REQUEST_STATUS_REJECTED = "REJECTED"
 	SHIFT_REQUEST_STATUS_APPROVED = "APPROVED"
+	// SHIFT_REQUEST_STATUS_CANCELLED is set on pending requests that the worker withdrew or
+	// whose shift was cancelled or deleted.
+	SHIFT_REQUEST_STATUS_CANCELLED = "CANCELLED"
+	// SHIFT_REQUEST_STATUS_EXPIRED is set on pending requests nobody decided on in time.
+	SHIFT_REQUEST_STATUS_EXPIRED = "EXPIRED"
+	// SHIFT_REQUEST_STATUS_WAITLISTED is set on requests for a shift whose slots are all
+	// filled. They go back to PENDING in queue order when a slot opens up.
+	SHIFT_REQUEST_STATUS_WAITLISTED = "WAITLISTED"
+
+	SHIFT_REQUEST_QUEUE_ORDER_FIRST_COME    = "FIRST_COME"
+	SHIFT_REQUEST_QUEUE_ORDER_FEWEST_SHIFTS = "FEWEST_SHIFTS"
+
+	SHIFT_REQUEST_OVERFLOW_WAITLIST = "WAITLIST"
+	SHIFT_REQUEST_OVERFLOW_REJECT   = "REJECT"
+
+	SHIFT_FULL_REASON = "All slots of the shift have been filled"
+
+	SHIFT_SWAP_STATUS_PROPOSED = "PROPOSED"
+	// SHIFT_SWAP_STATUS_ACCEPTED is set on swaps the recipient agreed to that still wait for
+	// an admin, when swaps need approval.
+	SHIFT_SWAP_STATUS_ACCEPTED  = "ACCEPTED"
+	SHIFT_SWAP_STATUS_COMPLETED = "COMPLETED"
+	SHIFT_SWAP_STATUS_DECLINED  = "DECLINED"
+	SHIFT_SWAP_STATUS_CANCELLED = "CANCELLED"
+	SHIFT_SWAP_STATUS_REJECTED  = "REJECTED"
 
 	MAX_ASSIGNED_SHIFT_PER_WEEK = 5
+
+	DATE_FORMAT        = "2006-01-02"
+	DATETIME_FORMAT    = "2006-01-02 15:04:05"
+	TIME_OF_DAY_FORMAT = "15:04"
+
+	MAX_SHIFT_DURATION = 24 * time.Hour
+
+	MAX_SHIFT_IMPORT_ROWS = 1000
+
+	MAX_ROSTER_COPY_DAYS = 31
+
+	MAX_SHIFT_COST_RANGE_DAYS = 93
+
+	SHIFT_STATUS_DRAFT     = "DRAFT"
+	SHIFT_STATUS_PUBLISHED = "PUBLISHED"
+	SHIFT_STATUS_CANCELLED = "CANCELLED"
+	SHIFT_STATUS_COMPLETED = "COMPLETED"
+
+	SHIFT_BREAK_TYPE_FIXED    = "FIXED"
+	SHIFT_BREAK_TYPE_FLOATING = "FLOATING"
+
+	EVENT_TYPE_SHIFT_CANCELLED = "SHIFT_CANCELLED"
+	EVENT_TYPE_SHIFT_DELETED   = "SHIFT_DELETED"
+	EVENT_TYPE_SHIFT_RESTORED  = "SHIFT_RESTORED"
+	EVENT_TYPE_SHIFT_RELEASED  = "SHIFT_RELEASED"
+	EVENT_TYPE_SHIFT_SWAPPED   = "SHIFT_SWAPPED"
+
+	EVENT_AFFECTED_REQUEST    = "REQUEST"
+	EVENT_AFFECTED_ASSIGNMENT = "ASSIGNMENT"
+
+	AUDIT_ENTITY_SHIFT            = "SHIFT"
+	AUDIT_ENTITY_SHIFT_REQUEST    = "SHIFT_REQUEST"
+	AUDIT_ENTITY_SHIFT_ASSIGNMENT = "SHIFT_ASSIGNMENT"
+	AUDIT_ENTITY_SHIFT_SWAP       = "SHIFT_SWAP"
+	AUDIT_ENTITY_USER             = "USER"
+
+	AUDIT_ACTION_CREATE   = "CREATE"
+	AUDIT_ACTION_UPDATE   = "UPDATE"
+	AUDIT_ACTION_DELETE   = "DELETE"
+	AUDIT_ACTION_PUBLISH  = "PUBLISH"
+	AUDIT_ACTION_CANCEL   = "CANCEL"
+	AUDIT_ACTION_COMPLETE = "COMPLETE"
+	AUDIT_ACTION_APPROVE  = "APPROVE"
+	AUDIT_ACTION_REJECT   = "REJECT"
+	AUDIT_ACTION_RESTORE  = "RESTORE"
+	AUDIT_ACTION_WITHDRAW = "WITHDRAW"
+	AUDIT_ACTION_EXPIRE   = "EXPIRE"
+	AUDIT_ACTION_WAITLIST = "WAITLIST"
+	AUDIT_ACTION_PROMOTE  = "PROMOTE"
+	AUDIT_ACTION_ACCEPT   = "ACCEPT"
+	AUDIT_ACTION_DECLINE  = "DECLINE"
+
+	MAX_AUDIT_LOG_LIMIT = 100
+
+	SHIFT_LIST_SORT_CREATED_AT = "created_at"
+	SHIFT_LIST_SORT_DATE       = "date"
+	SHIFT_LIST_SORT_START_TIME = "start_time"
+	SHIFT_LIST_SORT_ROLE       = "role"
+	SHIFT_LIST_SORT_DELETED_AT = "deleted_at"
+
+	SORT_DIRECTION_ASC  = "asc"
+	SORT_DIRECTION_DESC = "desc"
+
+	SHIFT_SERIES_SCOPE_THIS      = "THIS"
+	SHIFT_SERIES_SCOPE_FOLLOWING = "FOLLOWING"
+	SHIFT_SERIES_SCOPE_ALL       = "ALL"
+
+	DEFAULT_SHIFT_SERIES_HORIZON_DAYS = 28
+
+	DEFAULT_SHIFT_REQUEST_EXPIRY_INTERVAL_MINUTES = 5
+
+	DEFAULT_RETENTION_DELETED_DAYS   = 90
+	DEFAULT_RETENTION_ARCHIVE_MONTHS = 12
+	DEFAULT_RETENTION_INTERVAL_HOURS = 24
+	DEFAULT_RETENTION_ARCHIVE_PATH   = "./rms-archive.db"
+
+	DEFAULT_LOCATION_TIMEZONE = "UTC"
 )
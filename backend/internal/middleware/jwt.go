@@ -68,7 +68,7 @@ func JwtMiddleware(cfg config.Config) gin.HandlerFunc {
 		// check parse token error
 		if err != nil {
 			cfg.Logger().ErrorWithContext(ctx, "[JWTMiddleware] User unauthorized", zap.Error(err))
-			httpresp.HttpRespError(ctx, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(err.Error()))
+			httpresp.HttpRespError(ctx, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf("%s", err))
 			return
 		}
 
@@ -79,7 +79,7 @@ func JwtMiddleware(cfg config.Config) gin.HandlerFunc {
 			ctx.Next()
 		} else {
 			cfg.Logger().ErrorWithContext(ctx, "[JWTMiddleware] User unauthorized", zap.Error(err))
-			httpresp.HttpRespError(ctx, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf(err.Error()))
+			httpresp.HttpRespError(ctx, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf("%s", err))
 			return
 		}
 	}
@@ -90,12 +90,7 @@ func IsAdminMiddleware(cfg config.Config) gin.HandlerFunc {
 
 		claims := ParseToken(ctx)
 
-		if claims.Email == constants.EMAIL_ADMIN_RMS {
-			ctx.Next()
-			return
-		}
-
-		if claims.Role != constants.ADMIN_ROLE {
+		if !IsAdmin(claims) {
 			cfg.Logger().ErrorWithContext(ctx, "[IsAdminMiddleware] User unauthorized")
 			httpresp.HttpRespError(ctx, oops.Code(response.Unauthorized.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnauthorized).Errorf("User unauthorized"))
 			return
@@ -103,6 +98,11 @@ func IsAdminMiddleware(cfg config.Config) gin.HandlerFunc {
 	}
 }
 
+// IsAdmin reports whether the token belongs to an admin or the static admin token.
+func IsAdmin(claims *TokenClaims) bool {
+	return claims.Email == constants.EMAIL_ADMIN_RMS || claims.Role == constants.ADMIN_ROLE
+}
+
 func ParseTokenFromHeader(ctx *gin.Context) (string, error) {
 	var (
 		headerToken = ctx.Request.Header.Get("Authorization")
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+)
+
+// AuditLog is one append-only entry of the change history. Changes holds a JSON object of
+// the fields that changed, each as an AuditChange.
+type AuditLog struct {
+	ID         int64     `json:"id"`
+	EntityType string    `json:"entity_type"`
+	EntityID   int64     `json:"entity_id"`
+	Action     string    `json:"action"`
+	Actor      string    `json:"actor"`
+	Changes    string    `json:"changes"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AuditChange is a field's value before and after a change. Before is null for created
+// entities and After is null for deleted ones.
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"github.com/guregu/null/v6"
+	"time"
+)
+
+// AutoApprovalRule approves a shift request without an admin when all of its conditions
+// hold. Unset conditions match anything. MaxHoursBeforeStart only matches shifts starting
+// within that many hours of the request.
+type AutoApprovalRule struct {
+	ID                  int64       `json:"id"`
+	Name                string      `json:"name"`
+	RoleID              null.Int    `json:"role_id"`
+	LocationID          null.Int    `json:"location_id"`
+	MinCompletedShifts  int         `json:"min_completed_shifts"`
+	MaxHoursBeforeStart null.Int    `json:"max_hours_before_start"`
+	IsActive            bool        `json:"is_active"`
+	CreatedAt           time.Time   `json:"created_at"`
+	CreatedBy           string      `json:"created_by"`
+	UpdatedAt           null.Time   `json:"updated_at"`
+	UpdatedBy           null.String `json:"updated_by"`
+	DeletedAt           null.Time   `json:"deleted_at"`
+	DeletedBy           null.String `json:"deleted_by"`
+}
+
+// Matches reports whether the rule approves a request for shift, made at now by a worker
+// who has completed completedShiftCount shifts.
+func (r *AutoApprovalRule) Matches(shift *Shift, completedShiftCount int, now time.Time) bool {
+	if r.RoleID.Valid && r.RoleID.Int64 != int64(shift.RoleID) {
+		return false
+	}
+
+	if r.LocationID.Valid && r.LocationID != shift.LocationID {
+		return false
+	}
+
+	if completedShiftCount < r.MinCompletedShifts {
+		return false
+	}
+
+	if r.MaxHoursBeforeStart.Valid && shift.StartTime.Sub(now) > time.Duration(r.MaxHoursBeforeStart.Int64)*time.Hour {
+		return false
+	}
+
+	return true
+}
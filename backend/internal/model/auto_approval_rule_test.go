@@ -0,0 +1,96 @@
+package model
+
+import (
+	"github.com/guregu/null/v6"
+	"testing"
+	"time"
+)
+
+func TestAutoApprovalRuleMatches(t *testing.T) {
+	now := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+
+	shift := &Shift{
+		RoleID:     2,
+		LocationID: null.IntFrom(5),
+		StartTime:  now.Add(48 * time.Hour),
+	}
+
+	tests := []struct {
+		name           string
+		rule           AutoApprovalRule
+		shift          *Shift
+		completedCount int
+		want           bool
+	}{
+		{
+			name:  "rule without conditions",
+			rule:  AutoApprovalRule{},
+			shift: shift,
+			want:  true,
+		},
+		{
+			name:  "matching role and location",
+			rule:  AutoApprovalRule{RoleID: null.IntFrom(2), LocationID: null.IntFrom(5)},
+			shift: shift,
+			want:  true,
+		},
+		{
+			name:  "other role",
+			rule:  AutoApprovalRule{RoleID: null.IntFrom(3)},
+			shift: shift,
+			want:  false,
+		},
+		{
+			name:  "other location",
+			rule:  AutoApprovalRule{LocationID: null.IntFrom(6)},
+			shift: shift,
+			want:  false,
+		},
+		{
+			name:  "location rule and shift without location",
+			rule:  AutoApprovalRule{LocationID: null.IntFrom(5)},
+			shift: &Shift{RoleID: 2, StartTime: shift.StartTime},
+			want:  false,
+		},
+		{
+			name:           "enough completed shifts",
+			rule:           AutoApprovalRule{MinCompletedShifts: 3},
+			shift:          shift,
+			completedCount: 3,
+			want:           true,
+		},
+		{
+			name:           "too few completed shifts",
+			rule:           AutoApprovalRule{MinCompletedShifts: 3},
+			shift:          shift,
+			completedCount: 2,
+			want:           false,
+		},
+		{
+			name:  "shift starts within the window",
+			rule:  AutoApprovalRule{MaxHoursBeforeStart: null.IntFrom(48)},
+			shift: shift,
+			want:  true,
+		},
+		{
+			name:  "shift starts after the window",
+			rule:  AutoApprovalRule{MaxHoursBeforeStart: null.IntFrom(47)},
+			shift: shift,
+			want:  false,
+		},
+		{
+			name:  "shift already started",
+			rule:  AutoApprovalRule{MaxHoursBeforeStart: null.IntFrom(1)},
+			shift: &Shift{RoleID: 2, StartTime: now.Add(-time.Hour)},
+			want:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(tt.shift, tt.completedCount, now); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,12 +6,15 @@ import (
 )
 
 type ShiftRoleEnum struct {
-	ID        int64       `json:"id"`
-	RoleName  string      `json:"role_name"`
-	CreatedAt time.Time   `json:"created_at"`
-	CreatedBy string      `json:"created_by"`
-	UpdatedAt null.Time   `json:"updated_at"`
-	UpdatedBy null.String `json:"updated_by"`
-	DeletedAt null.Time   `json:"deleted_at"`
-	DeletedBy null.String `json:"deleted_by"`
+	ID       int64  `json:"id"`
+	RoleName string `json:"role_name"`
+	// HourlyRate is the default pay rate for the role, used when neither the shift nor
+	// the worker overrides it.
+	HourlyRate null.Float  `json:"hourly_rate"`
+	CreatedAt  time.Time   `json:"created_at"`
+	CreatedBy  string      `json:"created_by"`
+	UpdatedAt  null.Time   `json:"updated_at"`
+	UpdatedBy  null.String `json:"updated_by"`
+	DeletedAt  null.Time   `json:"deleted_at"`
+	DeletedBy  null.String `json:"deleted_by"`
 }
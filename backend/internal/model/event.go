@@ -0,0 +1,51 @@
+package model
+
+import (
+	"github.com/guregu/null/v6"
+	"time"
+)
+
+// Event is an outbox entry written in the same transaction as the change it describes, so
+// workers can be notified of it without the change and the notification drifting apart.
+type Event struct {
+	ID           int64     `json:"id"`
+	EventType    string    `json:"event_type"`
+	UserID       int64     `json:"user_id"`
+	ShiftID      null.Int  `json:"shift_id"`
+	Payload      string    `json:"payload"`
+	CreatedAt    time.Time `json:"created_at"`
+	CreatedBy    string    `json:"created_by"`
+	DispatchedAt null.Time `json:"dispatched_at"`
+}
+
+// ShiftRestoredPayload tells a worker that a deleted shift was restored together with their
+// REQUEST or ASSIGNMENT.
+type ShiftRestoredPayload struct {
+	ShiftID   int       `json:"shift_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Timezone  string    `json:"timezone"`
+	Restored  string    `json:"restored"`
+}
+
+// ShiftRemovedPayload tells a worker that a shift they requested or were assigned to was
+// cancelled or deleted. Affected says whether their REQUEST or ASSIGNMENT was undone.
+type ShiftRemovedPayload struct {
+	ShiftID   int       `json:"shift_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Timezone  string    `json:"timezone"`
+	Affected  string    `json:"affected"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// ShiftSwappedPayload tells a worker that a swap went through, giving up the assignment to
+// GivenShiftID for one to ReceivedShiftID.
+type ShiftSwappedPayload struct {
+	SwapID          int64     `json:"swap_id"`
+	GivenShiftID    int64     `json:"given_shift_id"`
+	ReceivedShiftID int64     `json:"received_shift_id"`
+	StartTime       time.Time `json:"start_time"`
+	EndTime         time.Time `json:"end_time"`
+	Timezone        string    `json:"timezone"`
+}
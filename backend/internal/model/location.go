@@ -0,0 +1,21 @@
+package model
+
+import (
+	"github.com/guregu/null/v6"
+	"time"
+)
+
+type Location struct {
+	ID        int64       `json:"id"`
+	Name      string      `json:"name"`
+	Address   null.String `json:"address"`
+	Latitude  null.Float  `json:"latitude"`
+	Longitude null.Float  `json:"longitude"`
+	Timezone  string      `json:"timezone"`
+	CreatedAt time.Time   `json:"created_at"`
+	CreatedBy string      `json:"created_by"`
+	UpdatedAt null.Time   `json:"updated_at"`
+	UpdatedBy null.String `json:"updated_by"`
+	DeletedAt null.Time   `json:"deleted_at"`
+	DeletedBy null.String `json:"deleted_by"`
+}
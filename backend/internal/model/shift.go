@@ -1,24 +1,102 @@
 package model
 
 import (
+	"github.com/andibalo/payd-test/backend/internal/constants"
 	"github.com/guregu/null/v6"
 	"time"
 )
 
+// Shift is a scheduled slot of work. HourlyRate overrides the worker and role pay rates,
+// RequiredSkillIDs are the skills a worker must hold (and not have expired by the shift
+// date) to request the shift, and Version is bumped on every write and served as the ETag.
 type Shift struct {
-	ID        int         `json:"id"`
-	Date      time.Time   `json:"date"`
-	StartTime time.Time   `json:"start_time"`
-	EndTime   time.Time   `json:"end_time"`
-	RoleID    int         `json:"role_id"`
-	Location  null.String `json:"location"`
-	IsActive  bool        `json:"is_active"`
-	CreatedAt time.Time   `json:"created_at"`
-	CreatedBy string      `json:"created_by"`
-	UpdatedAt null.Time   `json:"updated_at"`
-	UpdatedBy null.String `json:"updated_by"`
-	DeletedAt null.Time   `json:"deleted_at"`
-	DeletedBy null.String `json:"deleted_by"`
+	ID               int          `json:"id"`
+	Date             time.Time    `json:"date"`
+	StartTime        time.Time    `json:"start_time"`
+	EndTime          time.Time    `json:"end_time"`
+	Timezone         string       `json:"timezone"`
+	RoleID           int          `json:"role_id"`
+	LocationID       null.Int     `json:"location_id"`
+	Headcount        int          `json:"headcount"`
+	SeriesID         null.Int     `json:"series_id"`
+	IsActive         bool         `json:"is_active"`
+	Status           string       `json:"status"`
+	PublishedAt      null.Time    `json:"published_at"`
+	PublishedBy      null.String  `json:"published_by"`
+	CancelledAt      null.Time    `json:"cancelled_at"`
+	CancelledBy      null.String  `json:"cancelled_by"`
+	CancelReason     null.String  `json:"cancel_reason"`
+	HourlyRate       null.Float   `json:"hourly_rate"`
+	Version          int          `json:"version"`
+	Breaks           []ShiftBreak `json:"breaks"`
+	RequiredSkillIDs []int64      `json:"required_skill_ids"`
+	CreatedAt        time.Time    `json:"created_at"`
+	CreatedBy        string       `json:"created_by"`
+	UpdatedAt        null.Time    `json:"updated_at"`
+	UpdatedBy        null.String  `json:"updated_by"`
+	DeletedAt        null.Time    `json:"deleted_at"`
+	DeletedBy        null.String  `json:"deleted_by"`
+}
+
+// SetSchedule stores the shift as absolute UTC instants in the given zone and derives Date
+// as the calendar day the shift starts on at its location, so an overnight shift belongs
+// to the day (and week) it starts.
+func (s *Shift) SetSchedule(start, end time.Time, loc *time.Location) {
+	localStart := start.In(loc)
+
+	s.Date = time.Date(localStart.Year(), localStart.Month(), localStart.Day(), 0, 0, 0, 0, time.UTC)
+	s.StartTime = start.UTC()
+	s.EndTime = end.UTC()
+	s.Timezone = loc.String()
+}
+
+// ShiftBreak is a break inside a shift. A FIXED break starts at StartTime, a FLOATING break
+// only has a length and may be taken any time during the shift.
+type ShiftBreak struct {
+	ID              int64     `json:"id"`
+	ShiftID         int64     `json:"shift_id"`
+	BreakType       string    `json:"break_type"`
+	StartTime       null.Time `json:"start_time"`
+	DurationMinutes int       `json:"duration_minutes"`
+	IsPaid          bool      `json:"is_paid"`
+	CreatedAt       time.Time `json:"created_at"`
+	CreatedBy       string    `json:"created_by"`
+}
+
+// UnpaidBreakDuration is the total length of the unpaid breaks of the shift.
+func (s *Shift) UnpaidBreakDuration() time.Duration {
+	var unpaid time.Duration
+	for _, shiftBreak := range s.Breaks {
+		if !shiftBreak.IsPaid {
+			unpaid += time.Duration(shiftBreak.DurationMinutes) * time.Minute
+		}
+	}
+
+	return unpaid
+}
+
+// NetWorkingDuration is the scheduled time minus unpaid breaks, which is what hours-based
+// rules and reports count.
+func (s *Shift) NetWorkingDuration() time.Duration {
+	return s.EndTime.Sub(s.StartTime) - s.UnpaidBreakDuration()
+}
+
+// shiftStatusTransitions lists the statuses a shift may move to from each status. Shifts
+// start as DRAFT, only PUBLISHED shifts are visible to and requestable by workers, and
+// cancelled and completed shifts are final.
+var shiftStatusTransitions = map[string][]string{
+	constants.SHIFT_STATUS_DRAFT:     {constants.SHIFT_STATUS_PUBLISHED, constants.SHIFT_STATUS_CANCELLED},
+	constants.SHIFT_STATUS_PUBLISHED: {constants.SHIFT_STATUS_CANCELLED, constants.SHIFT_STATUS_COMPLETED},
+}
+
+func (s *Shift) CanTransitionTo(status string) bool {
+	for _, next := range shiftStatusTransitions[s.Status] {
+		if next == status {
+			return true
+		}
+	}
+
+	return false
 }
 
 type ShiftRequest struct {
@@ -29,6 +107,7 @@ type ShiftRequest struct {
 	RequestedBy     string      `json:"requested_by"`
 	AdminActor      null.String `json:"admin_actor"`
 	RejectionReason null.String `json:"rejection_reason"`
+	Version         int         `json:"version"`
 	CreatedAt       time.Time   `json:"created_at"`
 	CreatedBy       string      `json:"created_by"`
 	UpdatedAt       null.Time   `json:"updated_at"`
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"github.com/guregu/null/v6"
+	"strings"
+	"time"
+)
+
+type ShiftSeries struct {
+	ID             int64       `json:"id"`
+	RRule          string      `json:"rrule"`
+	StartDate      time.Time   `json:"start_date"`
+	StartTime      string      `json:"start_time"`
+	EndTime        string      `json:"end_time"`
+	RoleID         int         `json:"role_id"`
+	LocationID     null.Int    `json:"location_id"`
+	Timezone       string      `json:"timezone"`
+	Headcount      int         `json:"headcount"`
+	ExceptionDates null.String `json:"exception_dates"`
+	IsActive       bool        `json:"is_active"`
+	CreatedAt      time.Time   `json:"created_at"`
+	CreatedBy      string      `json:"created_by"`
+	UpdatedAt      null.Time   `json:"updated_at"`
+	UpdatedBy      null.String `json:"updated_by"`
+	DeletedAt      null.Time   `json:"deleted_at"`
+	DeletedBy      null.String `json:"deleted_by"`
+}
+
+// ExceptionDateList returns the occurrence dates (YYYY-MM-DD) the series no longer manages:
+// cancelled occurrences and occurrences edited on their own.
+func (s *ShiftSeries) ExceptionDateList() []string {
+	if !s.ExceptionDates.Valid || s.ExceptionDates.String == "" {
+		return []string{}
+	}
+
+	return strings.Split(s.ExceptionDates.String, ",")
+}
+
+func (s *ShiftSeries) AddExceptionDate(date string) {
+	for _, d := range s.ExceptionDateList() {
+		if d == date {
+			return
+		}
+	}
+
+	s.ExceptionDates = null.StringFrom(strings.Join(append(s.ExceptionDateList(), date), ","))
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"github.com/guregu/null/v6"
+	"time"
+)
+
+// ShiftSwap is a proposal by one worker to exchange their assignment for another worker's.
+// The assignment IDs are the ones the swap was proposed against; once it completes both
+// are ended and replaced by new assignments.
+type ShiftSwap struct {
+	ID                    int64       `json:"id"`
+	ProposerID            int64       `json:"proposer_id"`
+	ProposerAssignmentID  int64       `json:"proposer_assignment_id"`
+	ProposerShiftID       int64       `json:"proposer_shift_id"`
+	RecipientID           int64       `json:"recipient_id"`
+	RecipientAssignmentID int64       `json:"recipient_assignment_id"`
+	RecipientShiftID      int64       `json:"recipient_shift_id"`
+	Status                string      `json:"status"`
+	Note                  null.String `json:"note"`
+	AdminActor            null.String `json:"admin_actor"`
+	RejectionReason       null.String `json:"rejection_reason"`
+	Version               int         `json:"version"`
+	CreatedAt             time.Time   `json:"created_at"`
+	CreatedBy             string      `json:"created_by"`
+	UpdatedAt             null.Time   `json:"updated_at"`
+	UpdatedBy             null.String `json:"updated_by"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"github.com/guregu/null/v6"
+	"time"
+)
+
+type Skill struct {
+	ID          int64       `json:"id"`
+	Name        string      `json:"name"`
+	Description null.String `json:"description"`
+	CreatedAt   time.Time   `json:"created_at"`
+	CreatedBy   string      `json:"created_by"`
+	UpdatedAt   null.Time   `json:"updated_at"`
+	UpdatedBy   null.String `json:"updated_by"`
+	DeletedAt   null.Time   `json:"deleted_at"`
+	DeletedBy   null.String `json:"deleted_by"`
+}
+
+// WorkerSkill is a skill or certification held by a worker. A certification without
+// ExpiresAt never expires.
+type WorkerSkill struct {
+	ID        int64       `json:"id"`
+	UserID    int64       `json:"user_id"`
+	SkillID   int64       `json:"skill_id"`
+	SkillName string      `json:"skill_name"`
+	ExpiresAt null.Time   `json:"expires_at"`
+	CreatedAt time.Time   `json:"created_at"`
+	CreatedBy string      `json:"created_by"`
+	UpdatedAt null.Time   `json:"updated_at"`
+	UpdatedBy null.String `json:"updated_by"`
+}
+
+// IsValidOn reports whether the certification is still valid on the given date. It is
+// valid up to and including its expiry date.
+func (w *WorkerSkill) IsValidOn(date time.Time) bool {
+	if !w.ExpiresAt.Valid {
+		return true
+	}
+
+	expiresAt := w.ExpiresAt.Time
+	expiryDate := time.Date(expiresAt.Year(), expiresAt.Month(), expiresAt.Day(), 0, 0, 0, 0, time.UTC)
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	return !day.After(expiryDate)
+}
@@ -6,16 +6,18 @@ import (
 )
 
 type User struct {
-	ID        int64       `json:"id"`
-	FirstName string      `json:"first_name"`
-	LastName  string      `json:"last_name"`
-	Email     string      `json:"email"`
-	Password  string      `json:"password"`
-	Role      string      `json:"role"`
-	CreatedBy string      `json:"created_by"`
-	CreatedAt time.Time   `json:"created_at"`
-	UpdatedBy null.String `json:"updated_by"`
-	UpdatedAt null.Time   `json:"updated_at"`
-	DeletedBy null.String `json:"-"`
-	DeletedAt null.Time   `json:"-"`
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	Role      string `json:"role"`
+	// HourlyRate overrides the role pay rate for this worker.
+	HourlyRate null.Float  `json:"hourly_rate"`
+	CreatedBy  string      `json:"created_by"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedBy  null.String `json:"updated_by"`
+	UpdatedAt  null.Time   `json:"updated_at"`
+	DeletedBy  null.String `json:"-"`
+	DeletedAt  null.Time   `json:"-"`
 }
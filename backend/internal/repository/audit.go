@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"strconv"
+	"time"
+)
+
+type auditLogRepository struct {
+	db DBTX
+}
+
+func NewAuditLogRepository(db DBTX) AuditLogRepository {
+	return &auditLogRepository{
+		db: db,
+	}
+}
+
+func (r *auditLogRepository) WithTx(tx *sql.Tx) AuditLogRepository {
+	return &auditLogRepository{
+		db: tx,
+	}
+}
+
+type GetAuditLogListFilter struct {
+	EntityType string
+	EntityID   int64
+	Actor      string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+	After      *ListCursor
+}
+
+func (r *auditLogRepository) Save(auditLog *model.AuditLog) error {
+	query := `
+		INSERT INTO audit_logs (entity_type, entity_id, action, actor, changes, created_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`
+
+	res, err := r.db.Exec(query, auditLog.EntityType, auditLog.EntityID, auditLog.Action, auditLog.Actor, auditLog.Changes)
+	if err != nil {
+		return err
+	}
+
+	auditLog.ID, err = res.LastInsertId()
+
+	return err
+}
+
+// GetList returns the newest entries first. The id doubles as the sort key because entries
+// are only ever appended.
+func (r *auditLogRepository) GetList(filter GetAuditLogListFilter) ([]model.AuditLog, *httpresp.Pagination, error) {
+	auditLogs := []model.AuditLog{}
+	keys := []ListCursor{}
+
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+
+	conditions := " WHERE 1 = 1"
+	var conditionArgs []interface{}
+
+	if filter.EntityType != "" {
+		conditions += " AND entity_type = ?"
+		conditionArgs = append(conditionArgs, filter.EntityType)
+	}
+
+	if filter.EntityID != 0 {
+		conditions += " AND entity_id = ?"
+		conditionArgs = append(conditionArgs, filter.EntityID)
+	}
+
+	if filter.Actor != "" {
+		conditions += " AND actor = ?"
+		conditionArgs = append(conditionArgs, filter.Actor)
+	}
+
+	if filter.From != nil {
+		conditions += " AND datetime(created_at) >= datetime(?)"
+		conditionArgs = append(conditionArgs, *filter.From)
+	}
+
+	if filter.To != nil {
+		conditions += " AND datetime(created_at) < datetime(?)"
+		conditionArgs = append(conditionArgs, *filter.To)
+	}
+
+	query := `
+		SELECT id, entity_type, entity_id, action, actor, changes, created_at
+		FROM audit_logs
+	` + conditions
+
+	cursorCondition, cursorArgs := keysetCondition("id", "id", "DESC", filter.After)
+	query += cursorCondition
+
+	query += " ORDER BY id DESC LIMIT ? OFFSET ?"
+
+	if filter.After != nil {
+		filter.Offset = 0
+	}
+
+	args := append(append(append([]interface{}{}, conditionArgs...), cursorArgs...), filter.Limit+1, filter.Offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var auditLog model.AuditLog
+		err := rows.Scan(
+			&auditLog.ID, &auditLog.EntityType, &auditLog.EntityID, &auditLog.Action,
+			&auditLog.Actor, &auditLog.Changes, &auditLog.CreatedAt,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		auditLogs = append(auditLogs, auditLog)
+		keys = append(keys, ListCursor{SortValue: strconv.FormatInt(auditLog.ID, 10), ID: auditLog.ID})
+	}
+
+	hasMore := len(auditLogs) > filter.Limit
+	if hasMore {
+		auditLogs, keys = auditLogs[:filter.Limit], keys[:filter.Limit]
+	}
+
+	var totalCount int64
+	err = r.db.QueryRow("SELECT COUNT(*) FROM audit_logs"+conditions, conditionArgs...).Scan(&totalCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := newListPagination(filter.Limit, filter.Offset, filter.After, totalCount, keys, hasMore, "id")
+
+	return auditLogs, pagination, nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/andibalo/payd-test/backend/internal/model"
+)
+
+type autoApprovalRuleRepository struct {
+	db DBTX
+}
+
+func NewAutoApprovalRuleRepository(db DBTX) AutoApprovalRuleRepository {
+	return &autoApprovalRuleRepository{
+		db: db,
+	}
+}
+
+func (r *autoApprovalRuleRepository) WithTx(tx *sql.Tx) AutoApprovalRuleRepository {
+	return &autoApprovalRuleRepository{
+		db: tx,
+	}
+}
+
+type GetAutoApprovalRuleListFilter struct {
+	ActiveOnly     bool `json:"active_only"`
+	IncludeDeleted bool `json:"include_deleted"`
+}
+
+// autoApprovalRuleScanDest receives the columns of a rule in the order the queries below
+// select them.
+func autoApprovalRuleScanDest(rule *model.AutoApprovalRule) []interface{} {
+	return []interface{}{
+		&rule.ID, &rule.Name, &rule.RoleID, &rule.LocationID, &rule.MinCompletedShifts, &rule.MaxHoursBeforeStart, &rule.IsActive,
+		&rule.CreatedAt, &rule.CreatedBy, &rule.UpdatedAt, &rule.UpdatedBy, &rule.DeletedAt, &rule.DeletedBy,
+	}
+}
+
+func (r *autoApprovalRuleRepository) Save(rule *model.AutoApprovalRule) error {
+	query := `
+		INSERT INTO auto_approval_rules (name, role_id, location_id, min_completed_shifts, max_hours_before_start, is_active, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`
+
+	res, err := r.db.Exec(query, rule.Name, rule.RoleID, rule.LocationID, rule.MinCompletedShifts, rule.MaxHoursBeforeStart, rule.IsActive, rule.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	rule.ID, err = res.LastInsertId()
+
+	return err
+}
+
+// GetByID returns a non-deleted rule.
+func (r *autoApprovalRuleRepository) GetByID(id int64) (*model.AutoApprovalRule, error) {
+	rule := &model.AutoApprovalRule{}
+
+	query := `
+		SELECT id, name, role_id, location_id, min_completed_shifts, max_hours_before_start, is_active, 
+			created_at, created_by, updated_at, updated_by, deleted_at, deleted_by
+		FROM auto_approval_rules
+		WHERE id = ? AND deleted_at IS NULL
+		LIMIT 1
+	`
+
+	err := r.db.QueryRow(query, id).Scan(autoApprovalRuleScanDest(rule)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (r *autoApprovalRuleRepository) GetList(filter GetAutoApprovalRuleListFilter) ([]model.AutoApprovalRule, error) {
+	rules := []model.AutoApprovalRule{}
+
+	query := `
+		SELECT id, name, role_id, location_id, min_completed_shifts, max_hours_before_start, is_active, 
+			created_at, created_by, updated_at, updated_by, deleted_at, deleted_by
+		FROM auto_approval_rules
+		WHERE 1 = 1
+	`
+
+	if !filter.IncludeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
+
+	if filter.ActiveOnly {
+		query += " AND is_active = TRUE"
+	}
+
+	query += " ORDER BY id ASC"
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rule model.AutoApprovalRule
+		err := rows.Scan(autoApprovalRuleScanDest(&rule)...)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func (r *autoApprovalRuleRepository) UpdateByID(id int64, rule *model.AutoApprovalRule) error {
+	query := `
+		UPDATE auto_approval_rules 
+		SET name = ?, role_id = ?, location_id = ?, min_completed_shifts = ?, max_hours_before_start = ?, is_active = ?, 
+			updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	_, err := r.db.Exec(query, rule.Name, rule.RoleID, rule.LocationID, rule.MinCompletedShifts, rule.MaxHoursBeforeStart, rule.IsActive, rule.UpdatedBy, id)
+	return err
+}
+
+func (r *autoApprovalRuleRepository) DeleteByID(id int64, deletedBy string) error {
+	query := `
+		UPDATE auto_approval_rules 
+		SET deleted_at = CURRENT_TIMESTAMP, deleted_by = ? 
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	_, err := r.db.Exec(query, deletedBy, id)
+	return err
+}
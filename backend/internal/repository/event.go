@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/andibalo/payd-test/backend/internal/model"
+)
+
+type eventRepository struct {
+	db DBTX
+}
+
+func NewEventRepository(db DBTX) EventRepository {
+	return &eventRepository{
+		db: db,
+	}
+}
+
+func (r *eventRepository) WithTx(tx *sql.Tx) EventRepository {
+	return &eventRepository{
+		db: tx,
+	}
+}
+
+type GetEventListFilter struct {
+	UserID int64 `json:"user_id"`
+	Limit  int   `json:"limit"`
+}
+
+func (r *eventRepository) Save(event *model.Event) error {
+	query := `
+		INSERT INTO outbox_events (event_type, user_id, shift_id, payload, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`
+
+	res, err := r.db.Exec(query, event.EventType, event.UserID, event.ShiftID, event.Payload, event.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	event.ID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetList returns the newest events first.
+func (r *eventRepository) GetList(filter GetEventListFilter) ([]model.Event, error) {
+	events := []model.Event{}
+
+	query := `
+		SELECT id, event_type, user_id, shift_id, payload, created_at, created_by, dispatched_at
+		FROM outbox_events
+	`
+
+	var args []interface{}
+
+	if filter.UserID != 0 {
+		query += " WHERE user_id = ?"
+		args = append(args, filter.UserID)
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, filter.Limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event model.Event
+		err := rows.Scan(
+			&event.ID, &event.EventType, &event.UserID, &event.ShiftID, &event.Payload,
+			&event.CreatedAt, &event.CreatedBy, &event.DispatchedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/guregu/null/v6"
+)
+
+type locationRepository struct {
+	db DBTX
+}
+
+func NewLocationRepository(db DBTX) LocationRepository {
+	return &locationRepository{
+		db: db,
+	}
+}
+
+func (r *locationRepository) WithTx(tx *sql.Tx) LocationRepository {
+	return &locationRepository{
+		db: tx,
+	}
+}
+
+type GetLocationListFilter struct {
+	IncludeDeleted bool `json:"include_deleted"`
+}
+
+func (r *locationRepository) Save(location *model.Location) error {
+	query := `
+		INSERT INTO locations (name, address, latitude, longitude, timezone, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`
+
+	res, err := r.db.Exec(query, location.Name, location.Address, location.Latitude, location.Longitude, location.Timezone, location.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	location.ID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetByID returns a location including soft-deleted ones, so callers can tell
+// a deleted location apart from one that never existed.
+func (r *locationRepository) GetByID(id int64) (*model.Location, error) {
+	location := &model.Location{}
+
+	query := `
+		SELECT id, name, address, latitude, longitude, timezone, created_at, created_by, updated_at, updated_by, deleted_at, deleted_by
+		FROM locations
+		WHERE id = ?
+		LIMIT 1
+	`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&location.ID, &location.Name, &location.Address, &location.Latitude, &location.Longitude, &location.Timezone,
+		&location.CreatedAt, &location.CreatedBy, &location.UpdatedAt, &location.UpdatedBy, &location.DeletedAt, &location.DeletedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return location, nil
+}
+
+// GetByName looks up a location case-insensitively, including soft-deleted ones.
+func (r *locationRepository) GetByName(name string) (*model.Location, error) {
+	location := &model.Location{}
+
+	query := `
+		SELECT id, name, address, latitude, longitude, timezone, created_at, created_by, updated_at, updated_by, deleted_at, deleted_by
+		FROM locations
+		WHERE name = ? COLLATE NOCASE
+		LIMIT 1
+	`
+
+	err := r.db.QueryRow(query, name).Scan(
+		&location.ID, &location.Name, &location.Address, &location.Latitude, &location.Longitude, &location.Timezone,
+		&location.CreatedAt, &location.CreatedBy, &location.UpdatedAt, &location.UpdatedBy, &location.DeletedAt, &location.DeletedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return location, nil
+}
+
+func (r *locationRepository) GetList(filter GetLocationListFilter) ([]model.Location, error) {
+	locations := []model.Location{}
+
+	query := `
+		SELECT id, name, address, latitude, longitude, timezone, created_at, created_by, updated_at, updated_by, deleted_at, deleted_by
+		FROM locations
+	`
+
+	if !filter.IncludeDeleted {
+		query += " WHERE deleted_at IS NULL"
+	}
+
+	query += " ORDER BY name ASC"
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var location model.Location
+		err := rows.Scan(
+			&location.ID, &location.Name, &location.Address, &location.Latitude, &location.Longitude, &location.Timezone,
+			&location.CreatedAt, &location.CreatedBy, &location.UpdatedAt, &location.UpdatedBy, &location.DeletedAt, &location.DeletedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, location)
+	}
+
+	return locations, nil
+}
+
+func (r *locationRepository) UpdateByID(id int64, location *model.Location) error {
+	query := `
+		UPDATE locations 
+		SET name = ?, address = ?, latitude = ?, longitude = ?, timezone = ?, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	_, err := r.db.Exec(query, location.Name, location.Address, location.Latitude, location.Longitude, location.Timezone, location.UpdatedBy, id)
+	return err
+}
+
+func (r *locationRepository) DeleteByID(id int64, deletedBy string) error {
+	query := `
+		UPDATE locations 
+		SET deleted_at = CURRENT_TIMESTAMP, deleted_by = ? 
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	_, err := r.db.Exec(query, deletedBy, id)
+	return err
+}
+
+// CountActiveShiftsByLocationID counts the active, non-deleted shifts that are held at the location.
+func (r *locationRepository) CountActiveShiftsByLocationID(id int64) (int, error) {
+	query := `
+		SELECT COUNT(*) 
+		FROM shifts 
+		WHERE location_id = ? 
+		AND is_active = TRUE
+		AND deleted_at IS NULL
+	`
+
+	var shiftCount int
+	err := r.db.QueryRow(query, id).Scan(&shiftCount)
+	if err != nil {
+		return 0, err
+	}
+
+	return shiftCount, nil
+}
+
+// locationColumns receives the columns of a LEFT JOINed location, which are
+// all NULL when the shift has no location.
+type locationColumns struct {
+	ID        null.Int
+	Name      null.String
+	Address   null.String
+	Latitude  null.Float
+	Longitude null.Float
+	Timezone  null.String
+}
+
+func (l *locationColumns) scanDest() []interface{} {
+	return []interface{}{&l.ID, &l.Name, &l.Address, &l.Latitude, &l.Longitude, &l.Timezone}
+}
+
+func (l *locationColumns) toResponse() *response.LocationData {
+	if !l.ID.Valid {
+		return nil
+	}
+
+	return &response.LocationData{
+		ID:        l.ID.Int64,
+		Name:      l.Name.String,
+		Address:   l.Address,
+		Latitude:  l.Latitude,
+		Longitude: l.Longitude,
+		Timezone:  l.Timezone.String,
+	}
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/andibalo/payd-test/backend/pkg"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+)
+
+// ListCursor is the keyset position of a list row: the value of the column the list is
+// sorted by and the row id that breaks ties.
+type ListCursor struct {
+	SortValue string
+	ID        int64
+}
+
+// keysetCondition restricts a page to the rows that sort after the cursor. sortExpr must be
+// the same expression the query orders by, and direction is "ASC" or "DESC".
+func keysetCondition(sortExpr, idColumn, direction string, after *ListCursor) (string, []interface{}) {
+	if after == nil {
+		return "", nil
+	}
+
+	operator := "<"
+	if direction == "ASC" {
+		operator = ">"
+	}
+
+	condition := fmt.Sprintf(" AND (%s %s ? OR (%s = ? AND %s %s ?))", sortExpr, operator, sortExpr, idColumn, operator)
+
+	return condition, []interface{}{after.SortValue, after.SortValue, after.ID}
+}
+
+// newListPagination describes one page of a list. keys holds the keyset of every row on the
+// page, and hasMore tells whether rows exist after it, in which case CursorEnd points at the
+// last row so it can be sent back as the next cursor. CurrentPage is only meaningful for
+// offset pages.
+func newListPagination(limit, offset int, after *ListCursor, totalCount int64, keys []ListCursor, hasMore bool, sortBy string) *httpresp.Pagination {
+	pagination := &httpresp.Pagination{
+		CurrentElements: int64(len(keys)),
+		TotalPages:      (totalCount + int64(limit-1)) / int64(limit),
+		TotalElements:   totalCount,
+		SortBy:          sortBy,
+	}
+
+	if after == nil {
+		pagination.CurrentPage = int64(offset/limit + 1)
+	}
+
+	if len(keys) > 0 {
+		pagination.CursorStart = pkg.ToPointer(pkg.EncodeCursor(keys[0].SortValue, keys[0].ID))
+	}
+
+	if hasMore {
+		last := keys[len(keys)-1]
+		pagination.CursorEnd = pkg.ToPointer(pkg.EncodeCursor(last.SortValue, last.ID))
+	}
+
+	return pagination
+}
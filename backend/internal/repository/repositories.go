@@ -1,24 +1,105 @@
 package repository
 
 import (
+	"database/sql"
+	"errors"
 	"github.com/andibalo/payd-test/backend/internal/model"
 	"github.com/andibalo/payd-test/backend/internal/response"
 	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/guregu/null/v6"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 	"time"
 )
 
+// ErrVersionConflict is returned by versioned updates when the row was changed since it
+// was read.
+var ErrVersionConflict = errors.New("row was modified by another request")
+
+// ErrAssignmentExists is returned when a worker would get a second live assignment to the
+// same shift.
+var ErrAssignmentExists = errors.New("worker is already assigned to the shift")
+
+// checkAssignmentWrite turns a violation of the unique live assignment index into
+// ErrAssignmentExists.
+func checkAssignmentWrite(err error) error {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE {
+		return ErrAssignmentExists
+	}
+
+	return err
+}
+
+// checkVersionedUpdate turns an update that matched no row into ErrVersionConflict.
+func checkVersionedUpdate(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrVersionConflict
+	}
+
+	return nil
+}
+
+// DBTX is satisfied by both *sql.DB and *sql.Tx, so a repository can run
+// its queries either directly against the database or inside a transaction.
+type DBTX interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+type Transactor interface {
+	WithinTx(fn func(tx *sql.Tx) error) error
+}
+
 type UserRepository interface {
+	WithTx(tx *sql.Tx) UserRepository
 	Save(user *model.User) error
+	GetByID(id int64) (*model.User, error)
 	GetByEmail(email string) (*model.User, error)
+	UpdateHourlyRateByID(id int64, hourlyRate null.Float, updatedBy string) error
 	GetList(filter GetUserListFilter) ([]model.User, error)
 }
 
 type ShiftRepository interface {
+	WithTx(tx *sql.Tx) ShiftRepository
 	Save(shift *model.Shift) error
 	GetByID(id int64) (*model.Shift, error)
+	GetDeletedByID(id int64) (*model.Shift, error)
+	GetBySeriesID(seriesID int64, fromDate time.Time) ([]model.Shift, error)
+	GetSeriesOccurrenceDates(seriesID int64) (map[string]bool, error)
+	GetActiveByDateRange(fromDate, toDate time.Time) ([]model.Shift, error)
+	CheckIfSameShiftExists(shift *model.Shift) (bool, error)
+	GetShiftAssigneeIDs(shiftID int64) ([]int64, error)
+	ReassignSeries(fromSeriesID, toSeriesID int64, fromDate time.Time) error
 	GetList(filter GetShiftListFilter) ([]response.GetShiftListData, *httpresp.Pagination, error)
 	UpdateByID(id int64, shift *model.Shift) error
+	PublishByDateRange(fromDate, toDate time.Time, locationID int64, publishedBy string) ([]int64, error)
+	UpdateStatusByID(id int64, status string, updatedBy string) error
+	CancelByID(id int64, reason string, cancelledBy string) error
+	CancelShiftRequestsByShiftID(shiftID int64, status string, cancelledBy string, cancelledAt time.Time) ([]model.ShiftRequest, error)
+	ExpireShiftRequests(status string, startedBefore time.Time, createdBefore *time.Time, expiredBy string) ([]model.ShiftRequest, error)
+	GetShiftRequestsCancelledAt(shiftID int64, cancelledBy string, cancelledAt time.Time) ([]model.ShiftRequest, error)
+	GetShiftRequestQueue(shiftID int64) ([]model.ShiftRequest, error)
+	GetApprovedShiftRequest(userID, shiftID int64) (*model.ShiftRequest, error)
+	EndWorkerShiftsByShiftID(shiftID int64, endedBy string, endedAt time.Time) ([]model.WorkerShift, error)
+	GetWorkerShiftsEndedAt(shiftID int64, endedBy string, endedAt time.Time) ([]model.WorkerShift, error)
+	RestoreWorkerShiftByID(id int64, restoredBy string) error
+	GetWorkerShiftByID(id int64) (*model.WorkerShift, error)
+	EndWorkerShiftByID(id int64, endedBy string) error
 	DeleteByID(id int64, deletedBy string) error
+	RestoreByID(id int64, restoredBy string) error
+	GetBreaksByShiftID(shiftID int64) ([]model.ShiftBreak, error)
+	SaveBreaks(shiftID int64, breaks []model.ShiftBreak) error
+	DeleteBreaksByShiftID(shiftID int64) error
+	GetRequiredSkillIDs(shiftID int64) ([]int64, error)
+	SaveRequiredSkills(shiftID int64, skillIDs []int64) error
+	DeleteRequiredSkillsByShiftID(shiftID int64) error
 	GetShiftRequestByID(id int64) (*model.ShiftRequest, error)
 	GetShiftRequestList(filter GetShiftRequestListFilter) ([]response.GetShiftRequestListData, *httpresp.Pagination, error)
 	SaveShiftRequest(shiftRequest *model.ShiftRequest) error
@@ -26,7 +107,90 @@ type ShiftRepository interface {
 	UpdateShiftRequestByID(id int64, sr *model.ShiftRequest) error
 	CheckUserAssignedShiftExistsByDate(userID int64, shiftDate time.Time) (bool, error)
 	CheckIfShiftIsAlreadyAssigned(shiftID int64) (bool, error)
+	GetShiftFilledSlotCount(shiftID int64) (int, error)
 	GetUserWeeklyAssignedShiftCountByDate(userID int64, shiftDate time.Time) (int, error)
-	CheckIfShiftRequestTimeOverlaps(userID int64, shiftDate, requestedStartTime, requestedEndTime time.Time) (bool, error)
+	GetUserCompletedShiftCount(userID int64) (int, error)
+	CheckIfShiftRequestTimeOverlaps(userID int64, requestedStartTime, requestedEndTime time.Time) (bool, error)
+	CheckIfAssignedShiftTimeOverlaps(userID int64, startTime, endTime time.Time) (bool, error)
 	GetShiftAssignmentList(filter GetShiftAssignmentListFilter) ([]response.GetShiftAssignmentListData, *httpresp.Pagination, error)
+	GetCostLines(filter GetShiftCostFilter) ([]ShiftCostLine, error)
+}
+
+type ShiftSeriesRepository interface {
+	WithTx(tx *sql.Tx) ShiftSeriesRepository
+	Save(series *model.ShiftSeries) error
+	GetByID(id int64) (*model.ShiftSeries, error)
+	GetList(filter GetShiftSeriesListFilter) ([]model.ShiftSeries, *httpresp.Pagination, error)
+	UpdateByID(id int64, series *model.ShiftSeries) error
+	DeleteByID(id int64, deletedBy string) error
+}
+
+type ShiftRoleRepository interface {
+	Save(role *model.ShiftRoleEnum) error
+	GetByID(id int64) (*model.ShiftRoleEnum, error)
+	GetByName(roleName string) (*model.ShiftRoleEnum, error)
+	GetList(filter GetShiftRoleListFilter) ([]model.ShiftRoleEnum, error)
+	UpdateByID(id int64, role *model.ShiftRoleEnum) error
+	DeleteByID(id int64, deletedBy string) error
+	RestoreByID(id int64, restoredBy string) error
+	CountActiveShiftsByRoleID(id int64) (int, error)
+}
+
+type SkillRepository interface {
+	WithTx(tx *sql.Tx) SkillRepository
+	Save(skill *model.Skill) error
+	GetByID(id int64) (*model.Skill, error)
+	GetByName(name string) (*model.Skill, error)
+	GetList(filter GetSkillListFilter) ([]model.Skill, error)
+	UpdateByID(id int64, skill *model.Skill) error
+	DeleteByID(id int64, deletedBy string) error
+	GetWorkerSkillsByUserID(userID int64) ([]model.WorkerSkill, error)
+	SaveWorkerSkill(workerSkill *model.WorkerSkill) error
+	DeleteWorkerSkill(userID, skillID int64, deletedBy string) (bool, error)
+}
+
+type LocationRepository interface {
+	WithTx(tx *sql.Tx) LocationRepository
+	Save(location *model.Location) error
+	GetByID(id int64) (*model.Location, error)
+	GetByName(name string) (*model.Location, error)
+	GetList(filter GetLocationListFilter) ([]model.Location, error)
+	UpdateByID(id int64, location *model.Location) error
+	DeleteByID(id int64, deletedBy string) error
+	CountActiveShiftsByLocationID(id int64) (int, error)
+}
+
+type AutoApprovalRuleRepository interface {
+	WithTx(tx *sql.Tx) AutoApprovalRuleRepository
+	Save(rule *model.AutoApprovalRule) error
+	GetByID(id int64) (*model.AutoApprovalRule, error)
+	GetList(filter GetAutoApprovalRuleListFilter) ([]model.AutoApprovalRule, error)
+	UpdateByID(id int64, rule *model.AutoApprovalRule) error
+	DeleteByID(id int64, deletedBy string) error
+}
+
+type ShiftSwapRepository interface {
+	WithTx(tx *sql.Tx) ShiftSwapRepository
+	Save(swap *model.ShiftSwap) error
+	GetByID(id int64) (*model.ShiftSwap, error)
+	GetList(filter GetShiftSwapListFilter) ([]model.ShiftSwap, error)
+	UpdateByID(id int64, swap *model.ShiftSwap) error
+	CheckOpenSwapExistsByAssignmentID(assignmentID int64) (bool, error)
+}
+
+type AuditLogRepository interface {
+	WithTx(tx *sql.Tx) AuditLogRepository
+	Save(auditLog *model.AuditLog) error
+	GetList(filter GetAuditLogListFilter) ([]model.AuditLog, *httpresp.Pagination, error)
+}
+
+type RetentionRepository interface {
+	PurgeDeleted(deletedBefore time.Time) (map[string]int64, error)
+	ArchiveCompletedShifts(archivePath string, endedBefore time.Time) (map[string]int64, error)
+}
+
+type EventRepository interface {
+	WithTx(tx *sql.Tx) EventRepository
+	Save(event *model.Event) error
+	GetList(filter GetEventListFilter) ([]model.Event, error)
 }
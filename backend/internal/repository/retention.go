@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/andibalo/payd-test/backend/internal/constants"
+	"strings"
+	"time"
+)
+
+// shiftOwnedTables hold rows that belong to a single shift through shift_id. They are purged
+// or archived together with their shift, children before the shift itself.
+var shiftOwnedTables = []string{
+	"shift_breaks",
+	"shift_required_skills",
+	"shift_requests",
+	"worker_shift_assignments",
+	"outbox_events",
+}
+
+// shiftSwapsWhere matches the swaps that name any of the given shifts on either side. A
+// swap belongs to two shifts, so it goes as soon as one of them does, before the
+// assignments it points at.
+const shiftSwapsWhere = "proposer_shift_id IN (%[1]s) OR recipient_shift_id IN (%[1]s)"
+
+type retentionRepository struct {
+	db *sql.DB
+}
+
+func NewRetentionRepository(db *sql.DB) RetentionRepository {
+	return &retentionRepository{
+		db: db,
+	}
+}
+
+// PurgeDeleted hard-deletes rows that were soft-deleted before deletedBefore and returns
+// how many rows went from each table. A purged shift takes everything that hangs off it
+// with it. Roles, locations, skills and series are only purged once nothing references
+// them. audit_logs is append-only and never touched.
+func (r *retentionRepository) PurgeDeleted(deletedBefore time.Time) (map[string]int64, error) {
+	purged := map[string]int64{}
+	before := deletedBefore.UTC().Format(constants.DATETIME_FORMAT)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	deletedShiftIDs := `SELECT id FROM shifts WHERE deleted_at IS NOT NULL AND datetime(deleted_at) < datetime(?)`
+	err = execAndCount(tx, purged, "shift_swaps", "DELETE FROM shift_swaps WHERE "+fmt.Sprintf(shiftSwapsWhere, deletedShiftIDs), before, before)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, table := range shiftOwnedTables {
+		err = execAndCount(tx, purged, table, fmt.Sprintf("DELETE FROM %s WHERE shift_id IN (%s)", table, deletedShiftIDs), before)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	purgeQueries := []struct {
+		table        string
+		unreferenced string
+	}{
+		{"shifts", ""},
+		{"shift_requests", ""},
+		{"worker_shift_assignments", ""},
+		{"worker_skills", ""},
+		{"shift_series", `
+			AND id NOT IN (SELECT series_id FROM shifts WHERE series_id IS NOT NULL)`},
+		{"skills", `
+			AND id NOT IN (SELECT skill_id FROM worker_skills)
+			AND id NOT IN (SELECT skill_id FROM shift_required_skills)`},
+		{"locations", `
+			AND id NOT IN (SELECT location_id FROM shifts WHERE location_id IS NOT NULL)
+			AND id NOT IN (SELECT location_id FROM shift_series WHERE location_id IS NOT NULL)`},
+		{"shift_role_enum", `
+			AND id NOT IN (SELECT role_id FROM shifts)
+			AND id NOT IN (SELECT role_id FROM shift_series)`},
+	}
+
+	for _, purge := range purgeQueries {
+		query := fmt.Sprintf(`
+			DELETE FROM %s
+			WHERE deleted_at IS NOT NULL AND datetime(deleted_at) < datetime(?) %s
+		`, purge.table, purge.unreferenced)
+
+		err = execAndCount(tx, purged, purge.table, query, before)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return purged, nil
+}
+
+// ArchiveCompletedShifts moves completed shifts that ended before endedBefore, with the rows
+// they own, into the SQLite file at archivePath and returns how many rows moved from each
+// table. The roles, locations and skills they point at are copied but stay in the main
+// database. Both files change in one transaction, so a failed run moves nothing.
+func (r *retentionRepository) ArchiveCompletedShifts(archivePath string, endedBefore time.Time) (map[string]int64, error) {
+	ctx := context.Background()
+
+	// ATTACH only applies to one connection and cannot run inside a transaction
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, "ATTACH DATABASE ? AS archive", archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.ExecContext(ctx, "DETACH DATABASE archive")
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	archived := map[string]int64{}
+	args := []interface{}{constants.SHIFT_STATUS_COMPLETED, endedBefore.UTC().Format(constants.DATETIME_FORMAT)}
+	shiftIDs := `
+		SELECT id FROM main.shifts
+		WHERE status = ? AND deleted_at IS NULL AND datetime(end_time) < datetime(?)
+	`
+
+	lookups := []struct{ table, referencedIDs string }{
+		{"shift_role_enum", fmt.Sprintf("SELECT role_id FROM main.shifts WHERE id IN (%s)", shiftIDs)},
+		{"locations", fmt.Sprintf("SELECT location_id FROM main.shifts WHERE id IN (%s)", shiftIDs)},
+		{"skills", fmt.Sprintf("SELECT skill_id FROM main.shift_required_skills WHERE shift_id IN (%s)", shiftIDs)},
+	}
+
+	for _, lookup := range lookups {
+		err = ensureArchiveTable(tx, lookup.table)
+		if err != nil {
+			return nil, err
+		}
+
+		where := fmt.Sprintf("id IN (%s) AND id NOT IN (SELECT id FROM archive.%s)", lookup.referencedIDs, lookup.table)
+		err = copyToArchive(tx, lookup.table, where, args...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = moveToArchive(tx, archived, "shift_swaps", fmt.Sprintf(shiftSwapsWhere, shiftIDs), append(args, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, table := range shiftOwnedTables {
+		err = moveToArchive(tx, archived, table, fmt.Sprintf("shift_id IN (%s)", shiftIDs), args...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = moveToArchive(tx, archived, "shifts", fmt.Sprintf("id IN (%s)", shiftIDs), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return archived, nil
+}
+
+// ensureArchiveTable creates table in the archive database with the columns of the main
+// table, and adds the columns the main table gained since the archive table was created.
+func ensureArchiveTable(tx *sql.Tx, table string) error {
+	_, err := tx.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS archive.%s AS SELECT * FROM main.%s WHERE 0", table, table))
+	if err != nil {
+		return err
+	}
+
+	mainColumns, err := tableColumns(tx, "main", table)
+	if err != nil {
+		return err
+	}
+
+	archiveColumns, err := tableColumns(tx, "archive", table)
+	if err != nil {
+		return err
+	}
+
+	archived := map[string]bool{}
+	for _, column := range archiveColumns {
+		archived[column.name] = true
+	}
+
+	for _, column := range mainColumns {
+		if archived[column.name] {
+			continue
+		}
+
+		_, err = tx.Exec(fmt.Sprintf("ALTER TABLE archive.%s ADD COLUMN %s %s", table, column.name, column.dataType))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveToArchive copies the rows of table matching where to the archive database, deletes
+// them from the main one and adds the number moved to counts[table].
+func moveToArchive(tx *sql.Tx, counts map[string]int64, table, where string, args ...interface{}) error {
+	err := ensureArchiveTable(tx, table)
+	if err != nil {
+		return err
+	}
+
+	err = copyToArchive(tx, table, where, args...)
+	if err != nil {
+		return err
+	}
+
+	return execAndCount(tx, counts, table, fmt.Sprintf("DELETE FROM main.%s WHERE %s", table, where), args...)
+}
+
+// copyToArchive copies the rows of table matching where from the main to the archive
+// database, naming the columns so the two tables may order them differently.
+func copyToArchive(tx *sql.Tx, table, where string, args ...interface{}) error {
+	columns, err := tableColumns(tx, "main", table)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(columns))
+	for _, column := range columns {
+		names = append(names, column.name)
+	}
+
+	columnList := strings.Join(names, ", ")
+	_, err = tx.Exec(fmt.Sprintf("INSERT INTO archive.%s (%s) SELECT %s FROM main.%s WHERE %s", table, columnList, columnList, table, where), args...)
+
+	return err
+}
+
+type tableColumn struct {
+	name     string
+	dataType string
+}
+
+// tableColumns lists the columns of table in the given schema in declaration order.
+func tableColumns(tx *sql.Tx, schema, table string) ([]tableColumn, error) {
+	rows, err := tx.Query("SELECT name, type FROM pragma_table_info(?, ?) ORDER BY cid", table, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []tableColumn
+	for rows.Next() {
+		var column tableColumn
+		err := rows.Scan(&column.name, &column.dataType)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+
+	return columns, rows.Err()
+}
+
+// execAndCount runs a delete and adds the number of affected rows to counts[table].
+func execAndCount(tx *sql.Tx, counts map[string]int64, table, query string, args ...interface{}) error {
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	counts[table] += affected
+
+	return nil
+}
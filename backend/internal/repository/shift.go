@@ -2,230 +2,1143 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
 	"github.com/andibalo/payd-test/backend/internal/constants"
 	"github.com/andibalo/payd-test/backend/internal/model"
 	"github.com/andibalo/payd-test/backend/internal/response"
 	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/guregu/null/v6"
+	"strings"
 	"time"
 )
 
 type shiftRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewShiftRepository(db *sql.DB) ShiftRepository {
+func NewShiftRepository(db DBTX) ShiftRepository {
 	return &shiftRepository{
 		db: db,
 	}
 }
 
+func (r *shiftRepository) WithTx(tx *sql.Tx) ShiftRepository {
+	return &shiftRepository{
+		db: tx,
+	}
+}
+
 type GetShiftListFilter struct {
+	// ShowOnlyUnassigned keeps only shifts that still have open slots.
 	ShowOnlyUnassigned bool `json:"show_only_unassigned"`
-	Limit              int  `json:"limit"`
-	Offset             int  `json:"offset"`
+	// FromDate and ToDate bound the local shift date and are ignored when zero.
+	FromDate       time.Time `json:"from_date"`
+	ToDate         time.Time `json:"to_date"`
+	RoleIDs        []int64   `json:"role_ids"`
+	LocationID     int64     `json:"location_id"`
+	IsActive       *bool     `json:"is_active"`
+	Status         string    `json:"status"`
+	AssignedUserID int64     `json:"assigned_user_id"`
+	SortBy         string    `json:"sort_by"`
+	SortDirection  string    `json:"sort_direction"`
+	Limit          int       `json:"limit"`
+	Offset         int       `json:"offset"`
+	// After switches to keyset pagination and ignores Offset.
+	After *ListCursor `json:"after"`
+	// Deleted lists the trash, the soft-deleted shifts, instead of the live ones.
+	Deleted bool `json:"deleted"`
+}
+
+// shiftListSortColumns maps the sort_by values accepted by the shift list to their columns.
+var shiftListSortColumns = map[string]string{
+	constants.SHIFT_LIST_SORT_CREATED_AT: "datetime(shifts.created_at)",
+	constants.SHIFT_LIST_SORT_DATE:       "date(shifts.date)",
+	constants.SHIFT_LIST_SORT_START_TIME: "datetime(shifts.start_time)",
+	constants.SHIFT_LIST_SORT_ROLE:       "shift_role_enum.role_name COLLATE NOCASE",
+	constants.SHIFT_LIST_SORT_DELETED_AT: "datetime(shifts.deleted_at)",
+}
+
+// shiftListConditions builds the WHERE clause shared by the shift list page and count
+// queries, so both always see the same rows.
+func shiftListConditions(filter GetShiftListFilter) (string, []interface{}) {
+	conditions := " WHERE shifts.deleted_at IS NULL"
+	if filter.Deleted {
+		conditions = " WHERE shifts.deleted_at IS NOT NULL"
+	}
+	var args []interface{}
+
+	if filter.ShowOnlyUnassigned {
+		conditions += `
+			AND (
+				SELECT COUNT(*) 
+				FROM worker_shift_assignments 
+				WHERE worker_shift_assignments.shift_id = shifts.id 
+				AND worker_shift_assignments.deleted_at IS NULL
+			) < shifts.headcount
+		`
+	}
+
+	if !filter.FromDate.IsZero() {
+		conditions += " AND date(shifts.date) >= date(?)"
+		args = append(args, filter.FromDate.Format(constants.DATE_FORMAT))
+	}
+
+	if !filter.ToDate.IsZero() {
+		conditions += " AND date(shifts.date) <= date(?)"
+		args = append(args, filter.ToDate.Format(constants.DATE_FORMAT))
+	}
+
+	if len(filter.RoleIDs) > 0 {
+		conditions += " AND shifts.role_id IN (?" + strings.Repeat(", ?", len(filter.RoleIDs)-1) + ")"
+		for _, roleID := range filter.RoleIDs {
+			args = append(args, roleID)
+		}
+	}
+
+	if filter.LocationID != 0 {
+		conditions += " AND shifts.location_id = ?"
+		args = append(args, filter.LocationID)
+	}
+
+	if filter.IsActive != nil {
+		conditions += " AND shifts.is_active = ?"
+		args = append(args, *filter.IsActive)
+	}
+
+	if filter.Status != "" {
+		conditions += " AND shifts.status = ?"
+		args = append(args, filter.Status)
+	}
+
+	if filter.AssignedUserID != 0 {
+		conditions += `
+			AND EXISTS (
+				SELECT 1 
+				FROM worker_shift_assignments 
+				WHERE worker_shift_assignments.shift_id = shifts.id 
+				AND worker_shift_assignments.user_id = ?
+				AND worker_shift_assignments.deleted_at IS NULL
+			)
+		`
+		args = append(args, filter.AssignedUserID)
+	}
+
+	return conditions, args
 }
 
 type GetShiftRequestListFilter struct {
-	UserID int64  `json:"user_id"`
-	Status string `json:"status"`
-	Limit  int    `json:"limit"`
-	Offset int    `json:"offset"`
+	UserID int64       `json:"user_id"`
+	Status string      `json:"status"`
+	Limit  int         `json:"limit"`
+	Offset int         `json:"offset"`
+	After  *ListCursor `json:"after"`
 }
 
 type GetShiftAssignmentListFilter struct {
-	UserID int64 `json:"user_id"`
-	Limit  int   `json:"limit"`
-	Offset int   `json:"offset"`
+	UserID int64       `json:"user_id"`
+	Limit  int         `json:"limit"`
+	Offset int         `json:"offset"`
+	After  *ListCursor `json:"after"`
+}
+
+func (r *shiftRepository) Save(shift *model.Shift) error {
+	query := `
+		INSERT INTO shifts (date, start_time, end_time, timezone, role_id, location_id, headcount, series_id, is_active, status, published_at, published_by, hourly_rate, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`
+
+	res, err := r.db.Exec(query, shift.Date, shift.StartTime, shift.EndTime, shift.Timezone, shift.RoleID, shift.LocationID, shift.Headcount, shift.SeriesID, shift.IsActive,
+		shift.Status, shift.PublishedAt, shift.PublishedBy, shift.HourlyRate, shift.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	shift.ID = int(id)
+
+	return nil
+}
+
+func (r *shiftRepository) GetByID(id int64) (*model.Shift, error) {
+	return r.getByID(id, "deleted_at IS NULL")
+}
+
+// GetDeletedByID reads a shift from the trash.
+func (r *shiftRepository) GetDeletedByID(id int64) (*model.Shift, error) {
+	return r.getByID(id, "deleted_at IS NOT NULL")
+}
+
+func (r *shiftRepository) getByID(id int64, deletedCondition string) (*model.Shift, error) {
+	shift := &model.Shift{}
+
+	query := `
+		SELECT id, date, start_time, end_time, timezone, role_id, location_id, headcount, series_id, is_active, status, published_at, published_by, cancelled_at, cancelled_by, cancel_reason, hourly_rate, version, created_by, created_at, 
+			updated_by, updated_at, deleted_by, deleted_at
+		FROM shifts 
+		WHERE id = ? AND ` + deletedCondition + `
+		LIMIT 1
+	`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&shift.ID, &shift.Date, &shift.StartTime, &shift.EndTime, &shift.Timezone, &shift.RoleID, &shift.LocationID, &shift.Headcount, &shift.SeriesID, &shift.IsActive,
+		&shift.Status, &shift.PublishedAt, &shift.PublishedBy, &shift.CancelledAt, &shift.CancelledBy, &shift.CancelReason, &shift.HourlyRate, &shift.Version,
+		&shift.CreatedBy, &shift.CreatedAt, &shift.UpdatedBy, &shift.UpdatedAt, &shift.DeletedBy, &shift.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return shift, nil
+}
+
+func (r *shiftRepository) GetBySeriesID(seriesID int64, fromDate time.Time) ([]model.Shift, error) {
+	shifts := []model.Shift{}
+
+	query := `
+		SELECT id, date, start_time, end_time, timezone, role_id, location_id, headcount, series_id, is_active, status, published_at, published_by, cancelled_at, cancelled_by, cancel_reason, hourly_rate, version, created_by, created_at, 
+			updated_by, updated_at, deleted_by, deleted_at
+		FROM shifts 
+		WHERE series_id = ? 
+		AND date(date) >= date(?)
+		AND deleted_at IS NULL
+		ORDER BY date ASC
+	`
+
+	rows, err := r.db.Query(query, seriesID, fromDate.Format(constants.DATE_FORMAT))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shift model.Shift
+		err := rows.Scan(
+			&shift.ID, &shift.Date, &shift.StartTime, &shift.EndTime, &shift.Timezone, &shift.RoleID, &shift.LocationID, &shift.Headcount, &shift.SeriesID, &shift.IsActive,
+			&shift.Status, &shift.PublishedAt, &shift.PublishedBy, &shift.CancelledAt, &shift.CancelledBy, &shift.CancelReason, &shift.HourlyRate, &shift.Version,
+			&shift.CreatedBy, &shift.CreatedAt, &shift.UpdatedBy, &shift.UpdatedAt, &shift.DeletedBy, &shift.DeletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		shifts = append(shifts, shift)
+	}
+
+	return shifts, nil
+}
+
+// GetActiveByDateRange returns the active, non-cancelled shifts whose local start date falls
+// within the range.
+func (r *shiftRepository) GetActiveByDateRange(fromDate, toDate time.Time) ([]model.Shift, error) {
+	shifts := []model.Shift{}
+
+	query := `
+		SELECT id, date, start_time, end_time, timezone, role_id, location_id, headcount, series_id, is_active, status, published_at, published_by, cancelled_at, cancelled_by, cancel_reason, hourly_rate, version, created_by, created_at, 
+			updated_by, updated_at, deleted_by, deleted_at
+		FROM shifts 
+		WHERE date(date) BETWEEN date(?) AND date(?)
+		AND is_active = 1
+		AND status != ?
+		AND deleted_at IS NULL
+		ORDER BY start_time ASC, id ASC
+	`
+
+	rows, err := r.db.Query(query, fromDate.Format(constants.DATE_FORMAT), toDate.Format(constants.DATE_FORMAT), constants.SHIFT_STATUS_CANCELLED)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shift model.Shift
+		err := rows.Scan(
+			&shift.ID, &shift.Date, &shift.StartTime, &shift.EndTime, &shift.Timezone, &shift.RoleID, &shift.LocationID, &shift.Headcount, &shift.SeriesID, &shift.IsActive,
+			&shift.Status, &shift.PublishedAt, &shift.PublishedBy, &shift.CancelledAt, &shift.CancelledBy, &shift.CancelReason, &shift.HourlyRate, &shift.Version,
+			&shift.CreatedBy, &shift.CreatedAt, &shift.UpdatedBy, &shift.UpdatedAt, &shift.DeletedBy, &shift.DeletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		shifts = append(shifts, shift)
+	}
+
+	return shifts, nil
+}
+
+// CheckIfSameShiftExists reports whether a live shift with the same role, location and
+// start and end instants already exists.
+func (r *shiftRepository) CheckIfSameShiftExists(shift *model.Shift) (bool, error) {
+	query := `
+		SELECT COUNT(*) 
+		FROM shifts 
+		WHERE role_id = ? 
+		AND location_id IS ?
+		AND datetime(start_time) = datetime(?)
+		AND datetime(end_time) = datetime(?)
+		AND deleted_at IS NULL
+	`
+
+	var count int
+	err := r.db.QueryRow(
+		query,
+		shift.RoleID,
+		shift.LocationID,
+		shift.StartTime.UTC().Format(constants.DATETIME_FORMAT),
+		shift.EndTime.UTC().Format(constants.DATETIME_FORMAT)).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *shiftRepository) GetShiftAssigneeIDs(shiftID int64) ([]int64, error) {
+	userIDs := []int64{}
+
+	query := `
+		SELECT user_id 
+		FROM worker_shift_assignments 
+		WHERE shift_id = ? 
+		AND deleted_at IS NULL
+		ORDER BY id ASC
+	`
+
+	rows, err := r.db.Query(query, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID int64
+		err := rows.Scan(&userID)
+		if err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, nil
+}
+
+// GetSeriesOccurrenceDates returns every date a series already has a shift row for,
+// including soft-deleted ones so that cancelled occurrences are not generated again.
+func (r *shiftRepository) GetSeriesOccurrenceDates(seriesID int64) (map[string]bool, error) {
+	dates := map[string]bool{}
+
+	query := `
+		SELECT date(date) 
+		FROM shifts 
+		WHERE series_id = ?
+	`
+
+	rows, err := r.db.Query(query, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var date string
+		err := rows.Scan(&date)
+		if err != nil {
+			return nil, err
+		}
+		dates[date] = true
+	}
+
+	return dates, nil
+}
+
+// ReassignSeries moves the shifts of a series on or after fromDate to another series,
+// used when a series is split so existing rows (and their assignments) are kept.
+func (r *shiftRepository) ReassignSeries(fromSeriesID, toSeriesID int64, fromDate time.Time) error {
+	query := `
+		UPDATE shifts 
+		SET series_id = ?, version = version + 1
+		WHERE series_id = ? AND date(date) >= date(?)
+	`
+
+	_, err := r.db.Exec(query, toSeriesID, fromSeriesID, fromDate.Format(constants.DATE_FORMAT))
+	return err
+}
+
+func (r *shiftRepository) GetList(filter GetShiftListFilter) ([]response.GetShiftListData, *httpresp.Pagination, error) {
+	shifts := []response.GetShiftListData{}
+	keys := []ListCursor{}
+
+	if filter.Limit <= 0 {
+		filter.Limit = 10
+	}
+
+	if filter.SortBy == "" {
+		filter.SortBy = constants.SHIFT_LIST_SORT_CREATED_AT
+	}
+
+	sortColumn, ok := shiftListSortColumns[filter.SortBy]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported shift list sort %q", filter.SortBy)
+	}
+
+	sortDirection := "DESC"
+	if strings.EqualFold(filter.SortDirection, constants.SORT_DIRECTION_ASC) {
+		sortDirection = "ASC"
+	}
+
+	query := `
+		SELECT 
+			shifts.id, 
+			shifts.date, 
+			shifts.start_time, 
+			shifts.end_time, 
+			shifts.timezone, 
+			shifts.role_id, 
+			shift_role_enum.role_name, 
+			locations.id, 
+			locations.name, 
+			locations.address, 
+			locations.latitude, 
+			locations.longitude, 
+			locations.timezone, 
+			shifts.headcount,
+			(
+				SELECT COUNT(*) 
+				FROM worker_shift_assignments 
+				WHERE worker_shift_assignments.shift_id = shifts.id 
+				AND worker_shift_assignments.deleted_at IS NULL
+			) AS filled_count,
+			(
+				SELECT COALESCE(SUM(shift_breaks.duration_minutes), 0) 
+				FROM shift_breaks 
+				WHERE shift_breaks.shift_id = shifts.id 
+				AND shift_breaks.is_paid = 0
+			) AS unpaid_break_minutes,
+			shifts.is_active, 
+			shifts.status, 
+			shifts.published_at, 
+			shifts.published_by, 
+			shifts.version, 
+			shifts.created_by, 
+			shifts.created_at, 
+			shifts.updated_by, 
+			shifts.updated_at, 
+			shifts.deleted_by, 
+			shifts.deleted_at, 
+			` + sortColumn + ` AS sort_key
+		FROM shifts
+		JOIN shift_role_enum ON shifts.role_id = shift_role_enum.id
+		LEFT JOIN locations ON shifts.location_id = locations.id
+	`
+
+	conditions, conditionArgs := shiftListConditions(filter)
+	query += conditions
+
+	cursorCondition, cursorArgs := keysetCondition(sortColumn, "shifts.id", sortDirection, filter.After)
+	query += cursorCondition
+
+	query += fmt.Sprintf(" ORDER BY %s %s, shifts.id %s", sortColumn, sortDirection, sortDirection)
+
+	query += " LIMIT ? OFFSET ?"
+
+	if filter.After != nil {
+		filter.Offset = 0
+	}
+
+	args := append(append(append([]interface{}{}, conditionArgs...), cursorArgs...), filter.Limit+1, filter.Offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shift response.GetShiftListData
+		var location locationColumns
+		var key ListCursor
+		dest := []interface{}{&shift.ID, &shift.Date, &shift.StartTime, &shift.EndTime, &shift.Timezone, &shift.RoleID, &shift.RoleName}
+		dest = append(dest, location.scanDest()...)
+		dest = append(dest, &shift.RequiredHeadcount, &shift.FilledCount, &shift.UnpaidBreakMinutes, &shift.IsActive, &shift.Status, &shift.PublishedAt, &shift.PublishedBy, &shift.Version, &shift.CreatedBy, &shift.CreatedAt, &shift.UpdatedBy, &shift.UpdatedAt, &shift.DeletedBy, &shift.DeletedAt, &key.SortValue)
+
+		err := rows.Scan(dest...)
+		if err != nil {
+			return nil, nil, err
+		}
+		shift.Location = location.toResponse()
+		shift.NetWorkingMinutes = netWorkingMinutes(shift.StartTime, shift.EndTime, shift.UnpaidBreakMinutes)
+		key.ID = int64(shift.ID)
+		shifts = append(shifts, shift)
+		keys = append(keys, key)
+	}
+
+	hasMore := len(shifts) > filter.Limit
+	if hasMore {
+		shifts, keys = shifts[:filter.Limit], keys[:filter.Limit]
+	}
+
+	countQuery := `
+		SELECT COUNT(*) 
+		FROM shifts
+		JOIN shift_role_enum ON shifts.role_id = shift_role_enum.id
+	` + conditions
+
+	var totalCount int64
+	countRow := r.db.QueryRow(countQuery, conditionArgs...)
+	err = countRow.Scan(&totalCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := newListPagination(filter.Limit, filter.Offset, filter.After, totalCount, keys, hasMore, filter.SortBy)
+
+	return shifts, pagination, nil
+}
+
+type GetShiftCostFilter struct {
+	FromDate   time.Time
+	ToDate     time.Time
+	LocationID int64
+}
+
+// ShiftCostLine is either one assigned worker on a shift, or the open slots of a shift
+// when UserID is null. HourlyRate is the shift rate, then the worker rate, then the role
+// rate, and is null when none of them is set.
+type ShiftCostLine struct {
+	ShiftID           int64
+	RoleID            int64
+	RoleName          string
+	LocationID        null.Int
+	LocationName      null.String
+	UserID            null.Int
+	Slots             int
+	NetWorkingMinutes int
+	HourlyRate        null.Float
+}
+
+// GetCostLines returns the assigned workers and open slots of the live, non-cancelled
+// shifts whose local start date falls within the range.
+func (r *shiftRepository) GetCostLines(filter GetShiftCostFilter) ([]ShiftCostLine, error) {
+	lines := []ShiftCostLine{}
+
+	conditions := `
+		AND date(shifts.date) BETWEEN date(?) AND date(?)
+		AND shifts.is_active = 1
+		AND shifts.status != ?
+		AND shifts.deleted_at IS NULL
+	`
+	conditionArgs := []interface{}{filter.FromDate.Format(constants.DATE_FORMAT), filter.ToDate.Format(constants.DATE_FORMAT), constants.SHIFT_STATUS_CANCELLED}
+
+	if filter.LocationID > 0 {
+		conditions += " AND shifts.location_id = ?"
+		conditionArgs = append(conditionArgs, filter.LocationID)
+	}
+
+	unpaidBreakMinutes := `(
+				SELECT COALESCE(SUM(shift_breaks.duration_minutes), 0) 
+				FROM shift_breaks 
+				WHERE shift_breaks.shift_id = shifts.id 
+				AND shift_breaks.is_paid = 0
+			)`
+
+	filledCount := `(
+				SELECT COUNT(*) 
+				FROM worker_shift_assignments 
+				WHERE worker_shift_assignments.shift_id = shifts.id 
+				AND worker_shift_assignments.deleted_at IS NULL
+			)`
+
+	query := `
+		SELECT 
+			shifts.id, 
+			shifts.role_id, 
+			shift_role_enum.role_name, 
+			locations.id, 
+			locations.name, 
+			worker_shift_assignments.user_id, 
+			1, 
+			shifts.start_time, 
+			shifts.end_time, 
+			` + unpaidBreakMinutes + `, 
+			COALESCE(shifts.hourly_rate, users.hourly_rate, shift_role_enum.hourly_rate)
+		FROM worker_shift_assignments
+		JOIN shifts ON worker_shift_assignments.shift_id = shifts.id
+		JOIN users ON worker_shift_assignments.user_id = users.id
+		JOIN shift_role_enum ON shifts.role_id = shift_role_enum.id
+		LEFT JOIN locations ON shifts.location_id = locations.id
+		WHERE worker_shift_assignments.deleted_at IS NULL
+	` + conditions + `
+		UNION ALL
+		SELECT 
+			shifts.id, 
+			shifts.role_id, 
+			shift_role_enum.role_name, 
+			locations.id, 
+			locations.name, 
+			NULL, 
+			shifts.headcount - ` + filledCount + `, 
+			shifts.start_time, 
+			shifts.end_time, 
+			` + unpaidBreakMinutes + `, 
+			COALESCE(shifts.hourly_rate, shift_role_enum.hourly_rate)
+		FROM shifts
+		JOIN shift_role_enum ON shifts.role_id = shift_role_enum.id
+		LEFT JOIN locations ON shifts.location_id = locations.id
+		WHERE shifts.headcount > ` + filledCount + conditions
+
+	args := append(append([]interface{}{}, conditionArgs...), conditionArgs...)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line ShiftCostLine
+		var startTime, endTime time.Time
+		var unpaidMinutes int
+		err := rows.Scan(
+			&line.ShiftID, &line.RoleID, &line.RoleName, &line.LocationID, &line.LocationName, &line.UserID, &line.Slots,
+			&startTime, &endTime, &unpaidMinutes, &line.HourlyRate,
+		)
+		if err != nil {
+			return nil, err
+		}
+		line.NetWorkingMinutes = netWorkingMinutes(startTime, endTime, unpaidMinutes)
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+// netWorkingMinutes is the scheduled length of a shift minus its unpaid breaks.
+func netWorkingMinutes(start, end time.Time, unpaidBreakMinutes int) int {
+	return int(end.Sub(start)/time.Minute) - unpaidBreakMinutes
+}
+
+// UpdateByID only updates the shift while it is still at shift.Version, and returns
+// ErrVersionConflict when another write got there first.
+func (r *shiftRepository) UpdateByID(id int64, shift *model.Shift) error {
+	query := `
+		UPDATE shifts 
+		SET date = ?, start_time = ?, end_time = ?, timezone = ?, role_id = ?, location_id = ?, headcount = ?, hourly_rate = ?,
+			version = version + 1, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND version = ? AND deleted_at IS NULL
+	`
+
+	res, err := r.db.Exec(query, shift.Date, shift.StartTime, shift.EndTime, shift.Timezone, shift.RoleID, shift.LocationID, shift.Headcount, shift.HourlyRate, shift.UpdatedBy, id, shift.Version)
+	if err != nil {
+		return err
+	}
+
+	return checkVersionedUpdate(res)
+}
+
+// PublishByDateRange publishes the draft shifts whose local start date falls within the
+// range, optionally only at one location, and returns their ids.
+func (r *shiftRepository) PublishByDateRange(fromDate, toDate time.Time, locationID int64, publishedBy string) ([]int64, error) {
+	shiftIDs := []int64{}
+
+	query := `
+		UPDATE shifts 
+		SET status = ?, published_at = CURRENT_TIMESTAMP, published_by = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP, updated_by = ?
+		WHERE status = ?
+		AND date(date) BETWEEN date(?) AND date(?)
+		AND deleted_at IS NULL
+	`
+	args := []interface{}{
+		constants.SHIFT_STATUS_PUBLISHED, publishedBy, publishedBy, constants.SHIFT_STATUS_DRAFT,
+		fromDate.Format(constants.DATE_FORMAT), toDate.Format(constants.DATE_FORMAT),
+	}
+
+	if locationID != 0 {
+		query += " AND location_id = ?"
+		args = append(args, locationID)
+	}
+
+	query += " RETURNING id"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shiftID int64
+		err := rows.Scan(&shiftID)
+		if err != nil {
+			return nil, err
+		}
+		shiftIDs = append(shiftIDs, shiftID)
+	}
+
+	return shiftIDs, rows.Err()
+}
+
+func (r *shiftRepository) UpdateStatusByID(id int64, status string, updatedBy string) error {
+	query := `
+		UPDATE shifts 
+		SET status = ?, version = version + 1, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	_, err := r.db.Exec(query, status, updatedBy, id)
+	return err
+}
+
+func (r *shiftRepository) CancelByID(id int64, reason string, cancelledBy string) error {
+	query := `
+		UPDATE shifts 
+		SET status = ?, cancelled_at = CURRENT_TIMESTAMP, cancelled_by = ?, cancel_reason = ?, version = version + 1, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	_, err := r.db.Exec(query, constants.SHIFT_STATUS_CANCELLED, cancelledBy, reason, cancelledBy, id)
+	return err
+}
+
+// CancelShiftRequestsByShiftID cancels the requests for a shift in the given status and
+// returns them as they are after the update. cancelledAt is the time the shift itself was
+// cancelled or deleted, so the cascade can be told apart and undone later.
+func (r *shiftRepository) CancelShiftRequestsByShiftID(shiftID int64, status string, cancelledBy string, cancelledAt time.Time) ([]model.ShiftRequest, error) {
+	shiftRequests := []model.ShiftRequest{}
+
+	query := `
+		UPDATE shift_requests 
+		SET status = ?, admin_actor = ?, version = version + 1, updated_by = ?, updated_at = ?
+		WHERE shift_id = ? AND status = ? AND deleted_at IS NULL
+		RETURNING id, user_id, shift_id, status, requested_by, admin_actor, rejection_reason, version, created_at, created_by
+	`
+
+	rows, err := r.db.Query(query, constants.SHIFT_REQUEST_STATUS_CANCELLED, cancelledBy, cancelledBy, cancelledAt.UTC().Format(constants.DATETIME_FORMAT), shiftID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sr model.ShiftRequest
+		err := rows.Scan(
+			&sr.ID, &sr.UserID, &sr.ShiftID, &sr.Status, &sr.RequestedBy, &sr.AdminActor,
+			&sr.RejectionReason, &sr.Version, &sr.CreatedAt, &sr.CreatedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		shiftRequests = append(shiftRequests, sr)
+	}
+
+	return shiftRequests, rows.Err()
+}
+
+// ExpireShiftRequests expires the requests in the given status whose shift started by
+// startedBefore or that were made before createdBefore, and returns them as they are after
+// the update. A nil createdBefore only expires on shift start.
+func (r *shiftRepository) ExpireShiftRequests(status string, startedBefore time.Time, createdBefore *time.Time, expiredBy string) ([]model.ShiftRequest, error) {
+	shiftRequests := []model.ShiftRequest{}
+
+	var created interface{}
+	if createdBefore != nil {
+		created = createdBefore.UTC().Format(constants.DATETIME_FORMAT)
+	}
+
+	query := `
+		UPDATE shift_requests 
+		SET status = ?, admin_actor = ?, version = version + 1, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE status = ? AND deleted_at IS NULL
+		AND (
+			shift_id IN (SELECT id FROM shifts WHERE datetime(start_time) <= datetime(?))
+			OR datetime(created_at) <= datetime(?)
+		)
+		RETURNING id, user_id, shift_id, status, requested_by, admin_actor, rejection_reason, version, created_at, created_by
+	`
+
+	rows, err := r.db.Query(query, constants.SHIFT_REQUEST_STATUS_EXPIRED, expiredBy, expiredBy, status, startedBefore.UTC().Format(constants.DATETIME_FORMAT), created)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sr model.ShiftRequest
+		err := rows.Scan(
+			&sr.ID, &sr.UserID, &sr.ShiftID, &sr.Status, &sr.RequestedBy, &sr.AdminActor,
+			&sr.RejectionReason, &sr.Version, &sr.CreatedAt, &sr.CreatedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		shiftRequests = append(shiftRequests, sr)
+	}
+
+	return shiftRequests, rows.Err()
+}
+
+// EndWorkerShiftsByShiftID ends the live assignments of a shift, so they no longer count
+// toward the worker's daily and weekly limits, and returns the ended assignments. endedAt
+// is stamped the same way as in CancelShiftRequestsByShiftID.
+func (r *shiftRepository) EndWorkerShiftsByShiftID(shiftID int64, endedBy string, endedAt time.Time) ([]model.WorkerShift, error) {
+	query := `
+		UPDATE worker_shift_assignments 
+		SET deleted_at = ?, deleted_by = ?
+		WHERE shift_id = ? AND deleted_at IS NULL
+		RETURNING id, user_id, shift_id, assigned_at, assigned_by, created_at, created_by
+	`
+
+	return r.queryWorkerShifts(query, endedAt.UTC().Format(constants.DATETIME_FORMAT), endedBy, shiftID)
+}
+
+// GetWorkerShiftsEndedAt returns the assignments of a shift that were ended by endedBy at
+// endedAt, which is how a cascade from the shift is recognised.
+func (r *shiftRepository) GetWorkerShiftsEndedAt(shiftID int64, endedBy string, endedAt time.Time) ([]model.WorkerShift, error) {
+	query := `
+		SELECT id, user_id, shift_id, assigned_at, assigned_by, created_at, created_by
+		FROM worker_shift_assignments
+		WHERE shift_id = ? AND deleted_by = ? AND datetime(deleted_at) = datetime(?)
+		ORDER BY id
+	`
+
+	return r.queryWorkerShifts(query, shiftID, endedBy, endedAt.UTC().Format(constants.DATETIME_FORMAT))
+}
+
+// RestoreWorkerShiftByID brings an ended assignment back.
+func (r *shiftRepository) RestoreWorkerShiftByID(id int64, restoredBy string) error {
+	query := `
+		UPDATE worker_shift_assignments 
+		SET deleted_at = NULL, deleted_by = NULL, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND deleted_at IS NOT NULL
+	`
+
+	_, err := r.db.Exec(query, restoredBy, id)
+	return checkAssignmentWrite(err)
+}
+
+// GetShiftRequestsCancelledAt returns the requests for a shift that were cancelled by
+// cancelledBy at cancelledAt, which is how a cascade from the shift is recognised.
+func (r *shiftRepository) GetShiftRequestsCancelledAt(shiftID int64, cancelledBy string, cancelledAt time.Time) ([]model.ShiftRequest, error) {
+	query := `
+		SELECT id, user_id, shift_id, status, requested_by, admin_actor, rejection_reason, version, 
+			created_at, created_by, updated_at, updated_by
+		FROM shift_requests
+		WHERE shift_id = ? AND status = ? AND admin_actor = ? AND datetime(updated_at) = datetime(?) AND deleted_at IS NULL
+		ORDER BY id
+	`
+
+	return r.queryShiftRequests(query, shiftID, constants.SHIFT_REQUEST_STATUS_CANCELLED, cancelledBy, cancelledAt.UTC().Format(constants.DATETIME_FORMAT))
 }
 
-func (r *shiftRepository) Save(shift *model.Shift) error {
+// GetShiftRequestQueue returns the pending and waitlisted requests for a shift, first come
+// first.
+func (r *shiftRepository) GetShiftRequestQueue(shiftID int64) ([]model.ShiftRequest, error) {
 	query := `
-		INSERT INTO shifts (date, start_time, end_time, role_id, location, is_active, created_by, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		SELECT id, user_id, shift_id, status, requested_by, admin_actor, rejection_reason, version, 
+			created_at, created_by, updated_at, updated_by
+		FROM shift_requests
+		WHERE shift_id = ? AND status IN (?, ?) AND deleted_at IS NULL
+		ORDER BY datetime(created_at), id
 	`
 
-	_, err := r.db.Exec(query, shift.Date, shift.StartTime, shift.EndTime, shift.RoleID, shift.Location, shift.IsActive, shift.CreatedBy)
-	return err
+	return r.queryShiftRequests(query, shiftID, constants.SHIFT_REQUEST_STATUS_PENDING, constants.SHIFT_REQUEST_STATUS_WAITLISTED)
 }
 
-func (r *shiftRepository) GetByID(id int64) (*model.Shift, error) {
-	shift := &model.Shift{}
-
+// GetApprovedShiftRequest returns the approved request that put the user on the shift.
+func (r *shiftRepository) GetApprovedShiftRequest(userID, shiftID int64) (*model.ShiftRequest, error) {
 	query := `
-		SELECT id, date, start_time, end_time, role_id, location, created_by, created_at, 
-			updated_by, updated_at, deleted_by, deleted_at
-		FROM shifts 
-		WHERE id = ? AND deleted_at IS NULL
+		SELECT id, user_id, shift_id, status, requested_by, admin_actor, rejection_reason, version, 
+			created_at, created_by, updated_at, updated_by
+		FROM shift_requests
+		WHERE user_id = ? AND shift_id = ? AND status = ? AND deleted_at IS NULL
+		ORDER BY id DESC
 		LIMIT 1
 	`
 
-	err := r.db.QueryRow(query, id).Scan(
-		&shift.ID, &shift.Date, &shift.StartTime, &shift.EndTime, &shift.RoleID, &shift.Location,
-		&shift.CreatedBy, &shift.CreatedAt, &shift.UpdatedBy, &shift.UpdatedAt, &shift.DeletedBy, &shift.DeletedAt,
-	)
+	shiftRequests, err := r.queryShiftRequests(query, userID, shiftID, constants.SHIFT_REQUEST_STATUS_APPROVED)
 	if err != nil {
 		return nil, err
 	}
 
-	return shift, nil
+	if len(shiftRequests) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return &shiftRequests[0], nil
 }
 
-func (r *shiftRepository) GetList(filter GetShiftListFilter) ([]response.GetShiftListData, *httpresp.Pagination, error) {
-	shifts := []response.GetShiftListData{}
+func (r *shiftRepository) queryShiftRequests(query string, args ...interface{}) ([]model.ShiftRequest, error) {
+	shiftRequests := []model.ShiftRequest{}
 
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sr model.ShiftRequest
+		err := rows.Scan(
+			&sr.ID, &sr.UserID, &sr.ShiftID, &sr.Status, &sr.RequestedBy, &sr.AdminActor, &sr.RejectionReason,
+			&sr.Version, &sr.CreatedAt, &sr.CreatedBy, &sr.UpdatedAt, &sr.UpdatedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		shiftRequests = append(shiftRequests, sr)
+	}
+
+	return shiftRequests, rows.Err()
+}
+
+// GetWorkerShiftByID returns a live assignment.
+func (r *shiftRepository) GetWorkerShiftByID(id int64) (*model.WorkerShift, error) {
 	query := `
-		SELECT 
-			shifts.id, 
-			shifts.date, 
-			shifts.start_time, 
-			shifts.end_time, 
-			shifts.role_id, 
-			shift_role_enum.role_name, 
-			shifts.location, 
-			shifts.created_by, 
-			shifts.created_at, 
-			shifts.updated_by, 
-			shifts.updated_at, 
-			shifts.deleted_by, 
-			shifts.deleted_at
-		FROM shifts
-		JOIN shift_role_enum ON shifts.role_id = shift_role_enum.id
-		WHERE shifts.deleted_at IS NULL
+		SELECT id, user_id, shift_id, assigned_at, assigned_by, created_at, created_by
+		FROM worker_shift_assignments
+		WHERE id = ? AND deleted_at IS NULL
 	`
-	var args []interface{}
 
-	if filter.ShowOnlyUnassigned {
-		query += `
-			AND shifts.id NOT IN (
-				SELECT shift_id 
-				FROM worker_shift_assignments 
-				WHERE worker_shift_assignments.deleted_at IS NULL
-			)
-		`
+	workerShifts, err := r.queryWorkerShifts(query, id)
+	if err != nil {
+		return nil, err
 	}
 
-	if filter.Limit <= 0 {
-		filter.Limit = 10
+	if len(workerShifts) == 0 {
+		return nil, sql.ErrNoRows
 	}
 
-	query += " ORDER BY shifts.created_at DESC"
+	return &workerShifts[0], nil
+}
 
-	query += " LIMIT ? OFFSET ?"
+// EndWorkerShiftByID ends a single assignment, freeing its slot.
+func (r *shiftRepository) EndWorkerShiftByID(id int64, endedBy string) error {
+	query := `
+		UPDATE worker_shift_assignments 
+		SET deleted_at = CURRENT_TIMESTAMP, deleted_by = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	_, err := r.db.Exec(query, endedBy, id)
+	return err
+}
 
-	args = append(args, filter.Limit, filter.Offset)
+func (r *shiftRepository) queryWorkerShifts(query string, args ...interface{}) ([]model.WorkerShift, error) {
+	workerShifts := []model.WorkerShift{}
 
 	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var shift response.GetShiftListData
-		err := rows.Scan(
-			&shift.ID, &shift.Date, &shift.StartTime, &shift.EndTime, &shift.RoleID, &shift.RoleName, &shift.Location,
-			&shift.CreatedBy, &shift.CreatedAt, &shift.UpdatedBy, &shift.UpdatedAt, &shift.DeletedBy, &shift.DeletedAt,
-		)
+		var ws model.WorkerShift
+		err := rows.Scan(&ws.ID, &ws.UserID, &ws.ShiftID, &ws.AssignedAt, &ws.AssignedBy, &ws.CreatedAt, &ws.CreatedBy)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
-		shifts = append(shifts, shift)
+		workerShifts = append(workerShifts, ws)
 	}
 
-	countQuery := `
-		SELECT COUNT(*) 
-		FROM shifts
-		JOIN shift_role_enum ON shifts.role_id = shift_role_enum.id
-		WHERE shifts.deleted_at IS NULL
+	return workerShifts, rows.Err()
+}
+
+// RestoreByID takes a shift out of the trash.
+func (r *shiftRepository) RestoreByID(id int64, restoredBy string) error {
+	query := `
+		UPDATE shifts 
+		SET deleted_at = NULL, deleted_by = NULL, version = version + 1, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND deleted_at IS NOT NULL
 	`
 
-	if filter.ShowOnlyUnassigned {
-		countQuery += `
-			AND shifts.id NOT IN (
-				SELECT shift_id 
-				FROM worker_shift_assignments 
-				WHERE worker_shift_assignments.deleted_at IS NULL
-			)
-		`
-	}
+	_, err := r.db.Exec(query, restoredBy, id)
+	return err
+}
 
-	var totalCount int64
-	countRow := r.db.QueryRow(countQuery, args...)
-	err = countRow.Scan(&totalCount)
+func (r *shiftRepository) DeleteByID(id int64, deletedBy string) error {
+	query := `
+		UPDATE shifts 
+		SET deleted_at = CURRENT_TIMESTAMP, deleted_by = ?, version = version + 1 
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	_, err := r.db.Exec(query, deletedBy, id)
+	return err
+}
+
+func (r *shiftRepository) GetBreaksByShiftID(shiftID int64) ([]model.ShiftBreak, error) {
+	breaks := []model.ShiftBreak{}
+
+	query := `
+		SELECT id, shift_id, break_type, start_time, duration_minutes, is_paid, created_at, created_by
+		FROM shift_breaks 
+		WHERE shift_id = ?
+		ORDER BY start_time IS NULL, start_time ASC, id ASC
+	`
+
+	rows, err := r.db.Query(query, shiftID)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shiftBreak model.ShiftBreak
+		err := rows.Scan(&shiftBreak.ID, &shiftBreak.ShiftID, &shiftBreak.BreakType, &shiftBreak.StartTime, &shiftBreak.DurationMinutes, &shiftBreak.IsPaid, &shiftBreak.CreatedAt, &shiftBreak.CreatedBy)
+		if err != nil {
+			return nil, err
+		}
+		breaks = append(breaks, shiftBreak)
 	}
 
-	totalPages := (totalCount + int64(filter.Limit-1)) / int64(filter.Limit)
+	return breaks, rows.Err()
+}
+
+func (r *shiftRepository) SaveBreaks(shiftID int64, breaks []model.ShiftBreak) error {
+	query := `
+		INSERT INTO shift_breaks (shift_id, break_type, start_time, duration_minutes, is_paid, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`
+
+	for i := range breaks {
+		res, err := r.db.Exec(query, shiftID, breaks[i].BreakType, breaks[i].StartTime, breaks[i].DurationMinutes, breaks[i].IsPaid, breaks[i].CreatedBy)
+		if err != nil {
+			return err
+		}
+
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
 
-	pagination := &httpresp.Pagination{
-		CurrentPage:   int64(filter.Offset/filter.Limit + 1),
-		TotalPages:    totalPages,
-		TotalElements: totalCount,
-		SortBy:        "created_at",
+		breaks[i].ID = id
+		breaks[i].ShiftID = shiftID
 	}
 
-	return shifts, pagination, nil
+	return nil
 }
 
-func (r *shiftRepository) UpdateByID(id int64, shift *model.Shift) error {
+// DeleteBreaksByShiftID removes the breaks of a shift so they can be replaced as a whole.
+func (r *shiftRepository) DeleteBreaksByShiftID(shiftID int64) error {
 	query := `
-		UPDATE shifts 
-		SET date = ?, start_time = ?, end_time = ?, role_id = ?, location = ?, 
-			updated_by = ?, updated_at = CURRENT_TIMESTAMP
-		WHERE id = ? AND deleted_at IS NULL
+		DELETE FROM shift_breaks 
+		WHERE shift_id = ?
 	`
 
-	_, err := r.db.Exec(query, shift.Date, shift.StartTime, shift.EndTime, shift.RoleID, shift.Location, shift.UpdatedBy, id)
+	_, err := r.db.Exec(query, shiftID)
 	return err
 }
 
-func (r *shiftRepository) DeleteByID(id int64, deletedBy string) error {
+func (r *shiftRepository) GetRequiredSkillIDs(shiftID int64) ([]int64, error) {
+	skillIDs := []int64{}
+
 	query := `
-		UPDATE shifts 
-		SET deleted_at = CURRENT_TIMESTAMP, deleted_by = ? 
-		WHERE id = ? AND deleted_at IS NULL
+		SELECT skill_id 
+		FROM shift_required_skills 
+		WHERE shift_id = ?
+		ORDER BY skill_id ASC
 	`
 
-	_, err := r.db.Exec(query, deletedBy, id)
+	rows, err := r.db.Query(query, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var skillID int64
+		err := rows.Scan(&skillID)
+		if err != nil {
+			return nil, err
+		}
+		skillIDs = append(skillIDs, skillID)
+	}
+
+	return skillIDs, rows.Err()
+}
+
+func (r *shiftRepository) SaveRequiredSkills(shiftID int64, skillIDs []int64) error {
+	query := `
+		INSERT OR IGNORE INTO shift_required_skills (shift_id, skill_id)
+		VALUES (?, ?)
+	`
+
+	for _, skillID := range skillIDs {
+		_, err := r.db.Exec(query, shiftID, skillID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *shiftRepository) DeleteRequiredSkillsByShiftID(shiftID int64) error {
+	query := `
+		DELETE FROM shift_required_skills 
+		WHERE shift_id = ?
+	`
+
+	_, err := r.db.Exec(query, shiftID)
 	return err
 }
 
 func (r *shiftRepository) GetShiftRequestList(filter GetShiftRequestListFilter) ([]response.GetShiftRequestListData, *httpresp.Pagination, error) {
 	shiftRequests := []response.GetShiftRequestListData{}
+	keys := []ListCursor{}
 
 	query := `
 		SELECT sr.id, sr.user_id, sr.shift_id, s.date AS shift_date, s.start_time AS shift_start_time, 
-			s.end_time AS shift_end_time, s.role_id AS shift_role_id, sre.role_name AS shift_role_name,
-			sr.status, sr.requested_by, sr.admin_actor, sr.rejection_reason, 
-			sr.created_at, sr.created_by, sr.updated_at, sr.updated_by, sr.deleted_at, sr.deleted_by
+			s.end_time AS shift_end_time, s.timezone AS shift_timezone, s.role_id AS shift_role_id, sre.role_name AS shift_role_name,
+			l.id, l.name, l.address, l.latitude, l.longitude, l.timezone,
+			s.headcount AS shift_required_headcount,
+			(
+				SELECT COUNT(*) 
+				FROM worker_shift_assignments wsa 
+				WHERE wsa.shift_id = s.id AND wsa.deleted_at IS NULL
+			) AS shift_filled_count,
+			sr.status, sr.requested_by, sr.admin_actor, sr.rejection_reason, sr.version, 
+			sr.created_at, sr.created_by, sr.updated_at, sr.updated_by, sr.deleted_at, sr.deleted_by,
+			datetime(sr.created_at) AS sort_key
 		FROM shift_requests sr
 		JOIN shifts s ON sr.shift_id = s.id
 		JOIN shift_role_enum sre ON s.role_id = sre.id
-		WHERE sr.deleted_at IS NULL
+		LEFT JOIN locations l ON s.location_id = l.id
 	`
 
-	var args []interface{}
+	conditions := " WHERE sr.deleted_at IS NULL"
+	var conditionArgs []interface{}
 
 	if filter.Status != "" {
-		query += " AND sr.status = ?"
-		args = append(args, filter.Status)
+		conditions += " AND sr.status = ?"
+		conditionArgs = append(conditionArgs, filter.Status)
 	}
 
 	if filter.UserID != 0 {
-		query += " AND sr.user_id = ?"
-		args = append(args, filter.UserID)
+		conditions += " AND sr.user_id = ?"
+		conditionArgs = append(conditionArgs, filter.UserID)
 	}
 
+	query += conditions
+
 	if filter.Limit <= 0 {
 		filter.Limit = 10
 	}
 
-	query += " ORDER BY sr.created_at DESC"
+	cursorCondition, cursorArgs := keysetCondition("datetime(sr.created_at)", "sr.id", "DESC", filter.After)
+	query += cursorCondition
+
+	query += " ORDER BY datetime(sr.created_at) DESC, sr.id DESC"
 
 	query += " LIMIT ? OFFSET ?"
 
-	args = append(args, filter.Limit, filter.Offset)
+	if filter.After != nil {
+		filter.Offset = 0
+	}
+
+	args := append(append(append([]interface{}{}, conditionArgs...), cursorArgs...), filter.Limit+1, filter.Offset)
 
 	rows, err := r.db.Query(query, args...)
 	if err != nil {
@@ -235,44 +1148,51 @@ func (r *shiftRepository) GetShiftRequestList(filter GetShiftRequestListFilter)
 
 	for rows.Next() {
 		var shiftRequest response.GetShiftRequestListData
-
-		err := rows.Scan(
+		var location locationColumns
+		var key ListCursor
+		dest := []interface{}{
 			&shiftRequest.ID, &shiftRequest.UserID, &shiftRequest.ShiftID, &shiftRequest.ShiftDate,
-			&shiftRequest.ShiftStartTime, &shiftRequest.ShiftEndTime, &shiftRequest.ShiftRoleID,
-			&shiftRequest.ShiftRoleName, &shiftRequest.Status, &shiftRequest.RequestedBy,
-			&shiftRequest.AdminActor, &shiftRequest.RejectionReason, &shiftRequest.CreatedAt,
+			&shiftRequest.ShiftStartTime, &shiftRequest.ShiftEndTime, &shiftRequest.ShiftTimezone, &shiftRequest.ShiftRoleID,
+			&shiftRequest.ShiftRoleName,
+		}
+		dest = append(dest, location.scanDest()...)
+		dest = append(dest,
+			&shiftRequest.ShiftRequiredHeadcount, &shiftRequest.ShiftFilledCount, &shiftRequest.Status, &shiftRequest.RequestedBy,
+			&shiftRequest.AdminActor, &shiftRequest.RejectionReason, &shiftRequest.Version, &shiftRequest.CreatedAt,
 			&shiftRequest.CreatedBy, &shiftRequest.UpdatedAt, &shiftRequest.UpdatedBy,
-			&shiftRequest.DeletedAt, &shiftRequest.DeletedBy,
+			&shiftRequest.DeletedAt, &shiftRequest.DeletedBy, &key.SortValue,
 		)
+
+		err := rows.Scan(dest...)
 		if err != nil {
 			return nil, nil, err
 		}
+		shiftRequest.ShiftLocation = location.toResponse()
+		key.ID = shiftRequest.ID
 
 		shiftRequests = append(shiftRequests, shiftRequest)
+		keys = append(keys, key)
+	}
+
+	hasMore := len(shiftRequests) > filter.Limit
+	if hasMore {
+		shiftRequests, keys = shiftRequests[:filter.Limit], keys[:filter.Limit]
 	}
 
 	countQuery := `
 		SELECT COUNT(*) FROM shift_requests sr
 		JOIN shifts s ON sr.shift_id = s.id
 		JOIN shift_role_enum sre ON s.role_id = sre.id
-		WHERE sr.deleted_at IS NULL
-	`
+	` + conditions
 
 	var totalCount int64
-	countRows := r.db.QueryRow(countQuery, args...)
+	countRows := r.db.QueryRow(countQuery, conditionArgs...)
 	err = countRows.Scan(&totalCount)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	totalPages := (totalCount + int64(filter.Limit-1)) / int64(filter.Limit)
-
-	pagination := &httpresp.Pagination{
-		CurrentPage:   int64(filter.Offset/filter.Limit + 1),
-		TotalPages:    totalPages,
-		TotalElements: totalCount,
-		SortBy:        "created_at",
-	}
+	pagination := newListPagination(filter.Limit, filter.Offset, filter.After, totalCount, keys, hasMore, "created_at")
 
 	return shiftRequests, pagination, nil
 }
@@ -282,7 +1202,7 @@ func (r *shiftRepository) GetShiftRequestByID(id int64) (*model.ShiftRequest, er
 
 	// Raw SQL query to fetch the shift request by ID
 	query := `
-		SELECT id, user_id, shift_id, status, requested_by, admin_actor, rejection_reason, 
+		SELECT id, user_id, shift_id, status, requested_by, admin_actor, rejection_reason, version, 
 			created_at, created_by, updated_at, updated_by, deleted_at, deleted_by
 		FROM shift_requests 
 		WHERE id = ? AND deleted_at IS NULL
@@ -291,7 +1211,7 @@ func (r *shiftRepository) GetShiftRequestByID(id int64) (*model.ShiftRequest, er
 
 	err := r.db.QueryRow(query, id).Scan(
 		&sr.ID, &sr.UserID, &sr.ShiftID, &sr.Status,
-		&sr.RequestedBy, &sr.AdminActor, &sr.RejectionReason,
+		&sr.RequestedBy, &sr.AdminActor, &sr.RejectionReason, &sr.Version,
 		&sr.CreatedAt, &sr.CreatedBy, &sr.UpdatedAt,
 		&sr.UpdatedBy, &sr.DeletedAt, &sr.DeletedBy,
 	)
@@ -308,15 +1228,17 @@ func (r *shiftRepository) SaveShiftRequest(shiftRequest *model.ShiftRequest) err
 			user_id, shift_id, status, requested_by, admin_actor, rejection_reason, created_at, created_by, 
 			updated_at, updated_by, deleted_at, deleted_by
 		) 
-		VALUES (?, ?, ?, ?, NULL, NULL, CURRENT_TIMESTAMP, ?, NULL, NULL, NULL, NULL)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, ?, NULL, NULL, NULL, NULL)
 	`
 
-	_, err := r.db.Exec(query, shiftRequest.UserID, shiftRequest.ShiftID, shiftRequest.Status, shiftRequest.RequestedBy, shiftRequest.CreatedBy)
+	res, err := r.db.Exec(query, shiftRequest.UserID, shiftRequest.ShiftID, shiftRequest.Status, shiftRequest.RequestedBy, shiftRequest.AdminActor, shiftRequest.RejectionReason, shiftRequest.CreatedBy)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	shiftRequest.ID, err = res.LastInsertId()
+
+	return err
 }
 
 func (r *shiftRepository) SaveWorkerShift(workerShift *model.WorkerShift) error {
@@ -326,39 +1248,47 @@ func (r *shiftRepository) SaveWorkerShift(workerShift *model.WorkerShift) error
 			updated_at, updated_by, deleted_at, deleted_by
 		) 
 		VALUES (?, ?, CURRENT_TIMESTAMP, ?, CURRENT_TIMESTAMP, ?, NULL, NULL, NULL, NULL)
+		RETURNING id, assigned_at, created_at
 	`
 
-	_, err := r.db.Exec(query, workerShift.UserID, workerShift.ShiftID, workerShift.AssignedBy, workerShift.CreatedBy)
-	if err != nil {
-		return err
-	}
+	err := r.db.QueryRow(query, workerShift.UserID, workerShift.ShiftID, workerShift.AssignedBy, workerShift.CreatedBy).
+		Scan(&workerShift.ID, &workerShift.AssignedAt, &workerShift.CreatedAt)
 
-	return nil
+	return checkAssignmentWrite(err)
 }
 
+// UpdateShiftRequestByID only updates the request while it is still at sr.Version, and
+// returns ErrVersionConflict when another write got there first.
 func (r *shiftRepository) UpdateShiftRequestByID(id int64, sr *model.ShiftRequest) error {
 	query := `
 		UPDATE shift_requests 
 		SET status = ?, admin_actor = ?, rejection_reason = ?,
-			updated_by = ?, updated_at = CURRENT_TIMESTAMP
-		WHERE id = ? AND deleted_at IS NULL
+			version = version + 1, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND version = ? AND deleted_at IS NULL
 	`
 
-	_, err := r.db.Exec(query, sr.Status, sr.AdminActor, sr.RejectionReason, sr.UpdatedBy, id)
-	return err
+	res, err := r.db.Exec(query, sr.Status, sr.AdminActor, sr.RejectionReason, sr.UpdatedBy, id, sr.Version)
+	if err != nil {
+		return err
+	}
+
+	return checkVersionedUpdate(res)
 }
 
+// CheckUserAssignedShiftExistsByDate reports whether the user holds a shift on the given day.
+// A shift's day is the local date it starts on, so an overnight shift counts toward its start day.
 func (r *shiftRepository) CheckUserAssignedShiftExistsByDate(userID int64, shiftDate time.Time) (bool, error) {
 	query := `
 		SELECT COUNT(*) 
 		FROM worker_shift_assignments wsa
 		JOIN shifts s ON wsa.shift_id = s.id
 		WHERE wsa.user_id = ? 
-		AND s.date = ? 
+		AND date(s.date) = date(?) 
 		AND wsa.deleted_at IS NULL
+		AND s.deleted_at IS NULL
 	`
 	var assignedShiftCount int
-	err := r.db.QueryRow(query, userID, shiftDate).Scan(&assignedShiftCount)
+	err := r.db.QueryRow(query, userID, shiftDate.Format(constants.DATE_FORMAT)).Scan(&assignedShiftCount)
 	if err != nil {
 		return false, err
 	}
@@ -370,39 +1300,50 @@ func (r *shiftRepository) CheckUserAssignedShiftExistsByDate(userID int64, shift
 	return false, nil
 }
 
+// CheckIfShiftIsAlreadyAssigned reports whether at least one worker currently holds a slot on the shift.
 func (r *shiftRepository) CheckIfShiftIsAlreadyAssigned(shiftID int64) (bool, error) {
+	filledSlotCount, err := r.GetShiftFilledSlotCount(shiftID)
+	if err != nil {
+		return false, err
+	}
+
+	return filledSlotCount > 0, nil
+}
+
+// GetShiftFilledSlotCount returns how many of the shift's headcount slots are taken by live assignments.
+func (r *shiftRepository) GetShiftFilledSlotCount(shiftID int64) (int, error) {
 	query := `
 		SELECT COUNT(*) 
 		FROM shifts s
 		JOIN worker_shift_assignments wsa ON wsa.shift_id = s.id
 		WHERE s.id = ?
 		AND   s.deleted_at IS NULL
+		AND   wsa.deleted_at IS NULL
 	`
 
-	var assignedShiftCount int
-	err := r.db.QueryRow(query, shiftID).Scan(&assignedShiftCount)
+	var filledSlotCount int
+	err := r.db.QueryRow(query, shiftID).Scan(&filledSlotCount)
 	if err != nil {
-		return false, err
-	}
-
-	if assignedShiftCount > 0 {
-		return true, nil
+		return 0, err
 	}
 
-	return false, nil
+	return filledSlotCount, nil
 }
 
-func (r *shiftRepository) CheckIfShiftRequestTimeOverlaps(userID int64, shiftDate, requestedStartTime, requestedEndTime time.Time) (bool, error) {
+// CheckIfShiftRequestTimeOverlaps reports whether the user has a pending or approved request
+// for a shift whose instants overlap the given window. Shifts that only touch are allowed.
+func (r *shiftRepository) CheckIfShiftRequestTimeOverlaps(userID int64, requestedStartTime, requestedEndTime time.Time) (bool, error) {
 	checkOverlapQuery := `
 		SELECT COUNT(*) 
 		FROM shift_requests sr
 		JOIN shifts s ON sr.shift_id = s.id
 		WHERE sr.user_id = ? 
-		AND sr.status IN (?) 
-		AND s.date = ?
+		AND sr.status IN (?, ?) 
 		AND sr.deleted_at IS NULL
-		AND s.starttime <= ?
-    	AND s.endtime >= ?
+		AND s.deleted_at IS NULL
+		AND s.status != ?
+		AND datetime(s.start_time) < datetime(?)
+		AND datetime(s.end_time) > datetime(?)
 	`
 
 	var overlapCount int
@@ -410,9 +1351,10 @@ func (r *shiftRepository) CheckIfShiftRequestTimeOverlaps(userID int64, shiftDat
 		checkOverlapQuery,
 		userID,
 		constants.SHIFT_REQUEST_STATUS_PENDING,
-		shiftDate,
-		requestedEndTime,
-		requestedStartTime).Scan(&overlapCount)
+		constants.SHIFT_REQUEST_STATUS_APPROVED,
+		constants.SHIFT_STATUS_CANCELLED,
+		requestedEndTime.UTC().Format(constants.DATETIME_FORMAT),
+		requestedStartTime.UTC().Format(constants.DATETIME_FORMAT)).Scan(&overlapCount)
 	if err != nil {
 		return false, err
 	}
@@ -424,19 +1366,53 @@ func (r *shiftRepository) CheckIfShiftRequestTimeOverlaps(userID int64, shiftDat
 	return false, nil
 }
 
+// CheckIfAssignedShiftTimeOverlaps reports whether the worker holds a live assignment to a
+// shift, not cancelled, whose time overlaps the given window.
+func (r *shiftRepository) CheckIfAssignedShiftTimeOverlaps(userID int64, startTime, endTime time.Time) (bool, error) {
+	query := `
+		SELECT COUNT(*) 
+		FROM worker_shift_assignments wsa
+		JOIN shifts s ON wsa.shift_id = s.id
+		WHERE wsa.user_id = ? 
+		AND wsa.deleted_at IS NULL
+		AND s.deleted_at IS NULL
+		AND s.status != ?
+		AND datetime(s.start_time) < datetime(?)
+		AND datetime(s.end_time) > datetime(?)
+	`
+
+	var overlapCount int
+	err := r.db.QueryRow(
+		query,
+		userID,
+		constants.SHIFT_STATUS_CANCELLED,
+		endTime.UTC().Format(constants.DATETIME_FORMAT),
+		startTime.UTC().Format(constants.DATETIME_FORMAT)).Scan(&overlapCount)
+	if err != nil {
+		return false, err
+	}
+
+	return overlapCount > 0, nil
+}
+
+// GetUserWeeklyAssignedShiftCountByDate counts the user's live assignments in the Monday to
+// Sunday week containing shiftDate, with each shift counted in the week of its local start day.
 func (r *shiftRepository) GetUserWeeklyAssignedShiftCountByDate(userID int64, shiftDate time.Time) (int, error) {
+	weekStart := shiftDate.AddDate(0, 0, -((int(shiftDate.Weekday()) + 6) % 7))
+	weekEnd := weekStart.AddDate(0, 0, 6)
+
 	checkWeeklyShiftsQuery := `
 		SELECT COUNT(*) 
-		FROM shift_requests sr
-		JOIN shifts s ON sr.shift_id = s.id
-		WHERE sr.user_id = ? 
-		AND sr.status IN (?) 
-		AND strftime('%W', s.date) = strftime('%W', ?) 
-		AND sr.deleted_at IS NULL
+		FROM worker_shift_assignments wsa
+		JOIN shifts s ON wsa.shift_id = s.id
+		WHERE wsa.user_id = ? 
+		AND date(s.date) BETWEEN date(?) AND date(?)
+		AND wsa.deleted_at IS NULL
+		AND s.deleted_at IS NULL
 	`
 
 	var weeklyShiftCount int
-	err := r.db.QueryRow(checkWeeklyShiftsQuery, userID, constants.SHIFT_REQUEST_STATUS_APPROVED, shiftDate).Scan(&weeklyShiftCount)
+	err := r.db.QueryRow(checkWeeklyShiftsQuery, userID, weekStart.Format(constants.DATE_FORMAT), weekEnd.Format(constants.DATE_FORMAT)).Scan(&weeklyShiftCount)
 	if err != nil {
 		return 0, err
 	}
@@ -444,8 +1420,30 @@ func (r *shiftRepository) GetUserWeeklyAssignedShiftCountByDate(userID int64, sh
 	return weeklyShiftCount, nil
 }
 
+// GetUserCompletedShiftCount counts the completed shifts the worker was still assigned to.
+func (r *shiftRepository) GetUserCompletedShiftCount(userID int64) (int, error) {
+	query := `
+		SELECT COUNT(*) 
+		FROM worker_shift_assignments wsa
+		JOIN shifts s ON wsa.shift_id = s.id
+		WHERE wsa.user_id = ? 
+		AND s.status = ?
+		AND wsa.deleted_at IS NULL
+		AND s.deleted_at IS NULL
+	`
+
+	var completedShiftCount int
+	err := r.db.QueryRow(query, userID, constants.SHIFT_STATUS_COMPLETED).Scan(&completedShiftCount)
+	if err != nil {
+		return 0, err
+	}
+
+	return completedShiftCount, nil
+}
+
 func (r *shiftRepository) GetShiftAssignmentList(filter GetShiftAssignmentListFilter) ([]response.GetShiftAssignmentListData, *httpresp.Pagination, error) {
 	assignments := []response.GetShiftAssignmentListData{}
+	keys := []ListCursor{}
 
 	query := `
 		SELECT 
@@ -458,7 +1456,20 @@ func (r *shiftRepository) GetShiftAssignmentList(filter GetShiftAssignmentListFi
 			shifts.date, 
 			shifts.start_time, 
 			shifts.end_time, 
+			shifts.timezone, 
 			shift_role_enum.role_name, 
+			locations.id, 
+			locations.name, 
+			locations.address, 
+			locations.latitude, 
+			locations.longitude, 
+			locations.timezone, 
+			(
+				SELECT COALESCE(SUM(shift_breaks.duration_minutes), 0) 
+				FROM shift_breaks 
+				WHERE shift_breaks.shift_id = shifts.id 
+				AND shift_breaks.is_paid = 0
+			) AS unpaid_break_minutes,
 			worker_shift_assignments.assigned_at, 
 			worker_shift_assignments.assigned_by, 
 			worker_shift_assignments.created_at, 
@@ -466,30 +1477,41 @@ func (r *shiftRepository) GetShiftAssignmentList(filter GetShiftAssignmentListFi
 			worker_shift_assignments.updated_at, 
 			worker_shift_assignments.updated_by, 
 			worker_shift_assignments.deleted_at, 
-			worker_shift_assignments.deleted_by
+			worker_shift_assignments.deleted_by, 
+			datetime(worker_shift_assignments.created_at) AS sort_key
 		FROM worker_shift_assignments
 		JOIN users ON worker_shift_assignments.user_id = users.id
 		JOIN shifts ON worker_shift_assignments.shift_id = shifts.id
 		JOIN shift_role_enum ON shifts.role_id = shift_role_enum.id
-		WHERE worker_shift_assignments.deleted_at IS NULL
+		LEFT JOIN locations ON shifts.location_id = locations.id
 	`
 
-	var args []interface{}
+	conditions := " WHERE worker_shift_assignments.deleted_at IS NULL"
+	var conditionArgs []interface{}
 
 	if filter.UserID > 0 {
-		query += ` AND worker_shift_assignments.user_id = ?`
-		args = append(args, filter.UserID)
+		conditions += ` AND worker_shift_assignments.user_id = ?`
+		conditionArgs = append(conditionArgs, filter.UserID)
 	}
 
+	query += conditions
+
 	if filter.Limit <= 0 {
 		filter.Limit = 10
 	}
 
-	query += " ORDER BY worker_shift_assignments.created_at DESC"
+	cursorCondition, cursorArgs := keysetCondition("datetime(worker_shift_assignments.created_at)", "worker_shift_assignments.id", "DESC", filter.After)
+	query += cursorCondition
+
+	query += " ORDER BY datetime(worker_shift_assignments.created_at) DESC, worker_shift_assignments.id DESC"
 
 	query += " LIMIT ? OFFSET ?"
 
-	args = append(args, filter.Limit, filter.Offset)
+	if filter.After != nil {
+		filter.Offset = 0
+	}
+
+	args := append(append(append([]interface{}{}, conditionArgs...), cursorArgs...), filter.Limit+1, filter.Offset)
 
 	rows, err := r.db.Query(query, args...)
 	if err != nil {
@@ -499,17 +1521,34 @@ func (r *shiftRepository) GetShiftAssignmentList(filter GetShiftAssignmentListFi
 
 	for rows.Next() {
 		var assignment response.GetShiftAssignmentListData
-		err := rows.Scan(
+		var location locationColumns
+		var key ListCursor
+		dest := []interface{}{
 			&assignment.ID, &assignment.UserID, &assignment.FirstName, &assignment.LastName, &assignment.Email,
 			&assignment.ShiftID, &assignment.ShiftDate, &assignment.ShiftStartTime, &assignment.ShiftEndTime,
-			&assignment.ShiftRoleName, &assignment.AssignedAt, &assignment.AssignedBy,
+			&assignment.ShiftTimezone, &assignment.ShiftRoleName,
+		}
+		dest = append(dest, location.scanDest()...)
+		dest = append(dest,
+			&assignment.UnpaidBreakMinutes, &assignment.AssignedAt, &assignment.AssignedBy,
 			&assignment.CreatedAt, &assignment.CreatedBy, &assignment.UpdatedAt, &assignment.UpdatedBy,
-			&assignment.DeletedAt, &assignment.DeletedBy,
+			&assignment.DeletedAt, &assignment.DeletedBy, &key.SortValue,
 		)
+
+		err := rows.Scan(dest...)
 		if err != nil {
 			return nil, nil, err
 		}
+		assignment.ShiftLocation = location.toResponse()
+		assignment.NetWorkingMinutes = netWorkingMinutes(assignment.ShiftStartTime, assignment.ShiftEndTime, assignment.UnpaidBreakMinutes)
+		key.ID = assignment.ID
 		assignments = append(assignments, assignment)
+		keys = append(keys, key)
+	}
+
+	hasMore := len(assignments) > filter.Limit
+	if hasMore {
+		assignments, keys = assignments[:filter.Limit], keys[:filter.Limit]
 	}
 
 	countQuery := `
@@ -518,29 +1557,16 @@ func (r *shiftRepository) GetShiftAssignmentList(filter GetShiftAssignmentListFi
 		JOIN users ON worker_shift_assignments.user_id = users.id
 		JOIN shifts ON worker_shift_assignments.shift_id = shifts.id
 		JOIN shift_role_enum ON shifts.role_id = shift_role_enum.id
-		WHERE worker_shift_assignments.deleted_at IS NULL
-	`
-
-	if filter.UserID > 0 {
-		countQuery += ` AND worker_shift_assignments.user_id = ?`
-		args = append(args, filter.UserID)
-	}
+	` + conditions
 
 	var totalCount int64
-	countRows := r.db.QueryRow(countQuery, args...)
+	countRows := r.db.QueryRow(countQuery, conditionArgs...)
 	err = countRows.Scan(&totalCount)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	totalPages := (totalCount + int64(filter.Limit-1)) / int64(filter.Limit)
-
-	pagination := &httpresp.Pagination{
-		CurrentPage:   int64(filter.Offset/filter.Limit + 1),
-		TotalPages:    totalPages,
-		TotalElements: totalCount,
-		SortBy:        "created_at",
-	}
+	pagination := newListPagination(filter.Limit, filter.Offset, filter.After, totalCount, keys, hasMore, "created_at")
 
 	return assignments, pagination, nil
 }
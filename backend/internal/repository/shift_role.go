@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"github.com/andibalo/payd-test/backend/internal/model"
+)
+
+type shiftRoleRepository struct {
+	db DBTX
+}
+
+func NewShiftRoleRepository(db DBTX) ShiftRoleRepository {
+	return &shiftRoleRepository{
+		db: db,
+	}
+}
+
+type GetShiftRoleListFilter struct {
+	IncludeDeleted bool `json:"include_deleted"`
+}
+
+func (r *shiftRoleRepository) Save(role *model.ShiftRoleEnum) error {
+	query := `
+		INSERT INTO shift_role_enum (role_name, hourly_rate, created_by, created_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+	`
+
+	res, err := r.db.Exec(query, role.RoleName, role.HourlyRate, role.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	role.ID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetByID returns a role including soft-deleted ones, so callers can tell
+// a deleted role apart from one that never existed.
+func (r *shiftRoleRepository) GetByID(id int64) (*model.ShiftRoleEnum, error) {
+	role := &model.ShiftRoleEnum{}
+
+	query := `
+		SELECT id, role_name, hourly_rate, created_at, created_by, updated_at, updated_by, deleted_at, deleted_by
+		FROM shift_role_enum
+		WHERE id = ?
+		LIMIT 1
+	`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&role.ID, &role.RoleName, &role.HourlyRate, &role.CreatedAt, &role.CreatedBy,
+		&role.UpdatedAt, &role.UpdatedBy, &role.DeletedAt, &role.DeletedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// GetByName looks up a role case-insensitively, including soft-deleted ones.
+func (r *shiftRoleRepository) GetByName(roleName string) (*model.ShiftRoleEnum, error) {
+	role := &model.ShiftRoleEnum{}
+
+	query := `
+		SELECT id, role_name, hourly_rate, created_at, created_by, updated_at, updated_by, deleted_at, deleted_by
+		FROM shift_role_enum
+		WHERE role_name = ? COLLATE NOCASE
+		LIMIT 1
+	`
+
+	err := r.db.QueryRow(query, roleName).Scan(
+		&role.ID, &role.RoleName, &role.HourlyRate, &role.CreatedAt, &role.CreatedBy,
+		&role.UpdatedAt, &role.UpdatedBy, &role.DeletedAt, &role.DeletedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (r *shiftRoleRepository) GetList(filter GetShiftRoleListFilter) ([]model.ShiftRoleEnum, error) {
+	roles := []model.ShiftRoleEnum{}
+
+	query := `
+		SELECT id, role_name, hourly_rate, created_at, created_by, updated_at, updated_by, deleted_at, deleted_by
+		FROM shift_role_enum
+	`
+
+	if !filter.IncludeDeleted {
+		query += " WHERE deleted_at IS NULL"
+	}
+
+	query += " ORDER BY role_name ASC"
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var role model.ShiftRoleEnum
+		err := rows.Scan(
+			&role.ID, &role.RoleName, &role.HourlyRate, &role.CreatedAt, &role.CreatedBy,
+			&role.UpdatedAt, &role.UpdatedBy, &role.DeletedAt, &role.DeletedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, nil
+}
+
+func (r *shiftRoleRepository) UpdateByID(id int64, role *model.ShiftRoleEnum) error {
+	query := `
+		UPDATE shift_role_enum 
+		SET role_name = ?, hourly_rate = ?, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	_, err := r.db.Exec(query, role.RoleName, role.HourlyRate, role.UpdatedBy, id)
+	return err
+}
+
+func (r *shiftRoleRepository) DeleteByID(id int64, deletedBy string) error {
+	query := `
+		UPDATE shift_role_enum 
+		SET deleted_at = CURRENT_TIMESTAMP, deleted_by = ? 
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	_, err := r.db.Exec(query, deletedBy, id)
+	return err
+}
+
+func (r *shiftRoleRepository) RestoreByID(id int64, restoredBy string) error {
+	query := `
+		UPDATE shift_role_enum 
+		SET deleted_at = NULL, deleted_by = NULL, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND deleted_at IS NOT NULL
+	`
+
+	_, err := r.db.Exec(query, restoredBy, id)
+	return err
+}
+
+// CountActiveShiftsByRoleID counts the active, non-deleted shifts that still use the role.
+func (r *shiftRoleRepository) CountActiveShiftsByRoleID(id int64) (int, error) {
+	query := `
+		SELECT COUNT(*) 
+		FROM shifts 
+		WHERE role_id = ? 
+		AND is_active = TRUE
+		AND deleted_at IS NULL
+	`
+
+	var shiftCount int
+	err := r.db.QueryRow(query, id).Scan(&shiftCount)
+	if err != nil {
+		return 0, err
+	}
+
+	return shiftCount, nil
+}
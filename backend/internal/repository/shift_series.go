@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+)
+
+type shiftSeriesRepository struct {
+	db DBTX
+}
+
+func NewShiftSeriesRepository(db DBTX) ShiftSeriesRepository {
+	return &shiftSeriesRepository{
+		db: db,
+	}
+}
+
+type GetShiftSeriesListFilter struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+func (r *shiftSeriesRepository) WithTx(tx *sql.Tx) ShiftSeriesRepository {
+	return &shiftSeriesRepository{
+		db: tx,
+	}
+}
+
+func (r *shiftSeriesRepository) Save(series *model.ShiftSeries) error {
+	query := `
+		INSERT INTO shift_series (
+			rrule, start_date, start_time, end_time, role_id, location_id, timezone, headcount, exception_dates, is_active, created_by, created_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`
+
+	res, err := r.db.Exec(query, series.RRule, series.StartDate, series.StartTime, series.EndTime, series.RoleID,
+		series.LocationID, series.Timezone, series.Headcount, series.ExceptionDates, series.IsActive, series.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	series.ID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *shiftSeriesRepository) GetByID(id int64) (*model.ShiftSeries, error) {
+	series := &model.ShiftSeries{}
+
+	query := `
+		SELECT id, rrule, start_date, start_time, end_time, role_id, location_id, timezone, headcount, exception_dates, is_active,
+			created_at, created_by, updated_at, updated_by, deleted_at, deleted_by
+		FROM shift_series
+		WHERE id = ? AND deleted_at IS NULL
+		LIMIT 1
+	`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&series.ID, &series.RRule, &series.StartDate, &series.StartTime, &series.EndTime, &series.RoleID,
+		&series.LocationID, &series.Timezone, &series.Headcount, &series.ExceptionDates, &series.IsActive,
+		&series.CreatedAt, &series.CreatedBy, &series.UpdatedAt, &series.UpdatedBy, &series.DeletedAt, &series.DeletedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+func (r *shiftSeriesRepository) GetList(filter GetShiftSeriesListFilter) ([]model.ShiftSeries, *httpresp.Pagination, error) {
+	seriesList := []model.ShiftSeries{}
+
+	if filter.Limit <= 0 {
+		filter.Limit = 10
+	}
+
+	query := `
+		SELECT id, rrule, start_date, start_time, end_time, role_id, location_id, timezone, headcount, exception_dates, is_active,
+			created_at, created_by, updated_at, updated_by, deleted_at, deleted_by
+		FROM shift_series
+		WHERE deleted_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.Query(query, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var series model.ShiftSeries
+		err := rows.Scan(
+			&series.ID, &series.RRule, &series.StartDate, &series.StartTime, &series.EndTime, &series.RoleID,
+			&series.LocationID, &series.Timezone, &series.Headcount, &series.ExceptionDates, &series.IsActive,
+			&series.CreatedAt, &series.CreatedBy, &series.UpdatedAt, &series.UpdatedBy, &series.DeletedAt, &series.DeletedBy,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		seriesList = append(seriesList, series)
+	}
+
+	countQuery := `
+		SELECT COUNT(*) 
+		FROM shift_series
+		WHERE deleted_at IS NULL
+	`
+
+	var totalCount int64
+	err = r.db.QueryRow(countQuery).Scan(&totalCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	totalPages := (totalCount + int64(filter.Limit-1)) / int64(filter.Limit)
+
+	pagination := &httpresp.Pagination{
+		CurrentPage:   int64(filter.Offset/filter.Limit + 1),
+		TotalPages:    totalPages,
+		TotalElements: totalCount,
+		SortBy:        "created_at",
+	}
+
+	return seriesList, pagination, nil
+}
+
+func (r *shiftSeriesRepository) UpdateByID(id int64, series *model.ShiftSeries) error {
+	query := `
+		UPDATE shift_series 
+		SET rrule = ?, start_date = ?, start_time = ?, end_time = ?, role_id = ?, location_id = ?, timezone = ?, headcount = ?,
+			exception_dates = ?, is_active = ?, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	_, err := r.db.Exec(query, series.RRule, series.StartDate, series.StartTime, series.EndTime, series.RoleID,
+		series.LocationID, series.Timezone, series.Headcount, series.ExceptionDates, series.IsActive, series.UpdatedBy, id)
+	return err
+}
+
+func (r *shiftSeriesRepository) DeleteByID(id int64, deletedBy string) error {
+	query := `
+		UPDATE shift_series 
+		SET is_active = FALSE, deleted_at = CURRENT_TIMESTAMP, deleted_by = ? 
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	_, err := r.db.Exec(query, deletedBy, id)
+	return err
+}
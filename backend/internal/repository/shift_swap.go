@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/andibalo/payd-test/backend/internal/constants"
+	"github.com/andibalo/payd-test/backend/internal/model"
+)
+
+type shiftSwapRepository struct {
+	db DBTX
+}
+
+func NewShiftSwapRepository(db DBTX) ShiftSwapRepository {
+	return &shiftSwapRepository{
+		db: db,
+	}
+}
+
+func (r *shiftSwapRepository) WithTx(tx *sql.Tx) ShiftSwapRepository {
+	return &shiftSwapRepository{
+		db: tx,
+	}
+}
+
+// GetShiftSwapListFilter narrows the list to swaps UserID proposed or received, and to one
+// Status, when they are set.
+type GetShiftSwapListFilter struct {
+	UserID int64  `json:"user_id"`
+	Status string `json:"status"`
+}
+
+// shiftSwapScanDest receives the columns of a swap in the order the queries below select
+// them.
+func shiftSwapScanDest(swap *model.ShiftSwap) []interface{} {
+	return []interface{}{
+		&swap.ID, &swap.ProposerID, &swap.ProposerAssignmentID, &swap.ProposerShiftID,
+		&swap.RecipientID, &swap.RecipientAssignmentID, &swap.RecipientShiftID,
+		&swap.Status, &swap.Note, &swap.AdminActor, &swap.RejectionReason, &swap.Version,
+		&swap.CreatedAt, &swap.CreatedBy, &swap.UpdatedAt, &swap.UpdatedBy,
+	}
+}
+
+func (r *shiftSwapRepository) Save(swap *model.ShiftSwap) error {
+	query := `
+		INSERT INTO shift_swaps (proposer_id, proposer_assignment_id, proposer_shift_id, recipient_id, recipient_assignment_id, recipient_shift_id, 
+			status, note, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`
+
+	res, err := r.db.Exec(query, swap.ProposerID, swap.ProposerAssignmentID, swap.ProposerShiftID, swap.RecipientID, swap.RecipientAssignmentID, swap.RecipientShiftID,
+		swap.Status, swap.Note, swap.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	swap.ID, err = res.LastInsertId()
+
+	return err
+}
+
+func (r *shiftSwapRepository) GetByID(id int64) (*model.ShiftSwap, error) {
+	swap := &model.ShiftSwap{}
+
+	query := `
+		SELECT id, proposer_id, proposer_assignment_id, proposer_shift_id, recipient_id, recipient_assignment_id, recipient_shift_id, 
+			status, note, admin_actor, rejection_reason, version, created_at, created_by, updated_at, updated_by
+		FROM shift_swaps
+		WHERE id = ?
+		LIMIT 1
+	`
+
+	err := r.db.QueryRow(query, id).Scan(shiftSwapScanDest(swap)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return swap, nil
+}
+
+// GetList returns the newest swaps first.
+func (r *shiftSwapRepository) GetList(filter GetShiftSwapListFilter) ([]model.ShiftSwap, error) {
+	swaps := []model.ShiftSwap{}
+
+	query := `
+		SELECT id, proposer_id, proposer_assignment_id, proposer_shift_id, recipient_id, recipient_assignment_id, recipient_shift_id, 
+			status, note, admin_actor, rejection_reason, version, created_at, created_by, updated_at, updated_by
+		FROM shift_swaps
+		WHERE 1 = 1
+	`
+
+	var args []interface{}
+
+	if filter.UserID != 0 {
+		query += " AND (proposer_id = ? OR recipient_id = ?)"
+		args = append(args, filter.UserID, filter.UserID)
+	}
+
+	if filter.Status != "" {
+		query += " AND status = ?"
+		args = append(args, filter.Status)
+	}
+
+	query += " ORDER BY id DESC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var swap model.ShiftSwap
+		err := rows.Scan(shiftSwapScanDest(&swap)...)
+		if err != nil {
+			return nil, err
+		}
+		swaps = append(swaps, swap)
+	}
+
+	return swaps, rows.Err()
+}
+
+// UpdateByID moves the swap to a new status. It returns ErrVersionConflict when the swap
+// changed since it was read.
+func (r *shiftSwapRepository) UpdateByID(id int64, swap *model.ShiftSwap) error {
+	query := `
+		UPDATE shift_swaps 
+		SET status = ?, admin_actor = ?, rejection_reason = ?,
+			version = version + 1, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND version = ?
+	`
+
+	res, err := r.db.Exec(query, swap.Status, swap.AdminActor, swap.RejectionReason, swap.UpdatedBy, id, swap.Version)
+	if err != nil {
+		return err
+	}
+
+	return checkVersionedUpdate(res)
+}
+
+// CheckOpenSwapExistsByAssignmentID reports whether the assignment is already offered or
+// asked for in a swap that has not been decided yet.
+func (r *shiftSwapRepository) CheckOpenSwapExistsByAssignmentID(assignmentID int64) (bool, error) {
+	query := `
+		SELECT COUNT(*) 
+		FROM shift_swaps
+		WHERE (proposer_assignment_id = ? OR recipient_assignment_id = ?)
+		AND status IN (?, ?)
+	`
+
+	var openSwapCount int
+	err := r.db.QueryRow(query, assignmentID, assignmentID, constants.SHIFT_SWAP_STATUS_PROPOSED, constants.SHIFT_SWAP_STATUS_ACCEPTED).Scan(&openSwapCount)
+	if err != nil {
+		return false, err
+	}
+
+	return openSwapCount > 0, nil
+}
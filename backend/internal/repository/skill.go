@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/andibalo/payd-test/backend/internal/model"
+)
+
+type skillRepository struct {
+	db DBTX
+}
+
+func NewSkillRepository(db DBTX) SkillRepository {
+	return &skillRepository{
+		db: db,
+	}
+}
+
+func (r *skillRepository) WithTx(tx *sql.Tx) SkillRepository {
+	return &skillRepository{
+		db: tx,
+	}
+}
+
+type GetSkillListFilter struct {
+	IncludeDeleted bool `json:"include_deleted"`
+}
+
+func (r *skillRepository) Save(skill *model.Skill) error {
+	query := `
+		INSERT INTO skills (name, description, created_by, created_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+	`
+
+	res, err := r.db.Exec(query, skill.Name, skill.Description, skill.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	skill.ID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetByID returns a skill including soft-deleted ones.
+func (r *skillRepository) GetByID(id int64) (*model.Skill, error) {
+	skill := &model.Skill{}
+
+	query := `
+		SELECT id, name, description, created_at, created_by, updated_at, updated_by, deleted_at, deleted_by
+		FROM skills
+		WHERE id = ?
+		LIMIT 1
+	`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&skill.ID, &skill.Name, &skill.Description,
+		&skill.CreatedAt, &skill.CreatedBy, &skill.UpdatedAt, &skill.UpdatedBy, &skill.DeletedAt, &skill.DeletedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return skill, nil
+}
+
+// GetByName looks up a skill case-insensitively, including soft-deleted ones.
+func (r *skillRepository) GetByName(name string) (*model.Skill, error) {
+	skill := &model.Skill{}
+
+	query := `
+		SELECT id, name, description, created_at, created_by, updated_at, updated_by, deleted_at, deleted_by
+		FROM skills
+		WHERE name = ? COLLATE NOCASE
+		LIMIT 1
+	`
+
+	err := r.db.QueryRow(query, name).Scan(
+		&skill.ID, &skill.Name, &skill.Description,
+		&skill.CreatedAt, &skill.CreatedBy, &skill.UpdatedAt, &skill.UpdatedBy, &skill.DeletedAt, &skill.DeletedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return skill, nil
+}
+
+func (r *skillRepository) GetList(filter GetSkillListFilter) ([]model.Skill, error) {
+	skills := []model.Skill{}
+
+	query := `
+		SELECT id, name, description, created_at, created_by, updated_at, updated_by, deleted_at, deleted_by
+		FROM skills
+	`
+
+	if !filter.IncludeDeleted {
+		query += " WHERE deleted_at IS NULL"
+	}
+
+	query += " ORDER BY name ASC"
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var skill model.Skill
+		err := rows.Scan(
+			&skill.ID, &skill.Name, &skill.Description,
+			&skill.CreatedAt, &skill.CreatedBy, &skill.UpdatedAt, &skill.UpdatedBy, &skill.DeletedAt, &skill.DeletedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		skills = append(skills, skill)
+	}
+
+	return skills, nil
+}
+
+func (r *skillRepository) UpdateByID(id int64, skill *model.Skill) error {
+	query := `
+		UPDATE skills 
+		SET name = ?, description = ?, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	_, err := r.db.Exec(query, skill.Name, skill.Description, skill.UpdatedBy, id)
+	return err
+}
+
+func (r *skillRepository) DeleteByID(id int64, deletedBy string) error {
+	query := `
+		UPDATE skills 
+		SET deleted_at = CURRENT_TIMESTAMP, deleted_by = ? 
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	_, err := r.db.Exec(query, deletedBy, id)
+	return err
+}
+
+// GetWorkerSkillsByUserID returns the live certifications of a worker for skills that
+// have not been deleted.
+func (r *skillRepository) GetWorkerSkillsByUserID(userID int64) ([]model.WorkerSkill, error) {
+	workerSkills := []model.WorkerSkill{}
+
+	query := `
+		SELECT worker_skills.id, worker_skills.user_id, worker_skills.skill_id, skills.name, worker_skills.expires_at, 
+			worker_skills.created_at, worker_skills.created_by, worker_skills.updated_at, worker_skills.updated_by
+		FROM worker_skills
+		JOIN skills ON worker_skills.skill_id = skills.id
+		WHERE worker_skills.user_id = ?
+		AND worker_skills.deleted_at IS NULL
+		AND skills.deleted_at IS NULL
+		ORDER BY skills.name ASC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var workerSkill model.WorkerSkill
+		err := rows.Scan(
+			&workerSkill.ID, &workerSkill.UserID, &workerSkill.SkillID, &workerSkill.SkillName, &workerSkill.ExpiresAt,
+			&workerSkill.CreatedAt, &workerSkill.CreatedBy, &workerSkill.UpdatedAt, &workerSkill.UpdatedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		workerSkills = append(workerSkills, workerSkill)
+	}
+
+	return workerSkills, rows.Err()
+}
+
+// SaveWorkerSkill grants a skill to a worker, or renews the expiry date when the worker
+// already holds it.
+func (r *skillRepository) SaveWorkerSkill(workerSkill *model.WorkerSkill) error {
+	query := `
+		UPDATE worker_skills 
+		SET expires_at = ?, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = ? AND skill_id = ? AND deleted_at IS NULL
+		RETURNING id
+	`
+
+	err := r.db.QueryRow(query, workerSkill.ExpiresAt, workerSkill.CreatedBy, workerSkill.UserID, workerSkill.SkillID).Scan(&workerSkill.ID)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	query = `
+		INSERT INTO worker_skills (user_id, skill_id, expires_at, created_by, created_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`
+
+	res, err := r.db.Exec(query, workerSkill.UserID, workerSkill.SkillID, workerSkill.ExpiresAt, workerSkill.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	workerSkill.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *skillRepository) DeleteWorkerSkill(userID, skillID int64, deletedBy string) (bool, error) {
+	query := `
+		UPDATE worker_skills 
+		SET deleted_at = CURRENT_TIMESTAMP, deleted_by = ? 
+		WHERE user_id = ? AND skill_id = ? AND deleted_at IS NULL
+	`
+
+	res, err := r.db.Exec(query, deletedBy, userID, skillID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"database/sql"
+)
+
+type transactor struct {
+	db *sql.DB
+}
+
+func NewTransactor(db *sql.DB) Transactor {
+	return &transactor{
+		db: db,
+	}
+}
+
+// WithinTx runs fn inside a single database transaction. The transaction is
+// committed when fn returns nil and rolled back otherwise.
+func (t *transactor) WithinTx(fn func(tx *sql.Tx) error) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = fn(tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
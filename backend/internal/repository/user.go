@@ -3,18 +3,25 @@ package repository
 import (
 	"database/sql"
 	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/guregu/null/v6"
 )
 
 type userRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewUserRepository(db *sql.DB) UserRepository {
+func NewUserRepository(db DBTX) UserRepository {
 	return &userRepository{
 		db: db,
 	}
 }
 
+func (r *userRepository) WithTx(tx *sql.Tx) UserRepository {
+	return &userRepository{
+		db: tx,
+	}
+}
+
 type GetUserListFilter struct {
 	Role string
 }
@@ -27,19 +34,54 @@ func (r *userRepository) Save(user *model.User) error {
 		VALUES ( ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.Exec(query, user.FirstName, user.LastName, user.Email, user.Password, user.Role, user.CreatedBy)
+	res, err := r.db.Exec(query, user.FirstName, user.LastName, user.Email, user.Password, user.Role, user.CreatedBy)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	user.ID, err = res.LastInsertId()
+
+	return err
+}
+
+func (r *userRepository) GetByID(id int64) (*model.User, error) {
+	user := &model.User{}
+
+	query := `
+		SELECT id, first_name, last_name, email, password, role, hourly_rate, created_by, created_at, 
+		       updated_by, updated_at
+		FROM users 
+		WHERE id = ? AND deleted_at IS NULL
+		LIMIT 1
+	`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Password, &user.Role, &user.HourlyRate,
+		&user.CreatedBy, &user.CreatedAt, &user.UpdatedBy, &user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *userRepository) UpdateHourlyRateByID(id int64, hourlyRate null.Float, updatedBy string) error {
+	query := `
+		UPDATE users 
+		SET hourly_rate = ?, updated_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	_, err := r.db.Exec(query, hourlyRate, updatedBy, id)
+	return err
 }
 
 func (r *userRepository) GetByEmail(email string) (*model.User, error) {
 	user := &model.User{}
 
 	query := `
-		SELECT id, first_name, last_name, email, password, role, created_by, created_at, 
+		SELECT id, first_name, last_name, email, password, role, hourly_rate, created_by, created_at, 
 		       updated_by, updated_at
 		FROM users 
 		WHERE email = ? AND deleted_at IS NULL
@@ -47,7 +89,7 @@ func (r *userRepository) GetByEmail(email string) (*model.User, error) {
 	`
 
 	err := r.db.QueryRow(query, email).Scan(
-		&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Password, &user.Role,
+		&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Password, &user.Role, &user.HourlyRate,
 		&user.CreatedBy, &user.CreatedAt, &user.UpdatedBy, &user.UpdatedAt,
 	)
 	if err != nil {
@@ -61,7 +103,7 @@ func (r *userRepository) GetList(filter GetUserListFilter) ([]model.User, error)
 	users := []model.User{}
 
 	query := `
-		SELECT id, first_name, last_name, email, password, role, created_by, created_at, 
+		SELECT id, first_name, last_name, email, password, role, hourly_rate, created_by, created_at, 
 			   updated_by, updated_at 
 		FROM users WHERE deleted_at IS NULL
 	`
@@ -84,7 +126,7 @@ func (r *userRepository) GetList(filter GetUserListFilter) ([]model.User, error)
 	for rows.Next() {
 		var user model.User
 		err := rows.Scan(
-			&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Password, &user.Role,
+			&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Password, &user.Role, &user.HourlyRate,
 			&user.CreatedBy, &user.CreatedAt, &user.UpdatedBy, &user.UpdatedAt,
 		)
 		if err != nil {
@@ -0,0 +1,16 @@
+package request
+
+// GetAuditLogListReq filters the audit trail. From and To are RFC 3339 instants and
+// bound a half-open range [from, to).
+type GetAuditLogListReq struct {
+	EntityType string `json:"entity_type" form:"entity_type" binding:"omitempty,oneof=SHIFT SHIFT_REQUEST SHIFT_ASSIGNMENT USER"`
+	EntityID   int64  `json:"entity_id" form:"entity_id"`
+	Actor      string `json:"actor" form:"actor"`
+	From       string `json:"from" form:"from"`
+	To         string `json:"to" form:"to"`
+	Limit      int    `json:"limit" form:"limit" binding:"omitempty,min=1"`
+	Offset     int    `json:"offset" form:"offset" binding:"omitempty,min=0"`
+	Cursor     string `json:"cursor" form:"cursor"`
+
+	UserEmail string `json:"-"`
+}
@@ -0,0 +1,66 @@
+package request
+
+import (
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/guregu/null/v6"
+	"strings"
+)
+
+type GetAutoApprovalRuleListReq struct {
+	ActiveOnly bool `json:"active_only" form:"active_only"`
+
+	UserEmail string `json:"-"`
+}
+
+// CreateAutoApprovalRuleReq defines a rule. IsActive defaults to true.
+type CreateAutoApprovalRuleReq struct {
+	Name                string `json:"name" binding:"required"`
+	RoleID              *int64 `json:"role_id" binding:"omitempty,min=1"`
+	LocationID          *int64 `json:"location_id" binding:"omitempty,min=1"`
+	MinCompletedShifts  int    `json:"min_completed_shifts" binding:"min=0"`
+	MaxHoursBeforeStart *int64 `json:"max_hours_before_start" binding:"omitempty,min=1"`
+	IsActive            *bool  `json:"is_active"`
+
+	UserEmail string `json:"-"`
+}
+
+func (r *CreateAutoApprovalRuleReq) ToModel() *model.AutoApprovalRule {
+
+	rule := &model.AutoApprovalRule{
+		Name:                strings.TrimSpace(r.Name),
+		RoleID:              null.IntFromPtr(r.RoleID),
+		LocationID:          null.IntFromPtr(r.LocationID),
+		MinCompletedShifts:  r.MinCompletedShifts,
+		MaxHoursBeforeStart: null.IntFromPtr(r.MaxHoursBeforeStart),
+		IsActive:            r.IsActive == nil || *r.IsActive,
+		CreatedBy:           r.UserEmail,
+	}
+
+	return rule
+}
+
+type UpdateAutoApprovalRuleReq struct {
+	Name                string `json:"name" binding:"required"`
+	RoleID              *int64 `json:"role_id" binding:"omitempty,min=1"`
+	LocationID          *int64 `json:"location_id" binding:"omitempty,min=1"`
+	MinCompletedShifts  int    `json:"min_completed_shifts" binding:"min=0"`
+	MaxHoursBeforeStart *int64 `json:"max_hours_before_start" binding:"omitempty,min=1"`
+	IsActive            bool   `json:"is_active"`
+
+	UserEmail string `json:"-"`
+}
+
+func (r *UpdateAutoApprovalRuleReq) ToModel() *model.AutoApprovalRule {
+
+	rule := &model.AutoApprovalRule{
+		Name:                strings.TrimSpace(r.Name),
+		RoleID:              null.IntFromPtr(r.RoleID),
+		LocationID:          null.IntFromPtr(r.LocationID),
+		MinCompletedShifts:  r.MinCompletedShifts,
+		MaxHoursBeforeStart: null.IntFromPtr(r.MaxHoursBeforeStart),
+		IsActive:            r.IsActive,
+		UpdatedBy:           null.StringFrom(r.UserEmail),
+	}
+
+	return rule
+}
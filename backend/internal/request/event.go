@@ -0,0 +1,8 @@
+package request
+
+type GetEventListReq struct {
+	UserID int64 `json:"user_id" form:"user_id"`
+	Limit  int   `json:"limit" form:"limit"`
+
+	UserEmail string `json:"-"`
+}
@@ -0,0 +1,61 @@
+package request
+
+import (
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/guregu/null/v6"
+	"strings"
+)
+
+type GetLocationListReq struct {
+	IncludeDeleted bool `json:"include_deleted" form:"include_deleted"`
+
+	UserEmail string `json:"-"`
+}
+
+type CreateLocationReq struct {
+	Name      string   `json:"name" binding:"required"`
+	Address   string   `json:"address"`
+	Latitude  *float64 `json:"latitude" binding:"omitempty,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" binding:"omitempty,min=-180,max=180"`
+	Timezone  string   `json:"timezone" binding:"required"`
+
+	UserEmail string `json:"-"`
+}
+
+func (r *CreateLocationReq) ToModel() *model.Location {
+
+	location := &model.Location{
+		Name:      strings.TrimSpace(r.Name),
+		Address:   null.NewString(r.Address, r.Address != ""),
+		Latitude:  null.FloatFromPtr(r.Latitude),
+		Longitude: null.FloatFromPtr(r.Longitude),
+		Timezone:  r.Timezone,
+		CreatedBy: r.UserEmail,
+	}
+
+	return location
+}
+
+type UpdateLocationReq struct {
+	Name      string   `json:"name" binding:"required"`
+	Address   string   `json:"address"`
+	Latitude  *float64 `json:"latitude" binding:"omitempty,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" binding:"omitempty,min=-180,max=180"`
+	Timezone  string   `json:"timezone" binding:"required"`
+
+	UserEmail string `json:"-"`
+}
+
+func (r *UpdateLocationReq) ToModel() *model.Location {
+
+	location := &model.Location{
+		Name:      strings.TrimSpace(r.Name),
+		Address:   null.NewString(r.Address, r.Address != ""),
+		Latitude:  null.FloatFromPtr(r.Latitude),
+		Longitude: null.FloatFromPtr(r.Longitude),
+		Timezone:  r.Timezone,
+		UpdatedBy: null.StringFrom(r.UserEmail),
+	}
+
+	return location
+}
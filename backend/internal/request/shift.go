@@ -8,18 +8,34 @@ import (
 )
 
 type GetShiftAssignmentListReq struct {
-	UserID int64 `json:"user_id" form:"user_id"`
-	Limit  int   `json:"limit" form:"limit"`
-	Offset int   `json:"offset" form:"offset"`
+	UserID int64  `json:"user_id" form:"user_id"`
+	Limit  int    `json:"limit" form:"limit"`
+	Offset int    `json:"offset" form:"offset"`
+	Cursor string `json:"cursor" form:"cursor"`
 
 	UserEmail string `json:"-"`
 }
+
+// GetShiftListReq filters the shift list. StartDate and EndDate are YYYY-MM-DD local shift
+// dates, and role_id may be repeated to match any of several roles.
 type GetShiftListReq struct {
-	ShowOnlyUnassigned bool `json:"show_only_unassigned" form:"show_only_unassigned"`
-	Limit              int  `json:"limit" form:"limit"`
-	Offset             int  `json:"offset" form:"offset"`
+	ShowOnlyUnassigned bool    `json:"show_only_unassigned" form:"show_only_unassigned"`
+	StartDate          string  `json:"start_date" form:"start_date"`
+	EndDate            string  `json:"end_date" form:"end_date"`
+	RoleIDs            []int64 `json:"role_id" form:"role_id"`
+	LocationID         int64   `json:"location_id" form:"location_id"`
+	IsActive           *bool   `json:"is_active" form:"is_active"`
+	Status             string  `json:"status" form:"status" binding:"omitempty,oneof=DRAFT PUBLISHED CANCELLED COMPLETED"`
+	AssignedUserID     int64   `json:"assigned_user_id" form:"assigned_user_id"`
+	SortBy             string  `json:"sort_by" form:"sort_by" binding:"omitempty,oneof=created_at date start_time role deleted_at"`
+	SortDirection      string  `json:"sort_direction" form:"sort_direction" binding:"omitempty,oneof=asc desc"`
+	Limit              int     `json:"limit" form:"limit"`
+	Offset             int     `json:"offset" form:"offset"`
+	Cursor             string  `json:"cursor" form:"cursor"`
 
 	UserEmail string `json:"-"`
+	// Deleted lists the trash instead of the live shifts.
+	Deleted bool `json:"-"`
 }
 
 type GetShiftRequestListReq struct {
@@ -27,58 +43,129 @@ type GetShiftRequestListReq struct {
 	Offset int    `json:"offset" form:"offset"`
 	Status string `json:"status" form:"status"`
 	UserID int64  `json:"user_id" form:"user_id"`
+	Cursor string `json:"cursor" form:"cursor"`
 
 	UserEmail string `json:"-"`
 }
 
+// CreateShiftReq takes the shift as absolute start and end instants (RFC 3339 with offset).
+// The shift date is derived from the start in the shift timezone, which is the location's
+// timezone when a location is given, otherwise Timezone or UTC.
 type CreateShiftReq struct {
-	Date      time.Time `json:"date" binding:"required"`
-	StartTime time.Time `json:"start_time" binding:"required"`
-	EndTime   time.Time `json:"end_time" binding:"required"`
-	RoleID    int       `json:"role_id" binding:"required"`
-	Location  string    `json:"location"`
+	StartTime  time.Time       `json:"start_time" binding:"required"`
+	EndTime    time.Time       `json:"end_time" binding:"required"`
+	Timezone   string          `json:"timezone"`
+	RoleID     int             `json:"role_id" binding:"required"`
+	LocationID int64           `json:"location_id"`
+	Headcount  int             `json:"headcount" binding:"omitempty,min=1"`
+	HourlyRate *float64        `json:"hourly_rate" binding:"omitempty,min=0"`
+	Breaks     []ShiftBreakReq `json:"breaks" binding:"omitempty,dive"`
+	// RequiredSkillIDs are the skills a worker needs to request the shift.
+	RequiredSkillIDs []int64 `json:"required_skill_ids" binding:"omitempty,dive,min=1"`
 
 	UserEmail string `json:"-"`
 }
 
+// ShiftBreakReq is a break inside a shift. StartTime is required for FIXED breaks and must
+// be left out for FLOATING ones.
+type ShiftBreakReq struct {
+	BreakType       string     `json:"break_type" binding:"required,oneof=FIXED FLOATING"`
+	StartTime       *time.Time `json:"start_time"`
+	DurationMinutes int        `json:"duration_minutes" binding:"required,min=1"`
+	IsPaid          bool       `json:"is_paid"`
+}
+
+func toShiftBreaks(breaks []ShiftBreakReq, createdBy string) []model.ShiftBreak {
+	shiftBreaks := make([]model.ShiftBreak, 0, len(breaks))
+	for _, b := range breaks {
+		startTime := null.Time{}
+		if b.StartTime != nil {
+			startTime = null.TimeFrom(b.StartTime.UTC())
+		}
+
+		shiftBreaks = append(shiftBreaks, model.ShiftBreak{
+			BreakType:       b.BreakType,
+			StartTime:       startTime,
+			DurationMinutes: b.DurationMinutes,
+			IsPaid:          b.IsPaid,
+			CreatedBy:       createdBy,
+		})
+	}
+
+	return shiftBreaks
+}
+
 func (r *CreateShiftReq) ToModel() *model.Shift {
 
+	headcount := r.Headcount
+	if headcount == 0 {
+		headcount = 1
+	}
+
 	shift := &model.Shift{
-		Date:      r.Date,
-		StartTime: r.StartTime,
-		EndTime:   r.EndTime,
-		RoleID:    r.RoleID,
-		Location:  null.StringFrom(r.Location),
-		IsActive:  true,
-		CreatedBy: r.UserEmail,
+		StartTime:  r.StartTime,
+		EndTime:    r.EndTime,
+		RoleID:     r.RoleID,
+		LocationID: null.NewInt(r.LocationID, r.LocationID != 0),
+		Headcount:  headcount,
+		IsActive:   true,
+		Status:     constants.SHIFT_STATUS_DRAFT,
+		CreatedBy:  r.UserEmail,
+		HourlyRate: null.FloatFromPtr(r.HourlyRate),
+		Breaks:     toShiftBreaks(r.Breaks, r.UserEmail),
+
+		RequiredSkillIDs: r.RequiredSkillIDs,
 	}
 
 	return shift
 }
 
 type UpdateShiftReq struct {
-	Date      time.Time `json:"date"`
-	StartTime time.Time `json:"start_time"`
-	EndTime   time.Time `json:"end_time"`
-	RoleID    int       `json:"role_id"`
-	Location  string    `json:"location"`
-	IsActive  bool      `json:"is_active"`
+	StartTime  time.Time `json:"start_time" binding:"required"`
+	EndTime    time.Time `json:"end_time" binding:"required"`
+	Timezone   string    `json:"timezone"`
+	RoleID     int       `json:"role_id"`
+	LocationID int64     `json:"location_id"`
+	// Headcount changes the number of slots. Leaving it out keeps the current headcount.
+	Headcount *int `json:"headcount" binding:"omitempty,min=1"`
+	// HourlyRate changes the shift pay rate override. Leaving it out keeps the current
+	// override, and ClearHourlyRate removes it so the worker and role rates apply again.
+	HourlyRate      *float64 `json:"hourly_rate" binding:"omitempty,min=0"`
+	ClearHourlyRate bool     `json:"clear_hourly_rate"`
+	IsActive        bool     `json:"is_active"`
+	// Breaks replaces the breaks of the shift. Leaving it out keeps the current breaks and
+	// an empty list removes them.
+	Breaks []ShiftBreakReq `json:"breaks" binding:"omitempty,dive"`
+	// RequiredSkillIDs replaces the required skills of the shift in the same way as Breaks.
+	RequiredSkillIDs []int64 `json:"required_skill_ids" binding:"omitempty,dive,min=1"`
 
 	UserEmail string `json:"-"`
+	// Version comes from If-Match; zero makes the update unconditional.
+	Version int `json:"-"`
 }
 
 func (r *UpdateShiftReq) ToModel() *model.Shift {
 
 	shift := &model.Shift{
-		Date:      r.Date,
-		StartTime: r.StartTime,
-		EndTime:   r.EndTime,
-		RoleID:    r.RoleID,
-		Location:  null.StringFrom(r.Location),
-		IsActive:  r.IsActive,
-		UpdatedBy: null.StringFrom(r.UserEmail),
+		StartTime:  r.StartTime,
+		EndTime:    r.EndTime,
+		RoleID:     r.RoleID,
+		LocationID: null.NewInt(r.LocationID, r.LocationID != 0),
+		HourlyRate: null.FloatFromPtr(r.HourlyRate),
+		IsActive:   r.IsActive,
+		UpdatedBy:  null.StringFrom(r.UserEmail),
+	}
+
+	if r.Headcount != nil {
+		shift.Headcount = *r.Headcount
 	}
 
+	if r.Breaks != nil {
+		shift.Breaks = toShiftBreaks(r.Breaks, r.UserEmail)
+	}
+
+	shift.RequiredSkillIDs = r.RequiredSkillIDs
+
 	return shift
 }
 
@@ -106,11 +193,81 @@ type ApproveShiftRequestReq struct {
 	RequestedShiftID int64 `json:"requested_shift_id" binding:"required"`
 
 	UserEmail string `json:"-"`
+	Version   int    `json:"-"`
 }
 
 type RejectShiftRequestReq struct {
 	RequestedShiftID int64  `json:"requested_shift_id"`
 	Reason           string `json:"reason" binding:"required"`
 
+	UserEmail string `json:"-"`
+	Version   int    `json:"-"`
+}
+
+// BulkShiftRequestDecisionReq approves or rejects several requests at once. Reason is only
+// used when rejecting and is shared by every request in the batch.
+type BulkShiftRequestDecisionReq struct {
+	RequestIDs []int64 `json:"request_ids" binding:"required,min=1,max=100,dive,min=1"`
+	Reason     string  `json:"reason"`
+
+	UserEmail string `json:"-"`
+}
+
+type WithdrawShiftRequestReq struct {
+	RequestedShiftID int64 `json:"-"`
+
+	UserID    int64  `json:"-"`
+	UserEmail string `json:"-"`
+	Version   int    `json:"-"`
+}
+
+type RestoreShiftReq struct {
+	ShiftID int64 `json:"-"`
+
+	UserEmail string `json:"-"`
+}
+
+type ReleaseShiftAssignmentReq struct {
+	AssignmentID int64  `json:"-"`
+	Reason       string `json:"reason"`
+
+	UserEmail string `json:"-"`
+}
+
+type CancelShiftReq struct {
+	ShiftID int64  `json:"-"`
+	Reason  string `json:"reason" binding:"required"`
+
+	UserEmail string `json:"-"`
+}
+
+// PublishShiftsReq publishes every draft shift dated StartDate to EndDate, optionally only
+// at one location.
+type PublishShiftsReq struct {
+	StartDate  string `json:"start_date" binding:"required"`
+	EndDate    string `json:"end_date" binding:"required"`
+	LocationID int64  `json:"location_id"`
+
+	UserEmail string `json:"-"`
+}
+
+// GetShiftCostReq estimates the labour cost of the shifts dated StartDate to EndDate.
+type GetShiftCostReq struct {
+	StartDate  string `form:"start_date" binding:"required"`
+	EndDate    string `form:"end_date" binding:"required"`
+	LocationID int64  `form:"location_id"`
+
+	UserEmail string `json:"-"`
+}
+
+// CopyShiftRosterReq clones the active shifts dated SourceStartDate to SourceEndDate into
+// the range starting at TargetStartDate. Without KeepAssignments every copy is created
+// as an open shift.
+type CopyShiftRosterReq struct {
+	SourceStartDate string `json:"source_start_date" binding:"required"`
+	SourceEndDate   string `json:"source_end_date" binding:"required"`
+	TargetStartDate string `json:"target_start_date" binding:"required"`
+	KeepAssignments bool   `json:"keep_assignments"`
+
 	UserEmail string `json:"-"`
 }
@@ -0,0 +1,141 @@
+package request
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	ShiftImportFormatCSV  = "csv"
+	ShiftImportFormatJSON = "json"
+)
+
+type ImportShiftReq struct {
+	DryRun bool   `json:"dry_run" form:"dry_run"`
+	Format string `json:"format" form:"format"`
+
+	Rows      []ImportShiftRow `json:"-"`
+	UserEmail string           `json:"-"`
+}
+
+// ImportShiftRow is one planned shift. Date is the local date at the location, Start and
+// End are HH:MM local times (an End not after Start ends on the next day), and Role and
+// Location are matched by name.
+type ImportShiftRow struct {
+	Row       int    `json:"-"`
+	Date      string `json:"date"`
+	Start     string `json:"start"`
+	End       string `json:"end"`
+	Role      string `json:"role"`
+	Location  string `json:"location"`
+	Timezone  string `json:"timezone"`
+	Headcount int    `json:"headcount"`
+}
+
+var importShiftCSVColumns = []string{"date", "start", "end", "role", "location", "timezone", "headcount"}
+
+// ParseImportShiftRows reads the rows of an import file. Row numbers follow the file, so
+// for CSV the first data row is row 2, right below the header.
+func ParseImportShiftRows(format string, r io.Reader) ([]ImportShiftRow, error) {
+	switch strings.ToLower(format) {
+	case ShiftImportFormatCSV:
+		return parseImportShiftCSV(r)
+	case ShiftImportFormatJSON:
+		return parseImportShiftJSON(r)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q, expected csv or json", format)
+	}
+}
+
+func parseImportShiftCSV(r io.Reader) ([]ImportShiftRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("csv file is empty")
+		}
+		return nil, err
+	}
+
+	columnIndex := map[string]int{}
+	for i, name := range header {
+		columnIndex[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+
+	for _, name := range importShiftCSVColumns[:4] {
+		if _, ok := columnIndex[name]; !ok {
+			return nil, fmt.Errorf("csv header is missing the %q column", name)
+		}
+	}
+
+	rows := []ImportShiftRow{}
+	line := 1
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, err
+		}
+
+		value := func(column string) string {
+			i, ok := columnIndex[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		row := ImportShiftRow{
+			Row:      line,
+			Date:     value("date"),
+			Start:    value("start"),
+			End:      value("end"),
+			Role:     value("role"),
+			Location: value("location"),
+			Timezone: value("timezone"),
+		}
+
+		if row == (ImportShiftRow{Row: line}) && value("headcount") == "" {
+			continue
+		}
+
+		if headcount := value("headcount"); headcount != "" {
+			row.Headcount, err = strconv.Atoi(headcount)
+			if err != nil {
+				// Keep the row so the service reports it as a row-level error.
+				row.Headcount = -1
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func parseImportShiftJSON(r io.Reader) ([]ImportShiftRow, error) {
+	rows := []ImportShiftRow{}
+
+	err := json.NewDecoder(r).Decode(&rows)
+	if err != nil {
+		return nil, fmt.Errorf("json file should be an array of shifts: %w", err)
+	}
+
+	for i := range rows {
+		rows[i].Row = i + 1
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,21 @@
+package request
+
+type GetShiftRoleListReq struct {
+	IncludeDeleted bool `json:"include_deleted" form:"include_deleted"`
+
+	UserEmail string `json:"-"`
+}
+
+type CreateShiftRoleReq struct {
+	RoleName   string   `json:"role_name" binding:"required"`
+	HourlyRate *float64 `json:"hourly_rate" binding:"omitempty,min=0"`
+
+	UserEmail string `json:"-"`
+}
+
+type UpdateShiftRoleReq struct {
+	RoleName   string   `json:"role_name" binding:"required"`
+	HourlyRate *float64 `json:"hourly_rate" binding:"omitempty,min=0"`
+
+	UserEmail string `json:"-"`
+}
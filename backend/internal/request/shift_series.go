@@ -0,0 +1,46 @@
+package request
+
+type GetShiftSeriesListReq struct {
+	Limit  int `json:"limit" form:"limit"`
+	Offset int `json:"offset" form:"offset"`
+
+	UserEmail string `json:"-"`
+}
+
+type CreateShiftSeriesReq struct {
+	RRule          string   `json:"rrule" binding:"required"`
+	StartDate      string   `json:"start_date" binding:"required"`
+	StartTime      string   `json:"start_time" binding:"required"`
+	EndTime        string   `json:"end_time" binding:"required"`
+	RoleID         int      `json:"role_id" binding:"required"`
+	LocationID     int64    `json:"location_id"`
+	Timezone       string   `json:"timezone"`
+	Headcount      int      `json:"headcount" binding:"omitempty,min=1"`
+	ExceptionDates []string `json:"exception_dates"`
+
+	UserEmail string `json:"-"`
+}
+
+// UpdateShiftSeriesReq edits a series. Scope decides whether only the occurrence on
+// OccurrenceDate, that occurrence and every following one, or the whole series is changed.
+// Empty fields keep their current value.
+type UpdateShiftSeriesReq struct {
+	Scope          string `json:"scope" binding:"required"`
+	OccurrenceDate string `json:"occurrence_date"`
+	RRule          string `json:"rrule"`
+	StartTime      string `json:"start_time"`
+	EndTime        string `json:"end_time"`
+	RoleID         int    `json:"role_id"`
+	LocationID     int64  `json:"location_id"`
+	Timezone       string `json:"timezone"`
+	Headcount      int    `json:"headcount" binding:"omitempty,min=1"`
+
+	UserEmail string `json:"-"`
+}
+
+type CancelShiftSeriesReq struct {
+	Scope          string `json:"scope" form:"scope" binding:"required"`
+	OccurrenceDate string `json:"occurrence_date" form:"occurrence_date"`
+
+	UserEmail string `json:"-"`
+}
@@ -0,0 +1,62 @@
+package request
+
+import (
+	"github.com/andibalo/payd-test/backend/internal/constants"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/guregu/null/v6"
+	"strings"
+)
+
+// CreateShiftSwapReq offers the caller's assignment AssignmentID in exchange for
+// TargetAssignmentID, which belongs to another worker.
+type CreateShiftSwapReq struct {
+	AssignmentID       int64  `json:"assignment_id" binding:"required,min=1"`
+	TargetAssignmentID int64  `json:"target_assignment_id" binding:"required,min=1"`
+	Note               string `json:"note" binding:"max=255"`
+
+	UserID    int64  `json:"-"`
+	UserEmail string `json:"-"`
+}
+
+func (r *CreateShiftSwapReq) ToModel(proposer, recipient *model.WorkerShift) *model.ShiftSwap {
+	note := strings.TrimSpace(r.Note)
+
+	swap := &model.ShiftSwap{
+		ProposerID:            proposer.UserID,
+		ProposerAssignmentID:  proposer.ID,
+		ProposerShiftID:       proposer.ShiftID,
+		RecipientID:           recipient.UserID,
+		RecipientAssignmentID: recipient.ID,
+		RecipientShiftID:      recipient.ShiftID,
+		Status:                constants.SHIFT_SWAP_STATUS_PROPOSED,
+		Note:                  null.NewString(note, note != ""),
+		CreatedBy:             r.UserEmail,
+	}
+
+	return swap
+}
+
+type GetShiftSwapListReq struct {
+	UserID int64  `json:"user_id" form:"user_id"`
+	Status string `json:"status" form:"status"`
+
+	UserEmail string `json:"-"`
+}
+
+// ShiftSwapActionReq accepts, declines, cancels or approves a swap. UserID is the worker
+// acting on it and is left zero for admins.
+type ShiftSwapActionReq struct {
+	SwapID int64 `json:"-"`
+
+	UserID    int64  `json:"-"`
+	UserEmail string `json:"-"`
+	Version   int    `json:"-"`
+}
+
+type RejectShiftSwapReq struct {
+	SwapID int64  `json:"-"`
+	Reason string `json:"reason" binding:"required"`
+
+	UserEmail string `json:"-"`
+	Version   int    `json:"-"`
+}
@@ -0,0 +1,59 @@
+package request
+
+import (
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/guregu/null/v6"
+	"strings"
+)
+
+type GetSkillListReq struct {
+	IncludeDeleted bool `json:"include_deleted" form:"include_deleted"`
+
+	UserEmail string `json:"-"`
+}
+
+type CreateSkillReq struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+
+	UserEmail string `json:"-"`
+}
+
+func (r *CreateSkillReq) ToModel() *model.Skill {
+
+	skill := &model.Skill{
+		Name:        strings.TrimSpace(r.Name),
+		Description: null.NewString(r.Description, r.Description != ""),
+		CreatedBy:   r.UserEmail,
+	}
+
+	return skill
+}
+
+type UpdateSkillReq struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+
+	UserEmail string `json:"-"`
+}
+
+func (r *UpdateSkillReq) ToModel() *model.Skill {
+
+	skill := &model.Skill{
+		Name:        strings.TrimSpace(r.Name),
+		Description: null.NewString(r.Description, r.Description != ""),
+		UpdatedBy:   null.StringFrom(r.UserEmail),
+	}
+
+	return skill
+}
+
+// AssignWorkerSkillReq grants a skill to a worker. ExpiresAt is the last day the
+// certification is valid; leave it out for skills that never expire.
+type AssignWorkerSkillReq struct {
+	UserID    int64  `json:"-"`
+	SkillID   int64  `json:"skill_id" binding:"required"`
+	ExpiresAt string `json:"expires_at" binding:"omitempty,datetime=2006-01-02"`
+
+	UserEmail string `json:"-"`
+}
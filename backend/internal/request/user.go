@@ -4,5 +4,16 @@ type GetWorkerListReq struct {
 	Limit  int    `json:"limit"`
 	Cursor string `json:"cursor"`
 
+	// IncludePayRates is only set for admins, other callers get workers without pay rates.
+	IncludePayRates bool   `json:"-"`
+	UserEmail       string `json:"-"`
+}
+
+// SetWorkerPayRateReq overrides the role pay rates for one worker. A null rate removes
+// the override.
+type SetWorkerPayRateReq struct {
+	UserID     int64    `json:"-"`
+	HourlyRate *float64 `json:"hourly_rate" binding:"omitempty,min=0"`
+
 	UserEmail string `json:"-"`
 }
@@ -0,0 +1,21 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditLogData struct {
+	ID         int64           `json:"id"`
+	EntityType string          `json:"entity_type"`
+	EntityID   int64           `json:"entity_id"`
+	Action     string          `json:"action"`
+	Actor      string          `json:"actor"`
+	Changes    json.RawMessage `json:"changes"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type GetAuditLogListResponse struct {
+	Data []AuditLogData `json:"audit_logs"`
+	Meta PaginationMeta `json:"meta"`
+}
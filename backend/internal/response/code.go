@@ -1,15 +1,19 @@
 package response
 
 const (
-	Success           Code = "RMS0000"
-	ServerError       Code = "RMS0001"
-	BadRequest        Code = "RMS0002"
-	InvalidRequest    Code = "RMS0004"
-	Failed            Code = "RMS0073"
-	Pending           Code = "RMS0050"
-	InvalidInputParam Code = "RMS0032"
-	DuplicateUser     Code = "RMS0033"
-	NotFound          Code = "RMS0034"
+	Success            Code = "RMS0000"
+	ServerError        Code = "RMS0001"
+	BadRequest         Code = "RMS0002"
+	InvalidRequest     Code = "RMS0004"
+	Failed             Code = "RMS0073"
+	Pending            Code = "RMS0050"
+	InvalidInputParam  Code = "RMS0032"
+	DuplicateUser      Code = "RMS0033"
+	NotFound           Code = "RMS0034"
+	Conflict           Code = "RMS0035"
+	MissingSkill       Code = "RMS0036"
+	ExpiredSkill       Code = "RMS0037"
+	PreconditionFailed Code = "RMS0038"
 
 	Unauthorized   Code = "RMS0502"
 	Forbidden      Code = "RMS0503"
@@ -19,16 +23,20 @@ const (
 type Code string
 
 var codeMap = map[Code]string{
-	Success:           "success",
-	Failed:            "failed",
-	Pending:           "pending",
-	BadRequest:        "bad or invalid request",
-	Unauthorized:      "Unauthorized Token",
-	GatewayTimeout:    "Gateway Timeout",
-	ServerError:       "Internal Server Error",
-	InvalidInputParam: "Other invalid argument",
-	DuplicateUser:     "duplicate user",
-	NotFound:          "Not found",
+	Success:            "success",
+	Failed:             "failed",
+	Pending:            "pending",
+	BadRequest:         "bad or invalid request",
+	Unauthorized:       "Unauthorized Token",
+	GatewayTimeout:     "Gateway Timeout",
+	ServerError:        "Internal Server Error",
+	InvalidInputParam:  "Other invalid argument",
+	DuplicateUser:      "duplicate user",
+	NotFound:           "Not found",
+	Conflict:           "Conflict with current state",
+	MissingSkill:       "Worker lacks a required skill",
+	ExpiredSkill:       "Worker certification has expired",
+	PreconditionFailed: "Resource version does not match If-Match",
 }
 
 func (c Code) AsString() string {
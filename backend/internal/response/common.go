@@ -1,7 +1,12 @@
 package response
 
+// PaginationMeta describes a list page. CursorEnd is only set while more rows follow the
+// page and is sent back as the cursor query parameter to fetch them. CurrentPage is 0 for
+// cursor pages.
 type PaginationMeta struct {
-	CurrentPage int64 `json:"current_page"`
-	TotalPages  int64 `json:"total_pages"`
-	TotalItems  int64 `json:"total_items"`
+	CurrentPage int64   `json:"current_page"`
+	TotalPages  int64   `json:"total_pages"`
+	TotalItems  int64   `json:"total_items"`
+	CursorStart *string `json:"cursor_start,omitempty"`
+	CursorEnd   *string `json:"cursor_end,omitempty"`
 }
@@ -0,0 +1,16 @@
+package response
+
+import (
+	"encoding/json"
+	"github.com/guregu/null/v6"
+	"time"
+)
+
+type EventData struct {
+	ID        int64           `json:"id"`
+	EventType string          `json:"event_type"`
+	UserID    int64           `json:"user_id"`
+	ShiftID   null.Int        `json:"shift_id"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package response
+
+import (
+	"github.com/guregu/null/v6"
+)
+
+// LocationData is the location embedded in shift, request and assignment list items.
+type LocationData struct {
+	ID        int64       `json:"id"`
+	Name      string      `json:"name"`
+	Address   null.String `json:"address"`
+	Latitude  null.Float  `json:"latitude"`
+	Longitude null.Float  `json:"longitude"`
+	Timezone  string      `json:"timezone"`
+}
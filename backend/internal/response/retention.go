@@ -0,0 +1,14 @@
+package response
+
+import "time"
+
+// RetentionResult reports one retention run. Purged counts the rows hard-deleted from each
+// table, Archived the rows moved from each table to the archive file.
+type RetentionResult struct {
+	RanAt         time.Time        `json:"ran_at"`
+	DeletedBefore time.Time        `json:"deleted_before"`
+	EndedBefore   time.Time        `json:"ended_before"`
+	ArchivePath   string           `json:"archive_path"`
+	Purged        map[string]int64 `json:"purged"`
+	Archived      map[string]int64 `json:"archived"`
+}
@@ -1,46 +1,62 @@
 package response
 
 import (
+	"github.com/andibalo/payd-test/backend/internal/model"
 	"github.com/guregu/null/v6"
 	"time"
 )
 
 type GetShiftRequestListData struct {
-	ID              int64       `json:"id"`
-	UserID          int64       `json:"user_id"`
-	ShiftID         int64       `json:"shift_id"`
-	ShiftDate       time.Time   `json:"shift_date"`
-	ShiftStartTime  time.Time   `json:"shift_start_time"`
-	ShiftEndTime    time.Time   `json:"shift_end_time"`
-	ShiftRoleID     int64       `json:"shift_role_id"`
-	ShiftRoleName   string      `json:"shift_role_name"`
-	Status          string      `json:"status"`
-	RequestedBy     string      `json:"requested_by"`
-	AdminActor      null.String `json:"admin_actor"`
-	RejectionReason null.String `json:"rejection_reason"`
-	CreatedAt       time.Time   `json:"created_at"`
-	CreatedBy       string      `json:"created_by"`
-	UpdatedAt       null.Time   `json:"updated_at"`
-	UpdatedBy       null.String `json:"updated_by"`
-	DeletedAt       null.Time   `json:"deleted_at"`
-	DeletedBy       null.String `json:"deleted_by"`
+	ID                     int64         `json:"id"`
+	UserID                 int64         `json:"user_id"`
+	ShiftID                int64         `json:"shift_id"`
+	ShiftDate              time.Time     `json:"shift_date"`
+	ShiftStartTime         time.Time     `json:"shift_start_time"`
+	ShiftEndTime           time.Time     `json:"shift_end_time"`
+	ShiftTimezone          string        `json:"shift_timezone"`
+	ShiftRoleID            int64         `json:"shift_role_id"`
+	ShiftRoleName          string        `json:"shift_role_name"`
+	ShiftLocation          *LocationData `json:"shift_location"`
+	ShiftRequiredHeadcount int           `json:"shift_required_headcount"`
+	ShiftFilledCount       int           `json:"shift_filled_count"`
+	Status                 string        `json:"status"`
+	RequestedBy            string        `json:"requested_by"`
+	AdminActor             null.String   `json:"admin_actor"`
+	RejectionReason        null.String   `json:"rejection_reason"`
+	Version                int           `json:"version"`
+	CreatedAt              time.Time     `json:"created_at"`
+	CreatedBy              string        `json:"created_by"`
+	UpdatedAt              null.Time     `json:"updated_at"`
+	UpdatedBy              null.String   `json:"updated_by"`
+	DeletedAt              null.Time     `json:"deleted_at"`
+	DeletedBy              null.String   `json:"deleted_by"`
 }
 
 type GetShiftListData struct {
-	ID        int         `json:"id"`
-	Date      time.Time   `json:"date"`
-	StartTime time.Time   `json:"start_time"`
-	EndTime   time.Time   `json:"end_time"`
-	RoleID    int         `json:"role_id"`
-	RoleName  string      `json:"role_name"`
-	Location  null.String `json:"location"`
-	IsActive  bool        `json:"is_active"`
-	CreatedAt time.Time   `json:"created_at"`
-	CreatedBy string      `json:"created_by"`
-	UpdatedAt null.Time   `json:"updated_at"`
-	UpdatedBy null.String `json:"updated_by"`
-	DeletedAt null.Time   `json:"deleted_at"`
-	DeletedBy null.String `json:"deleted_by"`
+	ID                int           `json:"id"`
+	Date              time.Time     `json:"date"`
+	StartTime         time.Time     `json:"start_time"`
+	EndTime           time.Time     `json:"end_time"`
+	Timezone          string        `json:"timezone"`
+	RoleID            int           `json:"role_id"`
+	RoleName          string        `json:"role_name"`
+	Location          *LocationData `json:"location"`
+	RequiredHeadcount int           `json:"required_headcount"`
+	FilledCount       int           `json:"filled_count"`
+	// UnpaidBreakMinutes and NetWorkingMinutes count working time without unpaid breaks.
+	UnpaidBreakMinutes int         `json:"unpaid_break_minutes"`
+	NetWorkingMinutes  int         `json:"net_working_minutes"`
+	IsActive           bool        `json:"is_active"`
+	Status             string      `json:"status"`
+	PublishedAt        null.Time   `json:"published_at"`
+	PublishedBy        null.String `json:"published_by"`
+	Version            int         `json:"version"`
+	CreatedAt          time.Time   `json:"created_at"`
+	CreatedBy          string      `json:"created_by"`
+	UpdatedAt          null.Time   `json:"updated_at"`
+	UpdatedBy          null.String `json:"updated_by"`
+	DeletedAt          null.Time   `json:"deleted_at"`
+	DeletedBy          null.String `json:"deleted_by"`
 }
 
 type GetShiftRequestListResponse struct {
@@ -54,27 +70,95 @@ type GetShiftListResponse struct {
 }
 
 type GetShiftAssignmentListData struct {
-	ID             int64       `json:"id"`
-	UserID         int64       `json:"user_id"`
-	FirstName      string      `json:"first_name"`
-	LastName       string      `json:"last_name"`
-	Email          string      `json:"email"`
-	ShiftID        int64       `json:"shift_id"`
-	ShiftDate      time.Time   `json:"shift_date"`
-	ShiftStartTime time.Time   `json:"shift_start_time"`
-	ShiftEndTime   time.Time   `json:"shift_end_time"`
-	ShiftRoleName  string      `json:"shift_role_name"`
-	AssignedAt     time.Time   `json:"assigned_at"`
-	AssignedBy     string      `json:"assigned_by_by"`
-	CreatedAt      time.Time   `json:"created_at"`
-	CreatedBy      string      `json:"created_by"`
-	UpdatedAt      null.Time   `json:"updated_at"`
-	UpdatedBy      null.String `json:"updated_by"`
-	DeletedAt      null.Time   `json:"deleted_at"`
-	DeletedBy      null.String `json:"deleted_by"`
+	ID                 int64         `json:"id"`
+	UserID             int64         `json:"user_id"`
+	FirstName          string        `json:"first_name"`
+	LastName           string        `json:"last_name"`
+	Email              string        `json:"email"`
+	ShiftID            int64         `json:"shift_id"`
+	ShiftDate          time.Time     `json:"shift_date"`
+	ShiftStartTime     time.Time     `json:"shift_start_time"`
+	ShiftEndTime       time.Time     `json:"shift_end_time"`
+	ShiftTimezone      string        `json:"shift_timezone"`
+	ShiftRoleName      string        `json:"shift_role_name"`
+	ShiftLocation      *LocationData `json:"shift_location"`
+	UnpaidBreakMinutes int           `json:"unpaid_break_minutes"`
+	NetWorkingMinutes  int           `json:"net_working_minutes"`
+	AssignedAt         time.Time     `json:"assigned_at"`
+	AssignedBy         string        `json:"assigned_by_by"`
+	CreatedAt          time.Time     `json:"created_at"`
+	CreatedBy          string        `json:"created_by"`
+	UpdatedAt          null.Time     `json:"updated_at"`
+	UpdatedBy          null.String   `json:"updated_by"`
+	DeletedAt          null.Time     `json:"deleted_at"`
+	DeletedBy          null.String   `json:"deleted_by"`
 }
 
 type GetShiftAssignmentListResponse struct {
 	Data []GetShiftAssignmentListData `json:"shifts"`
 	Meta PaginationMeta               `json:"meta"`
 }
+
+type PublishShiftsResult struct {
+	PublishedCount int     `json:"published_count"`
+	ShiftIDs       []int64 `json:"shift_ids"`
+}
+
+// SkippedShiftRestoreData explains why a request or assignment removed together with a
+// shift was left alone when the shift was restored. Affected is REQUEST or ASSIGNMENT.
+type SkippedShiftRestoreData struct {
+	UserID   int64  `json:"user_id"`
+	Affected string `json:"affected"`
+	Reason   string `json:"reason"`
+}
+
+type RestoreShiftResult struct {
+	ShiftID                   int                       `json:"shift_id"`
+	RestoredRequestUserIDs    []int64                   `json:"restored_request_user_ids"`
+	RestoredAssignmentUserIDs []int64                   `json:"restored_assignment_user_ids"`
+	Skipped                   []SkippedShiftRestoreData `json:"skipped"`
+}
+
+type CancelShiftResult struct {
+	CancelledRequestUserIDs []int64 `json:"cancelled_request_user_ids"`
+	EndedAssignmentUserIDs  []int64 `json:"ended_assignment_user_ids"`
+}
+
+// ShiftRequestQueueItem is a request waiting for a shift. Position is its rank in the queue,
+// starting at 1. WeeklyShiftCount is how many shifts the worker already holds that week.
+type ShiftRequestQueueItem struct {
+	Position         int `json:"position"`
+	WeeklyShiftCount int `json:"weekly_shift_count"`
+	model.ShiftRequest
+}
+
+type ShiftRequestQueue struct {
+	ShiftID     int                     `json:"shift_id"`
+	Order       string                  `json:"order"`
+	Headcount   int                     `json:"headcount"`
+	FilledSlots int                     `json:"filled_slots"`
+	Requests    []ShiftRequestQueueItem `json:"requests"`
+}
+
+type ReleaseShiftAssignmentResult struct {
+	AssignmentID       int64   `json:"assignment_id"`
+	ShiftID            int64   `json:"shift_id"`
+	UserID             int64   `json:"user_id"`
+	PromotedRequestIDs []int64 `json:"promoted_request_ids"`
+}
+
+// BulkShiftRequestOutcome is what happened to one request of a bulk approve or reject. Status
+// is the request status after a success; Code and Error explain a failure.
+type BulkShiftRequestOutcome struct {
+	RequestID int64  `json:"request_id"`
+	Success   bool   `json:"success"`
+	Status    string `json:"status,omitempty"`
+	Code      string `json:"code,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type BulkShiftRequestResult struct {
+	SucceededCount int                       `json:"succeeded_count"`
+	FailedCount    int                       `json:"failed_count"`
+	Results        []BulkShiftRequestOutcome `json:"results"`
+}
@@ -0,0 +1,28 @@
+package response
+
+import "time"
+
+type CopiedShiftData struct {
+	SourceShiftID   int       `json:"source_shift_id"`
+	ShiftID         int       `json:"shift_id"`
+	StartTime       time.Time `json:"start_time"`
+	EndTime         time.Time `json:"end_time"`
+	AssignedUserIDs []int64   `json:"assigned_user_ids"`
+}
+
+// SkippedShiftCopyData explains why a shift, or one of its assignments when UserID is
+// set, was not copied.
+type SkippedShiftCopyData struct {
+	SourceShiftID int    `json:"source_shift_id"`
+	UserID        *int64 `json:"user_id,omitempty"`
+	Reason        string `json:"reason"`
+}
+
+type CopyShiftRosterResult struct {
+	TargetStartDate string                 `json:"target_start_date"`
+	TargetEndDate   string                 `json:"target_end_date"`
+	CreatedCount    int                    `json:"created_count"`
+	SkippedCount    int                    `json:"skipped_count"`
+	Created         []CopiedShiftData      `json:"created"`
+	Skipped         []SkippedShiftCopyData `json:"skipped"`
+}
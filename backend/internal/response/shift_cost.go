@@ -0,0 +1,39 @@
+package response
+
+import (
+	"github.com/guregu/null/v6"
+)
+
+// ShiftCostTotals adds up the labour cost of assigned workers and of the slots that are
+// still open. Hours are net working hours, so unpaid breaks are not paid. Slots without
+// any pay rate are counted in UnpricedSlots and add nothing to the cost.
+type ShiftCostTotals struct {
+	AssignedSlots int     `json:"assigned_slots"`
+	OpenSlots     int     `json:"open_slots"`
+	UnpricedSlots int     `json:"unpriced_slots"`
+	AssignedHours float64 `json:"assigned_hours"`
+	OpenHours     float64 `json:"open_hours"`
+	AssignedCost  float64 `json:"assigned_cost"`
+	OpenCost      float64 `json:"open_cost"`
+	TotalCost     float64 `json:"total_cost"`
+}
+
+type ShiftCostByRole struct {
+	RoleID   int64  `json:"role_id"`
+	RoleName string `json:"role_name"`
+	ShiftCostTotals
+}
+
+type ShiftCostByLocation struct {
+	LocationID   null.Int    `json:"location_id"`
+	LocationName null.String `json:"location_name"`
+	ShiftCostTotals
+}
+
+type ShiftCostReport struct {
+	StartDate  string                `json:"start_date"`
+	EndDate    string                `json:"end_date"`
+	Total      ShiftCostTotals       `json:"total"`
+	ByRole     []ShiftCostByRole     `json:"by_role"`
+	ByLocation []ShiftCostByLocation `json:"by_location"`
+}
@@ -0,0 +1,18 @@
+package response
+
+type ImportShiftRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ImportShiftResult reports the outcome of a bulk import. Shifts are only created when
+// the file has no row errors and the import is not a dry run.
+type ImportShiftResult struct {
+	DryRun          bool                  `json:"dry_run"`
+	TotalRows       int                   `json:"total_rows"`
+	ValidRows       int                   `json:"valid_rows"`
+	ImportedCount   int                   `json:"imported_count"`
+	CreatedShiftIDs []int                 `json:"created_shift_ids"`
+	Errors          []ImportShiftRowError `json:"errors"`
+}
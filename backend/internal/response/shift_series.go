@@ -0,0 +1,18 @@
+package response
+
+import "github.com/andibalo/payd-test/backend/internal/model"
+
+type GetShiftSeriesListResponse struct {
+	Data []model.ShiftSeries `json:"shift_series"`
+	Meta PaginationMeta      `json:"meta"`
+}
+
+// ShiftSeriesChangeResult reports which concrete shifts were touched by a series operation.
+// KeptShiftIDs are occurrences left untouched because workers are already assigned to them.
+type ShiftSeriesChangeResult struct {
+	SeriesID          int64 `json:"series_id"`
+	CreatedShiftIDs   []int `json:"created_shift_ids"`
+	UpdatedShiftIDs   []int `json:"updated_shift_ids"`
+	CancelledShiftIDs []int `json:"cancelled_shift_ids"`
+	KeptShiftIDs      []int `json:"kept_shift_ids"`
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/constants"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/andibalo/payd-test/backend/internal/repository"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+type auditLogService struct {
+	cfg          config.Config
+	auditLogRepo repository.AuditLogRepository
+}
+
+func NewAuditLogService(cfg config.Config, auditLogRepo repository.AuditLogRepository) AuditLogService {
+
+	return &auditLogService{
+		cfg:          cfg,
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+func (s *auditLogService) GetAuditLogList(ctx context.Context, req request.GetAuditLogListReq) (resp response.GetAuditLogListResponse, err error) {
+
+	if req.Limit > constants.MAX_AUDIT_LOG_LIMIT {
+		req.Limit = constants.MAX_AUDIT_LOG_LIMIT
+	}
+
+	filter := repository.GetAuditLogListFilter{
+		EntityType: req.EntityType,
+		EntityID:   req.EntityID,
+		Actor:      req.Actor,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+	}
+
+	filter.From, err = parseAuditTime(ctx, s.cfg, "from", req.From)
+	if err != nil {
+		return resp, err
+	}
+
+	filter.To, err = parseAuditTime(ctx, s.cfg, "to", req.To)
+	if err != nil {
+		return resp, err
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetAuditLogList] Time range is empty", zap.String("from", req.From), zap.String("to", req.To))
+		return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("to should be after from")
+	}
+
+	filter.After, err = parseListCursor(ctx, s.cfg, req.Cursor, "GetAuditLogList")
+	if err != nil {
+		return resp, err
+	}
+
+	auditLogs, pagination, err := s.auditLogRepo.GetList(filter)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetAuditLogList] Failed to get audit log list", zap.Any("filter", filter), zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get audit log list")
+	}
+
+	resp.Data = make([]response.AuditLogData, 0, len(auditLogs))
+	for _, auditLog := range auditLogs {
+		resp.Data = append(resp.Data, response.AuditLogData{
+			ID:         auditLog.ID,
+			EntityType: auditLog.EntityType,
+			EntityID:   auditLog.EntityID,
+			Action:     auditLog.Action,
+			Actor:      auditLog.Actor,
+			Changes:    json.RawMessage(auditLog.Changes),
+			CreatedAt:  auditLog.CreatedAt,
+		})
+	}
+
+	resp.Meta = response.PaginationMeta{
+		CurrentPage: pagination.CurrentPage,
+		TotalPages:  pagination.TotalPages,
+		TotalItems:  pagination.TotalElements,
+		CursorStart: pagination.CursorStart,
+		CursorEnd:   pagination.CursorEnd,
+	}
+
+	return resp, nil
+}
+
+func parseAuditTime(ctx context.Context, cfg config.Config, key, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		cfg.Logger().ErrorWithContext(ctx, "[GetAuditLogList] Invalid time", zap.String(key, value), zap.Error(err))
+		return nil, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("%s should be an RFC 3339 time", key)
+	}
+
+	t = t.UTC()
+
+	return &t, nil
+}
+
+// auditIgnoredFields are left out of diffs. The bookkeeping columns are already covered by
+// the entry's actor and timestamp, and secrets must never reach the audit trail.
+var auditIgnoredFields = map[string]bool{
+	"version":    true,
+	"created_at": true,
+	"created_by": true,
+	"updated_at": true,
+	"updated_by": true,
+	"deleted_at": true,
+	"deleted_by": true,
+	"password":   true,
+}
+
+// saveAuditLog appends a change to the audit trail. before is nil for created entities and
+// after is nil for deleted ones; only the fields that differ between the two are kept.
+func saveAuditLog(auditLogRepo repository.AuditLogRepository, entityType string, entityID int64, action, actor string, before, after interface{}) error {
+	changes, err := auditChanges(before, after)
+	if err != nil {
+		return err
+	}
+
+	return auditLogRepo.Save(&model.AuditLog{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Actor:      actor,
+		Changes:    changes,
+	})
+}
+
+// auditChanges diffs the JSON form of two snapshots of an entity, so the trail records
+// exactly what an API client would have seen change.
+func auditChanges(before, after interface{}) (string, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return "", err
+	}
+
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return "", err
+	}
+
+	changes := map[string]model.AuditChange{}
+
+	// a missing snapshot reads as null for every field, so only set values are recorded
+	for field, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[field]) {
+			changes[field] = model.AuditChange{Before: value, After: afterFields[field]}
+		}
+	}
+
+	for field, value := range afterFields {
+		if _, ok := beforeFields[field]; !ok && value != nil {
+			changes[field] = model.AuditChange{Before: nil, After: value}
+		}
+	}
+
+	for field := range auditIgnoredFields {
+		delete(changes, field)
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func auditFields(snapshot interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+
+	if snapshot == nil || reflect.ValueOf(snapshot).Kind() == reflect.Ptr && reflect.ValueOf(snapshot).IsNil() {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
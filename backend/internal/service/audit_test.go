@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+)
+
+type auditedEntity struct {
+	Name     string  `json:"name"`
+	Note     *string `json:"note"`
+	Password string  `json:"password"`
+	Version  int     `json:"version"`
+}
+
+func TestAuditChanges(t *testing.T) {
+	note := "night cover"
+
+	tests := []struct {
+		name   string
+		before interface{}
+		after  interface{}
+		want   string
+	}{
+		{
+			name:   "created entity records set fields only",
+			before: nil,
+			after:  &auditedEntity{Name: "Alice", Version: 1},
+			want:   `{"name":{"before":null,"after":"Alice"}}`,
+		},
+		{
+			name:   "updated entity records changed fields only",
+			before: &auditedEntity{Name: "Alice", Version: 1},
+			after:  &auditedEntity{Name: "Alice", Note: &note, Version: 2},
+			want:   `{"note":{"before":null,"after":"night cover"}}`,
+		},
+		{
+			name:   "deleted entity given as a typed nil",
+			before: &auditedEntity{Name: "Alice", Note: &note},
+			after:  (*auditedEntity)(nil),
+			want:   `{"name":{"before":"Alice","after":null},"note":{"before":"night cover","after":null}}`,
+		},
+		{
+			name:   "passwords never reach the trail",
+			before: &auditedEntity{Name: "Alice", Password: "old"},
+			after:  &auditedEntity{Name: "Alice", Password: "new"},
+			want:   `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := auditChanges(tt.before, tt.after)
+			if err != nil {
+				t.Fatalf("auditChanges returned error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("auditChanges() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -19,15 +19,19 @@ import (
 )
 
 type authService struct {
-	cfg      config.Config
-	userRepo repository.UserRepository
+	cfg          config.Config
+	transactor   repository.Transactor
+	userRepo     repository.UserRepository
+	auditLogRepo repository.AuditLogRepository
 }
 
-func NewAuthService(cfg config.Config, userRepo repository.UserRepository) AuthService {
+func NewAuthService(cfg config.Config, transactor repository.Transactor, userRepo repository.UserRepository, auditLogRepo repository.AuditLogRepository) AuthService {
 
 	return &authService{
-		cfg:      cfg,
-		userRepo: userRepo,
+		cfg:          cfg,
+		transactor:   transactor,
+		userRepo:     userRepo,
+		auditLogRepo: auditLogRepo,
 	}
 }
 
@@ -52,14 +56,21 @@ func (s *authService) Register(ctx context.Context, req request.RegisterUserReq)
 		return "", oops.Wrapf(err, "[Register] Failed to map payload to user model")
 	}
 
-	err = s.userRepo.Save(user)
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		err := s.userRepo.WithTx(tx).Save(user)
+		if err != nil {
+			return err
+		}
+
+		return saveAuditLog(s.auditLogRepo.WithTx(tx), constants.AUDIT_ENTITY_USER, user.ID, constants.AUDIT_ACTION_CREATE, user.Email, nil, user)
+	})
 	if err != nil {
 		s.cfg.Logger().ErrorWithContext(ctx, "[Register] Failed to insert user to database", zap.Error(err))
 
 		return "", oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf(apperr.ErrInternalServerError)
 	}
 
-	token, err = pkg.GenerateToken(existingUser)
+	token, err = pkg.GenerateToken(user)
 	if err != nil {
 		s.cfg.Logger().ErrorWithContext(ctx, "[Register] Failed to generate JWT Token for user", zap.String("email", req.Email))
 		return "", oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf(apperr.ErrInternalServerError)
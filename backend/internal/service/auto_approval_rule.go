@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/andibalo/payd-test/backend/internal/repository"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type autoApprovalRuleService struct {
+	cfg                  config.Config
+	autoApprovalRuleRepo repository.AutoApprovalRuleRepository
+	shiftRoleRepo        repository.ShiftRoleRepository
+	locationRepo         repository.LocationRepository
+}
+
+func NewAutoApprovalRuleService(cfg config.Config, autoApprovalRuleRepo repository.AutoApprovalRuleRepository, shiftRoleRepo repository.ShiftRoleRepository, locationRepo repository.LocationRepository) AutoApprovalRuleService {
+
+	return &autoApprovalRuleService{
+		cfg:                  cfg,
+		autoApprovalRuleRepo: autoApprovalRuleRepo,
+		shiftRoleRepo:        shiftRoleRepo,
+		locationRepo:         locationRepo,
+	}
+}
+
+func (s *autoApprovalRuleService) GetAutoApprovalRuleList(ctx context.Context, req request.GetAutoApprovalRuleListReq) ([]model.AutoApprovalRule, error) {
+
+	filter := repository.GetAutoApprovalRuleListFilter{
+		ActiveOnly: req.ActiveOnly,
+	}
+
+	rules, err := s.autoApprovalRuleRepo.GetList(filter)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetAutoApprovalRuleList] Failed to get auto-approval rule list", zap.Any("filter", filter), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get auto-approval rule list")
+	}
+
+	return rules, nil
+}
+
+func (s *autoApprovalRuleService) GetAutoApprovalRuleByID(ctx context.Context, id int64) (*model.AutoApprovalRule, error) {
+
+	return s.getAutoApprovalRule(ctx, id, "GetAutoApprovalRuleByID")
+}
+
+func (s *autoApprovalRuleService) CreateAutoApprovalRule(ctx context.Context, req request.CreateAutoApprovalRuleReq) (*model.AutoApprovalRule, error) {
+
+	rule := req.ToModel()
+
+	err := s.validateAutoApprovalRule(ctx, rule, "CreateAutoApprovalRule")
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.autoApprovalRuleRepo.Save(rule)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CreateAutoApprovalRule] Failed to create auto-approval rule", zap.String("name", rule.Name), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to create auto-approval rule")
+	}
+
+	return s.getAutoApprovalRule(ctx, rule.ID, "CreateAutoApprovalRule")
+}
+
+func (s *autoApprovalRuleService) UpdateAutoApprovalRuleByID(ctx context.Context, id int64, req request.UpdateAutoApprovalRuleReq) (*model.AutoApprovalRule, error) {
+
+	_, err := s.getAutoApprovalRule(ctx, id, "UpdateAutoApprovalRuleByID")
+	if err != nil {
+		return nil, err
+	}
+
+	rule := req.ToModel()
+
+	err = s.validateAutoApprovalRule(ctx, rule, "UpdateAutoApprovalRuleByID")
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.autoApprovalRuleRepo.UpdateByID(id, rule)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[UpdateAutoApprovalRuleByID] Failed to update auto-approval rule", zap.Int64("id", id), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to update auto-approval rule")
+	}
+
+	return s.getAutoApprovalRule(ctx, id, "UpdateAutoApprovalRuleByID")
+}
+
+func (s *autoApprovalRuleService) DeleteAutoApprovalRuleByID(ctx context.Context, id int64, deletedBy string) error {
+
+	_, err := s.getAutoApprovalRule(ctx, id, "DeleteAutoApprovalRuleByID")
+	if err != nil {
+		return err
+	}
+
+	err = s.autoApprovalRuleRepo.DeleteByID(id, deletedBy)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[DeleteAutoApprovalRuleByID] Failed to delete auto-approval rule", zap.Int64("id", id), zap.String("deletedBy", deletedBy), zap.Error(err))
+		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to delete auto-approval rule")
+	}
+
+	return nil
+}
+
+func (s *autoApprovalRuleService) getAutoApprovalRule(ctx context.Context, id int64, caller string) (*model.AutoApprovalRule, error) {
+	rule, err := s.autoApprovalRuleRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Auto-approval rule not found", zap.Int64("id", id))
+			return nil, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Auto-approval rule not found")
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to get auto-approval rule by id", zap.Int64("id", id), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get auto-approval rule by id")
+	}
+
+	return rule, nil
+}
+
+// validateAutoApprovalRule checks the role and location the rule points at exist, and that
+// the rule has at least one condition, so it can never approve every request.
+func (s *autoApprovalRuleService) validateAutoApprovalRule(ctx context.Context, rule *model.AutoApprovalRule, caller string) error {
+	if rule.Name == "" {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Auto-approval rule name is empty")
+		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Auto-approval rule name is required")
+	}
+
+	if !rule.RoleID.Valid && !rule.LocationID.Valid && rule.MinCompletedShifts == 0 && !rule.MaxHoursBeforeStart.Valid {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Auto-approval rule has no conditions", zap.String("name", rule.Name))
+		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Auto-approval rule needs at least one condition")
+	}
+
+	if rule.RoleID.Valid {
+		err := ensureShiftRoleExists(ctx, s.cfg, s.shiftRoleRepo, int(rule.RoleID.Int64), caller)
+		if err != nil {
+			return err
+		}
+	}
+
+	if rule.LocationID.Valid {
+		_, err := ensureLocationExists(ctx, s.cfg, s.locationRepo, rule.LocationID.Int64, caller)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/constants"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/andibalo/payd-test/backend/internal/repository"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/guregu/null/v6"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type eventService struct {
+	cfg       config.Config
+	eventRepo repository.EventRepository
+}
+
+func NewEventService(cfg config.Config, eventRepo repository.EventRepository) EventService {
+
+	return &eventService{
+		cfg:       cfg,
+		eventRepo: eventRepo,
+	}
+}
+
+func (s *eventService) GetEventList(ctx context.Context, req request.GetEventListReq) ([]response.EventData, error) {
+
+	filter := repository.GetEventListFilter{
+		UserID: req.UserID,
+		Limit:  req.Limit,
+	}
+
+	events, err := s.eventRepo.GetList(filter)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetEventList] Failed to get event list", zap.Any("filter", filter), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get event list")
+	}
+
+	resp := make([]response.EventData, 0, len(events))
+	for _, event := range events {
+		resp = append(resp, response.EventData{
+			ID:        event.ID,
+			EventType: event.EventType,
+			UserID:    event.UserID,
+			ShiftID:   event.ShiftID,
+			Payload:   json.RawMessage(event.Payload),
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+// saveShiftRemovedEvent writes an outbox event telling a worker that their request for or
+// assignment to shift was undone.
+func saveShiftRemovedEvent(eventRepo repository.EventRepository, eventType string, userID int64, shift *model.Shift, affected, reason, actor string) error {
+	payload, err := json.Marshal(model.ShiftRemovedPayload{
+		ShiftID:   shift.ID,
+		StartTime: shift.StartTime,
+		EndTime:   shift.EndTime,
+		Timezone:  shift.Timezone,
+		Affected:  affected,
+		Reason:    reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	return eventRepo.Save(&model.Event{
+		EventType: eventType,
+		UserID:    userID,
+		ShiftID:   null.IntFrom(int64(shift.ID)),
+		Payload:   string(payload),
+		CreatedBy: actor,
+	})
+}
+
+// saveShiftRestoredEvent writes an outbox event telling a worker that their request for or
+// assignment to a deleted shift came back with the shift.
+func saveShiftRestoredEvent(eventRepo repository.EventRepository, userID int64, shift *model.Shift, restored, actor string) error {
+	payload, err := json.Marshal(model.ShiftRestoredPayload{
+		ShiftID:   shift.ID,
+		StartTime: shift.StartTime,
+		EndTime:   shift.EndTime,
+		Timezone:  shift.Timezone,
+		Restored:  restored,
+	})
+	if err != nil {
+		return err
+	}
+
+	return eventRepo.Save(&model.Event{
+		EventType: constants.EVENT_TYPE_SHIFT_RESTORED,
+		UserID:    userID,
+		ShiftID:   null.IntFrom(int64(shift.ID)),
+		Payload:   string(payload),
+		CreatedBy: actor,
+	})
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/constants"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/andibalo/payd-test/backend/internal/repository"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+type locationService struct {
+	cfg          config.Config
+	locationRepo repository.LocationRepository
+}
+
+func NewLocationService(cfg config.Config, locationRepo repository.LocationRepository) LocationService {
+
+	return &locationService{
+		cfg:          cfg,
+		locationRepo: locationRepo,
+	}
+}
+
+func (s *locationService) GetLocationList(ctx context.Context, req request.GetLocationListReq) ([]model.Location, error) {
+
+	filter := repository.GetLocationListFilter{
+		IncludeDeleted: req.IncludeDeleted,
+	}
+
+	locations, err := s.locationRepo.GetList(filter)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetLocationList] Failed to get location list", zap.Any("filter", filter), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get location list")
+	}
+
+	return locations, nil
+}
+
+func (s *locationService) GetLocationByID(ctx context.Context, id int64) (*model.Location, error) {
+
+	location, err := s.getLocation(ctx, id, "GetLocationByID")
+	if err != nil {
+		return nil, err
+	}
+
+	if location.DeletedAt.Valid {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetLocationByID] Location is deleted", zap.Int64("id", id))
+		return nil, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Location not found")
+	}
+
+	return location, nil
+}
+
+func (s *locationService) CreateLocation(ctx context.Context, req request.CreateLocationReq) (*model.Location, error) {
+
+	location := req.ToModel()
+
+	err := s.validateLocation(ctx, location, 0, "CreateLocation")
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.locationRepo.Save(location)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CreateLocation] Failed to create location", zap.String("name", location.Name), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to create location")
+	}
+
+	return s.getLocation(ctx, location.ID, "CreateLocation")
+}
+
+func (s *locationService) UpdateLocationByID(ctx context.Context, id int64, req request.UpdateLocationReq) (*model.Location, error) {
+
+	_, err := s.GetLocationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	location := req.ToModel()
+
+	err = s.validateLocation(ctx, location, id, "UpdateLocationByID")
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.locationRepo.UpdateByID(id, location)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[UpdateLocationByID] Failed to update location", zap.Int64("id", id), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to update location")
+	}
+
+	return s.getLocation(ctx, id, "UpdateLocationByID")
+}
+
+func (s *locationService) DeleteLocationByID(ctx context.Context, id int64, deletedBy string) error {
+
+	_, err := s.GetLocationByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	activeShiftCount, err := s.locationRepo.CountActiveShiftsByLocationID(id)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[DeleteLocationByID] Failed to count active shifts at location", zap.Int64("id", id), zap.Error(err))
+		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to count active shifts at location")
+	}
+
+	if activeShiftCount > 0 {
+		s.cfg.Logger().ErrorWithContext(ctx, "[DeleteLocationByID] Location still has active shifts", zap.Int64("id", id), zap.Int("activeShiftCount", activeShiftCount))
+		return oops.Code(response.Conflict.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusConflict).Errorf("Location still has %d active shifts", activeShiftCount)
+	}
+
+	err = s.locationRepo.DeleteByID(id, deletedBy)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[DeleteLocationByID] Failed to delete location", zap.Int64("id", id), zap.String("deletedBy", deletedBy), zap.Error(err))
+		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to delete location")
+	}
+
+	return nil
+}
+
+func (s *locationService) getLocation(ctx context.Context, id int64, caller string) (*model.Location, error) {
+	location, err := s.locationRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Location not found", zap.Int64("id", id))
+			return nil, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Location not found")
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to get location by id", zap.Int64("id", id), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get location by id")
+	}
+
+	return location, nil
+}
+
+// validateLocation checks the timezone is a known IANA zone and that the name does not
+// collide case-insensitively with another location, deleted ones included.
+func (s *locationService) validateLocation(ctx context.Context, location *model.Location, excludeID int64, caller string) error {
+	if location.Name == "" {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Location name is empty")
+		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Location name is required")
+	}
+
+	_, err := time.LoadLocation(location.Timezone)
+	if err != nil || location.Timezone == "Local" {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Invalid timezone", zap.String("timezone", location.Timezone), zap.Error(err))
+		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Invalid timezone %q, expected an IANA name such as Asia/Jakarta", location.Timezone)
+	}
+
+	existing, err := s.locationRepo.GetByName(location.Name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to get location by name", zap.String("name", location.Name), zap.Error(err))
+		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get location by name")
+	}
+
+	if existing.ID == excludeID {
+		return nil
+	}
+
+	s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Location name already exists", zap.String("name", location.Name), zap.Int64("existingID", existing.ID))
+	return oops.Code(response.Conflict.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusConflict).Errorf("Location %q already exists", existing.Name)
+}
+
+// ensureLocationExists is shared by the services that accept a location_id, so a shift
+// can never point at a location that was never created or has since been deleted.
+func ensureLocationExists(ctx context.Context, cfg config.Config, locationRepo repository.LocationRepository, locationID int64, caller string) (*model.Location, error) {
+	location, err := locationRepo.GetByID(locationID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Location not found", zap.Int64("locationID", locationID))
+			return nil, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Location %d does not exist", locationID)
+		}
+
+		cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to get location by id", zap.Int64("locationID", locationID), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get location by id")
+	}
+
+	if location.DeletedAt.Valid {
+		cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Location is deleted", zap.Int64("locationID", locationID))
+		return nil, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Location %d has been deleted", locationID)
+	}
+
+	return location, nil
+}
+
+// resolveShiftTimezone picks the zone a shift's local day is counted in. A shift at a
+// location always uses the location's timezone; otherwise the given timezone or UTC.
+func resolveShiftTimezone(ctx context.Context, cfg config.Config, locationRepo repository.LocationRepository, locationID int64, timezone string, caller string) (*time.Location, error) {
+	if locationID != 0 {
+		location, err := ensureLocationExists(ctx, cfg, locationRepo, locationID, caller)
+		if err != nil {
+			return nil, err
+		}
+
+		if timezone != "" && timezone != location.Timezone {
+			cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Timezone does not match location", zap.String("timezone", timezone), zap.String("locationTimezone", location.Timezone))
+			return nil, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Timezone %q does not match the location timezone %q", timezone, location.Timezone)
+		}
+
+		timezone = location.Timezone
+	}
+
+	if timezone == "" {
+		timezone = constants.DEFAULT_LOCATION_TIMEZONE
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "Local" {
+		cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Invalid timezone", zap.String("timezone", timezone), zap.Error(err))
+		return nil, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Invalid timezone %q, expected an IANA name such as Asia/Jakarta", timezone)
+	}
+
+	return loc, nil
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/repository"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"go.uber.org/zap"
+	"time"
+)
+
+type retentionService struct {
+	cfg           config.Config
+	retentionRepo repository.RetentionRepository
+}
+
+func NewRetentionService(cfg config.Config, retentionRepo repository.RetentionRepository) RetentionService {
+
+	return &retentionService{
+		cfg:           cfg,
+		retentionRepo: retentionRepo,
+	}
+}
+
+// RunRetention purges rows soft-deleted longer ago than the configured number of days, then
+// moves completed shifts older than the configured number of months to the archive file.
+func (s *retentionService) RunRetention(ctx context.Context) (resp response.RetentionResult, err error) {
+	retentionCfg := s.cfg.GetRetentionCfg()
+
+	resp.RanAt = time.Now().UTC()
+	resp.DeletedBefore = resp.RanAt.AddDate(0, 0, -retentionCfg.DeletedDays)
+	resp.EndedBefore = resp.RanAt.AddDate(0, -retentionCfg.ArchiveMonths, 0)
+	resp.ArchivePath = retentionCfg.ArchivePath
+
+	resp.Purged, err = s.retentionRepo.PurgeDeleted(resp.DeletedBefore)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[RunRetention] Failed to purge deleted rows", zap.Time("deletedBefore", resp.DeletedBefore), zap.Error(err))
+		return resp, err
+	}
+
+	resp.Archived, err = s.retentionRepo.ArchiveCompletedShifts(retentionCfg.ArchivePath, resp.EndedBefore)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[RunRetention] Failed to archive completed shifts", zap.Time("endedBefore", resp.EndedBefore), zap.String("archivePath", retentionCfg.ArchivePath), zap.Error(err))
+		return resp, err
+	}
+
+	s.cfg.Logger().InfoWithContext(ctx, "[RunRetention] Retention run finished", zap.Any("purged", resp.Purged), zap.Any("archived", resp.Archived))
+
+	return resp, nil
+}
+
+// StartRetentionJob runs the retention once straight away and then on the configured
+// interval until ctx is done. A failed run is logged and retried on the next tick.
+func (s *retentionService) StartRetentionJob(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.cfg.GetRetentionCfg().IntervalHours) * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		_, _ = s.RunRetention(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 type UserService interface {
 	GetWorkerList(ctx context.Context, req request.GetWorkerListReq) ([]model.User, error)
+	SetWorkerPayRate(ctx context.Context, req request.SetWorkerPayRateReq) (*model.User, error)
 }
 
 type AuthService interface {
@@ -20,11 +21,92 @@ type ShiftService interface {
 	CreateShift(ctx context.Context, req request.CreateShiftReq) error
 	GetShiftByID(ctx context.Context, id int64) (*model.Shift, error)
 	GetShiftList(ctx context.Context, req request.GetShiftListReq) (resp response.GetShiftListResponse, err error)
-	UpdateShiftByID(ctx context.Context, id int64, req request.UpdateShiftReq) error
+	UpdateShiftByID(ctx context.Context, id int64, req request.UpdateShiftReq) (*model.Shift, error)
 	DeleteShiftByID(ctx context.Context, id int64, deletedBy string) error
+	RestoreShiftByID(ctx context.Context, req request.RestoreShiftReq) (response.RestoreShiftResult, error)
 	CreateShiftRequest(ctx context.Context, req request.CreateShiftRequestReq) error
-	ApproveShiftRequest(ctx context.Context, req request.ApproveShiftRequestReq) error
-	RejectShiftRequest(ctx context.Context, req request.RejectShiftRequestReq) error
+	ApproveShiftRequest(ctx context.Context, req request.ApproveShiftRequestReq) (*model.ShiftRequest, error)
+	RejectShiftRequest(ctx context.Context, req request.RejectShiftRequestReq) (*model.ShiftRequest, error)
+	WithdrawShiftRequest(ctx context.Context, req request.WithdrawShiftRequestReq) (*model.ShiftRequest, error)
+	BulkApproveShiftRequests(ctx context.Context, req request.BulkShiftRequestDecisionReq) (response.BulkShiftRequestResult, error)
+	BulkRejectShiftRequests(ctx context.Context, req request.BulkShiftRequestDecisionReq) (response.BulkShiftRequestResult, error)
 	GetShiftRequestList(ctx context.Context, req request.GetShiftRequestListReq) (resp response.GetShiftRequestListResponse, err error)
+	GetShiftRequestQueue(ctx context.Context, shiftID int64) (response.ShiftRequestQueue, error)
 	GetShiftAssignmentList(ctx context.Context, req request.GetShiftAssignmentListReq) (resp response.GetShiftAssignmentListResponse, err error)
+	ReleaseShiftAssignment(ctx context.Context, req request.ReleaseShiftAssignmentReq) (response.ReleaseShiftAssignmentResult, error)
+	ImportShifts(ctx context.Context, req request.ImportShiftReq) (resp response.ImportShiftResult, err error)
+	CopyShiftRoster(ctx context.Context, req request.CopyShiftRosterReq) (resp response.CopyShiftRosterResult, err error)
+	PublishShifts(ctx context.Context, req request.PublishShiftsReq) (resp response.PublishShiftsResult, err error)
+	GetShiftCost(ctx context.Context, req request.GetShiftCostReq) (resp response.ShiftCostReport, err error)
+	CompleteShiftByID(ctx context.Context, id int64, completedBy string) error
+	CancelShiftByID(ctx context.Context, req request.CancelShiftReq) (resp response.CancelShiftResult, err error)
+	ExpireShiftRequests(ctx context.Context) ([]model.ShiftRequest, error)
+	StartShiftRequestExpiryJob(ctx context.Context)
+}
+
+type ShiftSwapService interface {
+	CreateShiftSwap(ctx context.Context, req request.CreateShiftSwapReq) (*model.ShiftSwap, error)
+	GetShiftSwapList(ctx context.Context, req request.GetShiftSwapListReq) ([]model.ShiftSwap, error)
+	GetShiftSwapByID(ctx context.Context, id int64, userID int64) (*model.ShiftSwap, error)
+	AcceptShiftSwap(ctx context.Context, req request.ShiftSwapActionReq) (*model.ShiftSwap, error)
+	DeclineShiftSwap(ctx context.Context, req request.ShiftSwapActionReq) (*model.ShiftSwap, error)
+	CancelShiftSwap(ctx context.Context, req request.ShiftSwapActionReq) (*model.ShiftSwap, error)
+	ApproveShiftSwap(ctx context.Context, req request.ShiftSwapActionReq) (*model.ShiftSwap, error)
+	RejectShiftSwap(ctx context.Context, req request.RejectShiftSwapReq) (*model.ShiftSwap, error)
+}
+
+type ShiftSeriesService interface {
+	CreateShiftSeries(ctx context.Context, req request.CreateShiftSeriesReq) (resp response.ShiftSeriesChangeResult, err error)
+	GetShiftSeriesByID(ctx context.Context, id int64) (*model.ShiftSeries, error)
+	GetShiftSeriesList(ctx context.Context, req request.GetShiftSeriesListReq) (resp response.GetShiftSeriesListResponse, err error)
+	UpdateShiftSeries(ctx context.Context, id int64, req request.UpdateShiftSeriesReq) (resp response.ShiftSeriesChangeResult, err error)
+	CancelShiftSeries(ctx context.Context, id int64, req request.CancelShiftSeriesReq) (resp response.ShiftSeriesChangeResult, err error)
+	GenerateShiftSeries(ctx context.Context, id int64, generatedBy string) (resp response.ShiftSeriesChangeResult, err error)
+}
+
+type ShiftRoleService interface {
+	GetShiftRoleList(ctx context.Context, req request.GetShiftRoleListReq) ([]model.ShiftRoleEnum, error)
+	CreateShiftRole(ctx context.Context, req request.CreateShiftRoleReq) (*model.ShiftRoleEnum, error)
+	UpdateShiftRoleByID(ctx context.Context, id int64, req request.UpdateShiftRoleReq) (*model.ShiftRoleEnum, error)
+	DeleteShiftRoleByID(ctx context.Context, id int64, deletedBy string) error
+	RestoreShiftRoleByID(ctx context.Context, id int64, restoredBy string) (*model.ShiftRoleEnum, error)
+}
+
+type LocationService interface {
+	GetLocationList(ctx context.Context, req request.GetLocationListReq) ([]model.Location, error)
+	GetLocationByID(ctx context.Context, id int64) (*model.Location, error)
+	CreateLocation(ctx context.Context, req request.CreateLocationReq) (*model.Location, error)
+	UpdateLocationByID(ctx context.Context, id int64, req request.UpdateLocationReq) (*model.Location, error)
+	DeleteLocationByID(ctx context.Context, id int64, deletedBy string) error
+}
+
+type SkillService interface {
+	GetSkillList(ctx context.Context, req request.GetSkillListReq) ([]model.Skill, error)
+	CreateSkill(ctx context.Context, req request.CreateSkillReq) (*model.Skill, error)
+	UpdateSkillByID(ctx context.Context, id int64, req request.UpdateSkillReq) (*model.Skill, error)
+	DeleteSkillByID(ctx context.Context, id int64, deletedBy string) error
+	GetWorkerSkills(ctx context.Context, userID int64) ([]model.WorkerSkill, error)
+	AssignWorkerSkill(ctx context.Context, req request.AssignWorkerSkillReq) ([]model.WorkerSkill, error)
+	RemoveWorkerSkill(ctx context.Context, userID, skillID int64, deletedBy string) error
+}
+
+type AutoApprovalRuleService interface {
+	GetAutoApprovalRuleList(ctx context.Context, req request.GetAutoApprovalRuleListReq) ([]model.AutoApprovalRule, error)
+	GetAutoApprovalRuleByID(ctx context.Context, id int64) (*model.AutoApprovalRule, error)
+	CreateAutoApprovalRule(ctx context.Context, req request.CreateAutoApprovalRuleReq) (*model.AutoApprovalRule, error)
+	UpdateAutoApprovalRuleByID(ctx context.Context, id int64, req request.UpdateAutoApprovalRuleReq) (*model.AutoApprovalRule, error)
+	DeleteAutoApprovalRuleByID(ctx context.Context, id int64, deletedBy string) error
+}
+
+type AuditLogService interface {
+	GetAuditLogList(ctx context.Context, req request.GetAuditLogListReq) (response.GetAuditLogListResponse, error)
+}
+
+type EventService interface {
+	GetEventList(ctx context.Context, req request.GetEventListReq) ([]response.EventData, error)
+}
+
+type RetentionService interface {
+	RunRetention(ctx context.Context) (response.RetentionResult, error)
+	StartRetentionJob(ctx context.Context)
 }
@@ -10,29 +10,101 @@ import (
 	"github.com/andibalo/payd-test/backend/internal/repository"
 	"github.com/andibalo/payd-test/backend/internal/request"
 	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/pkg"
 	"github.com/andibalo/payd-test/backend/pkg/httpresp"
 	"github.com/guregu/null/v6"
 	"github.com/samber/oops"
 	"go.uber.org/zap"
 	"net/http"
+	"sort"
+	"strings"
+	"time"
 )
 
 type shiftService struct {
-	cfg       config.Config
-	shiftRepo repository.ShiftRepository
+	cfg                  config.Config
+	transactor           repository.Transactor
+	shiftRepo            repository.ShiftRepository
+	shiftRoleRepo        repository.ShiftRoleRepository
+	locationRepo         repository.LocationRepository
+	eventRepo            repository.EventRepository
+	skillRepo            repository.SkillRepository
+	auditLogRepo         repository.AuditLogRepository
+	autoApprovalRuleRepo repository.AutoApprovalRuleRepository
 }
 
-func NewShiftService(cfg config.Config, shiftRepo repository.ShiftRepository) ShiftService {
+func NewShiftService(cfg config.Config, transactor repository.Transactor, shiftRepo repository.ShiftRepository, shiftRoleRepo repository.ShiftRoleRepository, locationRepo repository.LocationRepository, eventRepo repository.EventRepository, skillRepo repository.SkillRepository, auditLogRepo repository.AuditLogRepository, autoApprovalRuleRepo repository.AutoApprovalRuleRepository) ShiftService {
 
 	return &shiftService{
-		cfg:       cfg,
-		shiftRepo: shiftRepo,
+		cfg:                  cfg,
+		transactor:           transactor,
+		shiftRepo:            shiftRepo,
+		shiftRoleRepo:        shiftRoleRepo,
+		locationRepo:         locationRepo,
+		eventRepo:            eventRepo,
+		skillRepo:            skillRepo,
+		auditLogRepo:         auditLogRepo,
+		autoApprovalRuleRepo: autoApprovalRuleRepo,
 	}
 }
 
 func (s *shiftService) CreateShift(ctx context.Context, req request.CreateShiftReq) error {
 
-	err := s.shiftRepo.Save(req.ToModel())
+	err := ensureShiftRoleExists(ctx, s.cfg, s.shiftRoleRepo, req.RoleID, "CreateShift")
+	if err != nil {
+		return err
+	}
+
+	err = validateShiftWindow(req.StartTime, req.EndTime)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShift] Invalid shift time", zap.Time("start", req.StartTime), zap.Time("end", req.EndTime))
+		return err
+	}
+
+	loc, err := resolveShiftTimezone(ctx, s.cfg, s.locationRepo, req.LocationID, req.Timezone, "CreateShift")
+	if err != nil {
+		return err
+	}
+
+	err = ensureSkillsExist(ctx, s.cfg, s.skillRepo, req.RequiredSkillIDs, "CreateShift")
+	if err != nil {
+		return err
+	}
+
+	shift := req.ToModel()
+	shift.SetSchedule(req.StartTime, req.EndTime, loc)
+
+	err = validateShiftBreaks(shift.StartTime, shift.EndTime, shift.Breaks)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShift] Invalid shift breaks", zap.Error(err))
+		return err
+	}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		shiftRepo := s.shiftRepo.WithTx(tx)
+
+		err := shiftRepo.Save(shift)
+		if err != nil {
+			return err
+		}
+
+		err = shiftRepo.SaveBreaks(int64(shift.ID), shift.Breaks)
+		if err != nil {
+			return err
+		}
+
+		err = shiftRepo.SaveRequiredSkills(int64(shift.ID), shift.RequiredSkillIDs)
+		if err != nil {
+			return err
+		}
+
+		created, err := loadShift(shiftRepo, int64(shift.ID))
+		if err != nil {
+			return err
+		}
+
+		return saveAuditLog(s.auditLogRepo.WithTx(tx), constants.AUDIT_ENTITY_SHIFT, int64(shift.ID), constants.AUDIT_ACTION_CREATE, req.UserEmail, nil, created)
+	})
 	if err != nil {
 		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShift] Failed to create shift", zap.Error(err))
 		return err
@@ -42,11 +114,32 @@ func (s *shiftService) CreateShift(ctx context.Context, req request.CreateShiftR
 }
 
 func (s *shiftService) GetShiftByID(ctx context.Context, id int64) (*model.Shift, error) {
-	shift, err := s.shiftRepo.GetByID(id)
+	shift, err := loadShift(s.shiftRepo, id)
 	if err != nil {
 		s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftByID] Failed to get shift by ID", zap.Int64("id", id), zap.Error(err))
 		return nil, err
 	}
+
+	return shift, nil
+}
+
+// loadShift reads a shift together with its breaks and required skills.
+func loadShift(shiftRepo repository.ShiftRepository, id int64) (*model.Shift, error) {
+	shift, err := shiftRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	shift.Breaks, err = shiftRepo.GetBreaksByShiftID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	shift.RequiredSkillIDs, err = shiftRepo.GetRequiredSkillIDs(id)
+	if err != nil {
+		return nil, err
+	}
+
 	return shift, nil
 }
 
@@ -54,8 +147,46 @@ func (s *shiftService) GetShiftList(ctx context.Context, req request.GetShiftLis
 
 	filter := repository.GetShiftListFilter{
 		ShowOnlyUnassigned: req.ShowOnlyUnassigned,
+		RoleIDs:            req.RoleIDs,
+		LocationID:         req.LocationID,
+		IsActive:           req.IsActive,
+		Status:             req.Status,
+		AssignedUserID:     req.AssignedUserID,
+		SortBy:             req.SortBy,
+		SortDirection:      req.SortDirection,
 		Limit:              req.Limit,
 		Offset:             req.Offset,
+		Deleted:            req.Deleted,
+	}
+
+	if req.Deleted && filter.SortBy == "" {
+		filter.SortBy = constants.SHIFT_LIST_SORT_DELETED_AT
+	}
+
+	filter.After, err = parseListCursor(ctx, s.cfg, req.Cursor, "GetShiftList")
+	if err != nil {
+		return resp, err
+	}
+
+	if req.StartDate != "" {
+		filter.FromDate, err = time.Parse(constants.DATE_FORMAT, req.StartDate)
+		if err != nil {
+			s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftList] Invalid start date", zap.String("start_date", req.StartDate), zap.Error(err))
+			return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("start_date should be formatted as YYYY-MM-DD")
+		}
+	}
+
+	if req.EndDate != "" {
+		filter.ToDate, err = time.Parse(constants.DATE_FORMAT, req.EndDate)
+		if err != nil {
+			s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftList] Invalid end date", zap.String("end_date", req.EndDate), zap.Error(err))
+			return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("end_date should be formatted as YYYY-MM-DD")
+		}
+	}
+
+	if !filter.FromDate.IsZero() && !filter.ToDate.IsZero() && filter.ToDate.Before(filter.FromDate) {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftList] End date is before start date", zap.String("start_date", req.StartDate), zap.String("end_date", req.EndDate))
+		return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("end_date cannot be before start_date")
 	}
 
 	shifts, pagination, err := s.shiftRepo.GetList(filter)
@@ -70,35 +201,184 @@ func (s *shiftService) GetShiftList(ctx context.Context, req request.GetShiftLis
 			CurrentPage: pagination.CurrentPage,
 			TotalPages:  pagination.TotalPages,
 			TotalItems:  pagination.TotalElements,
+			CursorStart: pagination.CursorStart,
+			CursorEnd:   pagination.CursorEnd,
 		},
 	}
 
 	return resp, nil
 }
 
-func (s *shiftService) UpdateShiftByID(ctx context.Context, id int64, req request.UpdateShiftReq) error {
+func (s *shiftService) UpdateShiftByID(ctx context.Context, id int64, req request.UpdateShiftReq) (*model.Shift, error) {
 
-	_, err := s.shiftRepo.GetByID(id)
+	existingShift, err := s.shiftRepo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftByID] Shift not found", zap.Error(err))
-			return oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Shift not found")
+			return nil, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Shift not found")
 		}
 
 		s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftByID] Failed to get shift by id", zap.Error(err))
-		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift by id")
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift by id")
+	}
+
+	if existingShift.Status == constants.SHIFT_STATUS_CANCELLED || existingShift.Status == constants.SHIFT_STATUS_COMPLETED {
+		s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftByID] Shift can no longer be changed", zap.String("status", existingShift.Status))
+		return nil, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Cannot update a %s shift", strings.ToLower(existingShift.Status))
+	}
+
+	if req.Version != 0 && req.Version != existingShift.Version {
+		s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftByID] Shift version does not match If-Match", zap.Int("expected", req.Version), zap.Int("current", existingShift.Version))
+		return nil, s.shiftVersionErr(ctx, id, response.PreconditionFailed, http.StatusPreconditionFailed, "UpdateShiftByID")
+	}
+
+	err = ensureShiftRoleExists(ctx, s.cfg, s.shiftRoleRepo, req.RoleID, "UpdateShiftByID")
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateShiftWindow(req.StartTime, req.EndTime)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftByID] Invalid shift time", zap.Time("start", req.StartTime), zap.Time("end", req.EndTime))
+		return nil, err
+	}
+
+	loc, err := resolveShiftTimezone(ctx, s.cfg, s.locationRepo, req.LocationID, req.Timezone, "UpdateShiftByID")
+	if err != nil {
+		return nil, err
 	}
 
-	err = s.shiftRepo.UpdateByID(id, req.ToModel())
+	err = ensureSkillsExist(ctx, s.cfg, s.skillRepo, req.RequiredSkillIDs, "UpdateShiftByID")
+	if err != nil {
+		return nil, err
+	}
+
+	shift := req.ToModel()
+	shift.SetSchedule(req.StartTime, req.EndTime, loc)
+	shift.Version = existingShift.Version
+
+	if req.Headcount == nil {
+		shift.Headcount = existingShift.Headcount
+	}
+
+	if req.HourlyRate == nil && !req.ClearHourlyRate {
+		shift.HourlyRate = existingShift.HourlyRate
+	}
+
+	replaceBreaks := shift.Breaks != nil
+	if !replaceBreaks {
+		// The current breaks are kept, so they must still fit the new window.
+		shift.Breaks, err = s.shiftRepo.GetBreaksByShiftID(id)
+		if err != nil {
+			s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftByID] Failed to get shift breaks", zap.Int64("id", id), zap.Error(err))
+			return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift breaks")
+		}
+	}
+
+	err = validateShiftBreaks(shift.StartTime, shift.EndTime, shift.Breaks)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftByID] Invalid shift breaks", zap.Error(err))
+		return nil, err
+	}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		shiftRepo := s.shiftRepo.WithTx(tx)
+
+		before, err := loadShift(shiftRepo, id)
+		if err != nil {
+			return err
+		}
+
+		// approvals do not bump the shift version, so the filled slots are counted here
+		filledSlotCount, err := shiftRepo.GetShiftFilledSlotCount(id)
+		if err != nil {
+			s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftByID] Failed to get shift filled slot count", zap.Error(err))
+			return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift filled slot count")
+		}
+
+		if shift.Headcount < filledSlotCount {
+			s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftByID] Headcount is lower than filled slots", zap.Int("headcount", shift.Headcount), zap.Int("filled", filledSlotCount))
+			return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Headcount cannot be lower than the %d slots already filled", filledSlotCount)
+		}
+
+		err = shiftRepo.UpdateByID(id, shift)
+		if err != nil {
+			return err
+		}
+
+		if replaceBreaks {
+			err = shiftRepo.DeleteBreaksByShiftID(id)
+			if err != nil {
+				return err
+			}
+
+			err = shiftRepo.SaveBreaks(id, shift.Breaks)
+			if err != nil {
+				return err
+			}
+		}
+
+		if shift.RequiredSkillIDs != nil {
+			err = shiftRepo.DeleteRequiredSkillsByShiftID(id)
+			if err != nil {
+				return err
+			}
+
+			err = shiftRepo.SaveRequiredSkills(id, shift.RequiredSkillIDs)
+			if err != nil {
+				return err
+			}
+		}
+
+		after, err := loadShift(shiftRepo, id)
+		if err != nil {
+			return err
+		}
+
+		auditLogRepo := s.auditLogRepo.WithTx(tx)
+		err = saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT, id, constants.AUDIT_ACTION_UPDATE, req.UserEmail, before, after)
+		if err != nil {
+			return err
+		}
+
+		if after.Headcount > before.Headcount {
+			_, err = s.promoteShiftRequestQueue(shiftRepo, auditLogRepo, after)
+			return err
+		}
+
+		return nil
+	})
 	if err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftByID] Shift was modified concurrently", zap.Int64("id", id))
+			return nil, s.shiftVersionErr(ctx, id, response.Conflict, http.StatusConflict, "UpdateShiftByID")
+		}
+
 		s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftByID] Failed to update shift", zap.Int64("id", id), zap.Error(err))
-		return err
+		return nil, err
 	}
-	return nil
+
+	return s.GetShiftByID(ctx, id)
+}
+
+// shiftVersionErr reports a stale shift write and carries the shift as it is now, so the
+// client can reapply its change against the current version.
+func (s *shiftService) shiftVersionErr(ctx context.Context, id int64, code response.Code, statusCode int, caller string) error {
+	current, err := s.GetShiftByID(ctx, id)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to get current shift", zap.Int64("id", id), zap.Error(err))
+		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift by id")
+	}
+
+	return oops.Code(code.AsString()).
+		With(httpresp.StatusCodeCtxKey, statusCode).
+		With(httpresp.CurrentStateCtxKey, current).
+		With(httpresp.ETagCtxKey, pkg.FormatETag(current.Version)).
+		Errorf("Shift was modified by another request, current version is %d", current.Version)
 }
 
 func (s *shiftService) DeleteShiftByID(ctx context.Context, id int64, deletedBy string) error {
-	_, err := s.shiftRepo.GetByID(id)
+	shift, err := loadShift(s.shiftRepo, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			s.cfg.Logger().ErrorWithContext(ctx, "[DeleteShiftByID] Shift not found", zap.Error(err))
@@ -109,7 +389,9 @@ func (s *shiftService) DeleteShiftByID(ctx context.Context, id int64, deletedBy
 		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift by id")
 	}
 
-	err = s.shiftRepo.DeleteByID(id, deletedBy)
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		return deleteShift(s.shiftRepo.WithTx(tx), s.eventRepo.WithTx(tx), s.auditLogRepo.WithTx(tx), shift, deletedBy)
+	})
 	if err != nil {
 		s.cfg.Logger().ErrorWithContext(ctx, "[DeleteShiftByID] Failed to delete shift", zap.Int64("id", id), zap.String("deletedBy", deletedBy), zap.Error(err))
 		return err
@@ -117,6 +399,250 @@ func (s *shiftService) DeleteShiftByID(ctx context.Context, id int64, deletedBy
 	return nil
 }
 
+// deleteShift soft-deletes a shift loaded with loadShift and undoes its requests and
+// assignments through removeShiftCascade, so RestoreShiftByID can bring them back.
+func deleteShift(shiftRepo repository.ShiftRepository, eventRepo repository.EventRepository, auditLogRepo repository.AuditLogRepository, shift *model.Shift, actor string) error {
+	id := int64(shift.ID)
+
+	err := shiftRepo.DeleteByID(id, actor)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := shiftRepo.GetDeletedByID(id)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = removeShiftCascade(shiftRepo, eventRepo, auditLogRepo, shift, deleted.DeletedAt.Time, constants.EVENT_TYPE_SHIFT_DELETED, "", actor)
+	if err != nil {
+		return err
+	}
+
+	return saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT, id, constants.AUDIT_ACTION_DELETE, actor, shift, nil)
+}
+
+// CancelShiftByID cancels a shift together with its pending requests and assignments, and
+// notifies the affected workers through outbox events, all in one transaction.
+func (s *shiftService) CancelShiftByID(ctx context.Context, req request.CancelShiftReq) (resp response.CancelShiftResult, err error) {
+	shift, err := loadShift(s.shiftRepo, req.ShiftID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.cfg.Logger().ErrorWithContext(ctx, "[CancelShiftByID] Shift not found", zap.Error(err))
+			return resp, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Shift not found")
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "[CancelShiftByID] Failed to get shift by id", zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift by id")
+	}
+
+	if !shift.CanTransitionTo(constants.SHIFT_STATUS_CANCELLED) {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CancelShiftByID] Shift cannot be cancelled", zap.String("status", shift.Status))
+		return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Cannot cancel a %s shift", strings.ToLower(shift.Status))
+	}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		shiftRepo := s.shiftRepo.WithTx(tx)
+
+		auditLogRepo := s.auditLogRepo.WithTx(tx)
+
+		err := shiftRepo.CancelByID(req.ShiftID, req.Reason, req.UserEmail)
+		if err != nil {
+			return err
+		}
+
+		cancelled, err := loadShift(shiftRepo, req.ShiftID)
+		if err != nil {
+			return err
+		}
+
+		resp.CancelledRequestUserIDs, resp.EndedAssignmentUserIDs, err = removeShiftCascade(shiftRepo, s.eventRepo.WithTx(tx), auditLogRepo, shift, cancelled.CancelledAt.Time, constants.EVENT_TYPE_SHIFT_CANCELLED, req.Reason, req.UserEmail)
+		if err != nil {
+			return err
+		}
+
+		return saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT, req.ShiftID, constants.AUDIT_ACTION_CANCEL, req.UserEmail, shift, cancelled)
+	})
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CancelShiftByID] Failed to cancel shift", zap.Int64("id", req.ShiftID), zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to cancel shift")
+	}
+
+	return resp, nil
+}
+
+// removeShiftCascade cancels the pending and waitlisted requests and ends the assignments of a shift that is
+// being cancelled or deleted, and writes an event and an audit entry for every affected
+// worker. The affected rows are stamped with at, the time the shift was cancelled or deleted,
+// so RestoreShiftByID can find them again. It returns the workers whose requests were
+// cancelled and whose assignments were ended.
+func removeShiftCascade(shiftRepo repository.ShiftRepository, eventRepo repository.EventRepository, auditLogRepo repository.AuditLogRepository, shift *model.Shift, at time.Time, eventType, reason, actor string) ([]int64, []int64, error) {
+	requestUserIDs := []int64{}
+	for _, status := range []string{constants.SHIFT_REQUEST_STATUS_PENDING, constants.SHIFT_REQUEST_STATUS_WAITLISTED} {
+		shiftRequests, err := shiftRepo.CancelShiftRequestsByShiftID(int64(shift.ID), status, actor, at)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, shiftRequest := range shiftRequests {
+			err := saveShiftRemovedEvent(eventRepo, eventType, shiftRequest.UserID, shift, constants.EVENT_AFFECTED_REQUEST, reason, actor)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			// the update only moved the request out of status and recorded the actor
+			before := shiftRequest
+			before.Status = status
+			before.AdminActor = null.String{}
+
+			err = saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT_REQUEST, shiftRequest.ID, constants.AUDIT_ACTION_CANCEL, actor, before, shiftRequest)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			requestUserIDs = append(requestUserIDs, shiftRequest.UserID)
+		}
+	}
+
+	workerShifts, err := shiftRepo.EndWorkerShiftsByShiftID(int64(shift.ID), actor, at)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	assignmentUserIDs := []int64{}
+	for _, workerShift := range workerShifts {
+		err := saveShiftRemovedEvent(eventRepo, eventType, workerShift.UserID, shift, constants.EVENT_AFFECTED_ASSIGNMENT, reason, actor)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT_ASSIGNMENT, workerShift.ID, constants.AUDIT_ACTION_DELETE, actor, workerShift, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		assignmentUserIDs = append(assignmentUserIDs, workerShift.UserID)
+	}
+
+	return requestUserIDs, assignmentUserIDs, nil
+}
+
+func (s *shiftService) PublishShifts(ctx context.Context, req request.PublishShiftsReq) (resp response.PublishShiftsResult, err error) {
+
+	startDate, err := time.Parse(constants.DATE_FORMAT, req.StartDate)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[PublishShifts] Invalid start date", zap.String("start_date", req.StartDate), zap.Error(err))
+		return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("start_date should be formatted as YYYY-MM-DD")
+	}
+
+	endDate, err := time.Parse(constants.DATE_FORMAT, req.EndDate)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[PublishShifts] Invalid end date", zap.String("end_date", req.EndDate), zap.Error(err))
+		return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("end_date should be formatted as YYYY-MM-DD")
+	}
+
+	if endDate.Before(startDate) {
+		s.cfg.Logger().ErrorWithContext(ctx, "[PublishShifts] End date is before start date", zap.String("start_date", req.StartDate), zap.String("end_date", req.EndDate))
+		return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("end_date cannot be before start_date")
+	}
+
+	if req.LocationID != 0 {
+		_, err = ensureLocationExists(ctx, s.cfg, s.locationRepo, req.LocationID, "PublishShifts")
+		if err != nil {
+			return resp, err
+		}
+	}
+
+	var shiftIDs []int64
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		shiftRepo := s.shiftRepo.WithTx(tx)
+		auditLogRepo := s.auditLogRepo.WithTx(tx)
+
+		shiftIDs, err = shiftRepo.PublishByDateRange(startDate, endDate, req.LocationID, req.UserEmail)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range shiftIDs {
+			published, err := loadShift(shiftRepo, id)
+			if err != nil {
+				return err
+			}
+
+			// only drafts are published, and publishing touches nothing but the status
+			before := *published
+			before.Status = constants.SHIFT_STATUS_DRAFT
+			before.PublishedAt = null.Time{}
+			before.PublishedBy = null.String{}
+
+			err = saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT, id, constants.AUDIT_ACTION_PUBLISH, req.UserEmail, &before, published)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[PublishShifts] Failed to publish shifts", zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to publish shifts")
+	}
+
+	resp = response.PublishShiftsResult{
+		PublishedCount: len(shiftIDs),
+		ShiftIDs:       shiftIDs,
+	}
+
+	return resp, nil
+}
+
+// CompleteShiftByID marks a published shift as completed once it has ended.
+func (s *shiftService) CompleteShiftByID(ctx context.Context, id int64, completedBy string) error {
+	shift, err := loadShift(s.shiftRepo, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.cfg.Logger().ErrorWithContext(ctx, "[CompleteShiftByID] Shift not found", zap.Error(err))
+			return oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Shift not found")
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "[CompleteShiftByID] Failed to get shift by id", zap.Error(err))
+		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift by id")
+	}
+
+	if !shift.CanTransitionTo(constants.SHIFT_STATUS_COMPLETED) {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CompleteShiftByID] Shift cannot be completed", zap.String("status", shift.Status))
+		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Cannot complete a %s shift", strings.ToLower(shift.Status))
+	}
+
+	if shift.EndTime.After(time.Now()) {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CompleteShiftByID] Shift has not ended yet", zap.Time("end", shift.EndTime))
+		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Shift has not ended yet")
+	}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		shiftRepo := s.shiftRepo.WithTx(tx)
+
+		err := shiftRepo.UpdateStatusByID(id, constants.SHIFT_STATUS_COMPLETED, completedBy)
+		if err != nil {
+			return err
+		}
+
+		completed, err := loadShift(shiftRepo, id)
+		if err != nil {
+			return err
+		}
+
+		return saveAuditLog(s.auditLogRepo.WithTx(tx), constants.AUDIT_ENTITY_SHIFT, id, constants.AUDIT_ACTION_COMPLETE, completedBy, shift, completed)
+	})
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CompleteShiftByID] Failed to complete shift", zap.Int64("id", id), zap.Error(err))
+		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to complete shift")
+	}
+
+	return nil
+}
+
 func (s *shiftService) GetShiftRequestList(ctx context.Context, req request.GetShiftRequestListReq) (resp response.GetShiftRequestListResponse, err error) {
 
 	filter := repository.GetShiftRequestListFilter{
@@ -126,6 +652,11 @@ func (s *shiftService) GetShiftRequestList(ctx context.Context, req request.GetS
 		Offset: req.Offset,
 	}
 
+	filter.After, err = parseListCursor(ctx, s.cfg, req.Cursor, "GetShiftRequestList")
+	if err != nil {
+		return resp, err
+	}
+
 	shiftRequests, pagination, err := s.shiftRepo.GetShiftRequestList(filter)
 	if err != nil {
 		s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftRequestList] Failed to get shift request list", zap.Any("filter", filter), zap.Error(err))
@@ -138,6 +669,8 @@ func (s *shiftService) GetShiftRequestList(ctx context.Context, req request.GetS
 			CurrentPage: pagination.CurrentPage,
 			TotalPages:  pagination.TotalPages,
 			TotalItems:  pagination.TotalElements,
+			CursorStart: pagination.CursorStart,
+			CursorEnd:   pagination.CursorEnd,
 		},
 	}
 
@@ -157,18 +690,30 @@ func (s *shiftService) CreateShiftRequest(ctx context.Context, req request.Creat
 		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift by id")
 	}
 
-	isShiftAlreadyAssigned, err := s.shiftRepo.CheckIfShiftIsAlreadyAssigned(req.ShiftID)
+	if shiftDetail.Status != constants.SHIFT_STATUS_PUBLISHED {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftRequest] Shift is not published", zap.String("status", shiftDetail.Status))
+		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Shift is not open for requests")
+	}
+
+	filledSlotCount, err := s.shiftRepo.GetShiftFilledSlotCount(req.ShiftID)
 	if err != nil {
-		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftRequest] Failed to check if shift already assigned", zap.Error(err))
-		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to check if shift already assigned")
+		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftRequest] Failed to get shift filled slot count", zap.Error(err))
+		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift filled slot count")
+	}
+
+	// a full shift only takes requests onto its waitlist, in case a slot is released later
+	isShiftFull := filledSlotCount >= shiftDetail.Headcount
+	if isShiftFull && s.cfg.GetShiftCfg().RequestOverflowAction != constants.SHIFT_REQUEST_OVERFLOW_WAITLIST {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftRequest] Shift has no open slots", zap.Int("headcount", shiftDetail.Headcount), zap.Int("filled", filledSlotCount))
+		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Shift has no open slots")
 	}
 
-	if isShiftAlreadyAssigned {
-		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftRequest] Shift is already assigned", zap.Error(err))
-		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Shift is already assigned")
+	err = ensureWorkerEligibleForShift(ctx, s.cfg, s.shiftRepo, s.skillRepo, req.UserID, shiftDetail, "CreateShiftRequest")
+	if err != nil {
+		return err
 	}
 
-	isShiftRequestTimeOverlaps, err := s.shiftRepo.CheckIfShiftRequestTimeOverlaps(req.UserID, shiftDetail.Date, shiftDetail.StartTime, shiftDetail.EndTime)
+	isShiftRequestTimeOverlaps, err := s.shiftRepo.CheckIfShiftRequestTimeOverlaps(req.UserID, shiftDetail.StartTime, shiftDetail.EndTime)
 	if err != nil {
 		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftRequest] Failed to check shift request overlaps", zap.Error(err))
 		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to check shift request overlaps")
@@ -196,93 +741,395 @@ func (s *shiftService) CreateShiftRequest(ctx context.Context, req request.Creat
 		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to check user weekly shift count")
 	}
 
-	if userWeeklyShiftCount == constants.MAX_ASSIGNED_SHIFT_PER_WEEK {
+	if userWeeklyShiftCount >= constants.MAX_ASSIGNED_SHIFT_PER_WEEK {
 		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftRequest] User already reached shift assignment limit this week", zap.Error(err))
 		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("User already reached shift assignment limit this week")
 	}
 
-	err = s.shiftRepo.SaveShiftRequest(req.ToModel())
+	shiftRequest := req.ToModel()
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		shiftRepo := s.shiftRepo.WithTx(tx)
+		if isShiftFull {
+			shiftRequest.Status = constants.SHIFT_REQUEST_STATUS_WAITLISTED
+			shiftRequest.AdminActor = null.StringFrom(constants.SYSTEM_ACTOR)
+			shiftRequest.RejectionReason = null.StringFrom(constants.SHIFT_FULL_REASON)
+		}
+
+		err := shiftRepo.SaveShiftRequest(shiftRequest)
+		if err != nil {
+			return err
+		}
+
+		created, err := shiftRepo.GetShiftRequestByID(shiftRequest.ID)
+		if err != nil {
+			return err
+		}
+
+		return saveAuditLog(s.auditLogRepo.WithTx(tx), constants.AUDIT_ENTITY_SHIFT_REQUEST, shiftRequest.ID, constants.AUDIT_ACTION_CREATE, req.UserEmail, nil, created)
+	})
 	if err != nil {
 		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftRequest] Failed to create shift request", zap.String("requestedBy", req.UserEmail), zap.Error(err))
 		return err
 	}
+
+	if shiftRequest.Status == constants.SHIFT_REQUEST_STATUS_PENDING {
+		s.autoApproveShiftRequest(ctx, shiftRequest, shiftDetail)
+	}
+
 	return nil
 }
 
-func (s *shiftService) ApproveShiftRequest(ctx context.Context, req request.ApproveShiftRequestReq) error {
+// ApproveShiftRequest assigns the worker to the shift. Every rule is checked inside the
+// approval transaction, which holds the database write lock, so approvals landing at the
+// same time are decided one after the other against what the previous one wrote.
+func (s *shiftService) ApproveShiftRequest(ctx context.Context, req request.ApproveShiftRequestReq) (*model.ShiftRequest, error) {
 
-	shiftRequest, err := s.shiftRepo.GetShiftRequestByID(req.RequestedShiftID)
+	var shiftRequest *model.ShiftRequest
+
+	err := s.transactor.WithinTx(func(tx *sql.Tx) error {
+		shiftRepo := s.shiftRepo.WithTx(tx)
+		auditLogRepo := s.auditLogRepo.WithTx(tx)
+
+		pending, shiftDetail, filledSlotCount, err := s.validateShiftRequestApproval(ctx, shiftRepo, s.skillRepo.WithTx(tx), req)
+		if err != nil {
+			return err
+		}
+
+		before := *pending
+
+		pending.Status = constants.SHIFT_REQUEST_STATUS_APPROVED
+		pending.AdminActor = null.StringFrom(req.UserEmail)
+		pending.UpdatedBy = null.StringFrom(req.UserEmail)
+
+		err = shiftRepo.UpdateShiftRequestByID(req.RequestedShiftID, pending)
+		if err != nil {
+			return err
+		}
+
+		workerShift := &model.WorkerShift{
+			UserID:     pending.UserID,
+			ShiftID:    pending.ShiftID,
+			AssignedBy: req.UserEmail,
+			CreatedBy:  req.UserEmail,
+		}
+
+		err = shiftRepo.SaveWorkerShift(workerShift)
+		if err != nil {
+			return err
+		}
+
+		shiftRequest, err = shiftRepo.GetShiftRequestByID(req.RequestedShiftID)
+		if err != nil {
+			return err
+		}
+
+		err = saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT_REQUEST, shiftRequest.ID, constants.AUDIT_ACTION_APPROVE, req.UserEmail, &before, shiftRequest)
+		if err != nil {
+			return err
+		}
+
+		err = saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT_ASSIGNMENT, workerShift.ID, constants.AUDIT_ACTION_CREATE, req.UserEmail, nil, workerShift)
+		if err != nil {
+			return err
+		}
+
+		if filledSlotCount+1 < shiftDetail.Headcount {
+			return nil
+		}
+
+		// that was the last slot, so the other requests can no longer be approved
+		closed, err := s.closeShiftRequestQueue(shiftRepo, auditLogRepo, shiftDetail)
+		if err != nil {
+			return err
+		}
+
+		if len(closed) > 0 {
+			s.cfg.Logger().InfoWithContext(ctx, "[ApproveShiftRequest] Closed shift request queue", zap.Int("shiftID", shiftDetail.ID), zap.Int("count", len(closed)))
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Shift request was modified concurrently", zap.Int64("id", req.RequestedShiftID))
+			return nil, s.shiftRequestConflictErr(ctx, req.RequestedShiftID, "ApproveShiftRequest")
+		}
+
+		if errors.Is(err, repository.ErrAssignmentExists) {
+			s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Worker is already assigned to the shift", zap.Int64("id", req.RequestedShiftID))
+			return nil, oops.Code(response.Conflict.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusConflict).Errorf("Worker is already assigned to this shift")
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Failed to approve shift request", zap.Error(err))
+		return nil, err
+	}
+
+	return shiftRequest, nil
+}
+
+// validateShiftRequestApproval runs every rule an approval has to pass against the given,
+// transaction-bound repositories. It returns the pending request, its shift and how many
+// slots of the shift are filled.
+func (s *shiftService) validateShiftRequestApproval(ctx context.Context, shiftRepo repository.ShiftRepository, skillRepo repository.SkillRepository, req request.ApproveShiftRequestReq) (*model.ShiftRequest, *model.Shift, int, error) {
+	shiftRequest, err := shiftRepo.GetShiftRequestByID(req.RequestedShiftID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Shift request not found", zap.Error(err))
-			return oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Shift request not found")
+			return nil, nil, 0, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Shift request not found")
 		}
 
 		s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Failed to fetch shift request by ID", zap.Error(err))
-		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift request by id")
+		return nil, nil, 0, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift request by id")
+	}
+
+	if req.Version != 0 && req.Version != shiftRequest.Version {
+		s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Shift request version does not match If-Match", zap.Int("expected", req.Version), zap.Int("current", shiftRequest.Version))
+		return nil, nil, 0, shiftRequestVersionErr(shiftRequest, response.PreconditionFailed, http.StatusPreconditionFailed)
 	}
 
 	if shiftRequest.Status != constants.SHIFT_REQUEST_STATUS_PENDING {
-		s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Shift request status is not pending", zap.Error(err))
-		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Shift request status is not pending")
+		s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Shift request status is not pending", zap.String("status", shiftRequest.Status))
+		// the request was already decided, possibly by a concurrent approve or reject
+		return nil, nil, 0, oops.Code(response.Conflict.AsString()).
+			With(httpresp.StatusCodeCtxKey, http.StatusConflict).
+			With(httpresp.CurrentStateCtxKey, shiftRequest).
+			With(httpresp.ETagCtxKey, pkg.FormatETag(shiftRequest.Version)).
+			Errorf("Shift request status is not pending")
 	}
 
-	shiftRequest.Status = constants.SHIFT_REQUEST_STATUS_APPROVED
-	shiftRequest.AdminActor = null.StringFrom(req.UserEmail)
-	shiftRequest.UpdatedBy = null.StringFrom(req.UserEmail)
+	shiftDetail, err := shiftRepo.GetByID(shiftRequest.ShiftID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Shift not found", zap.Error(err))
+			return nil, nil, 0, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Shift not found")
+		}
 
-	err = s.shiftRepo.UpdateShiftRequestByID(req.RequestedShiftID, shiftRequest)
+		s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Failed to get shift by id", zap.Error(err))
+		return nil, nil, 0, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift by id")
+	}
+
+	if shiftDetail.Status != constants.SHIFT_STATUS_PUBLISHED {
+		s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Shift is not published", zap.String("status", shiftDetail.Status))
+		return nil, nil, 0, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Shift is not open for requests")
+	}
+
+	filledSlotCount, err := shiftRepo.GetShiftFilledSlotCount(shiftRequest.ShiftID)
 	if err != nil {
-		s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Failed to update shift request", zap.Error(err))
-		return err
+		s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Failed to get shift filled slot count", zap.Error(err))
+		return nil, nil, 0, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift filled slot count")
 	}
 
-	workerShift := &model.WorkerShift{
-		UserID:     shiftRequest.UserID,
-		ShiftID:    shiftRequest.ShiftID,
-		AssignedBy: req.UserEmail,
-		CreatedBy:  req.UserEmail,
+	if filledSlotCount >= shiftDetail.Headcount {
+		s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Shift has no open slots", zap.Int("headcount", shiftDetail.Headcount), zap.Int("filled", filledSlotCount))
+		return nil, nil, 0, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Shift has no open slots")
 	}
 
-	err = s.shiftRepo.SaveWorkerShift(workerShift)
+	hasShiftOnDate, err := shiftRepo.CheckUserAssignedShiftExistsByDate(shiftRequest.UserID, shiftDetail.Date)
 	if err != nil {
-		s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Failed to create worker shift", zap.Error(err))
-		return err
+		s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Failed to check assigned shift exists", zap.Error(err))
+		return nil, nil, 0, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to check assigned shift exists")
 	}
 
-	return nil
+	if hasShiftOnDate {
+		s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Worker already has an assigned shift on this day", zap.Int64("userID", shiftRequest.UserID))
+		return nil, nil, 0, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Worker already has an assigned shift on %s", shiftDetail.Date.Format(constants.DATE_FORMAT))
+	}
+
+	overlaps, err := shiftRepo.CheckIfAssignedShiftTimeOverlaps(shiftRequest.UserID, shiftDetail.StartTime, shiftDetail.EndTime)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Failed to check assigned shift overlaps", zap.Error(err))
+		return nil, nil, 0, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to check assigned shift overlaps")
+	}
+
+	if overlaps {
+		s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Shift overlaps an assigned shift", zap.Int64("userID", shiftRequest.UserID))
+		return nil, nil, 0, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Worker already has an assigned shift overlapping this shift")
+	}
+
+	weeklyShiftCount, err := shiftRepo.GetUserWeeklyAssignedShiftCountByDate(shiftRequest.UserID, shiftDetail.Date)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Failed to check user weekly shift count", zap.Error(err))
+		return nil, nil, 0, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to check user weekly shift count")
+	}
+
+	if weeklyShiftCount >= constants.MAX_ASSIGNED_SHIFT_PER_WEEK {
+		s.cfg.Logger().ErrorWithContext(ctx, "[ApproveShiftRequest] Worker already reached shift assignment limit that week", zap.Int64("userID", shiftRequest.UserID), zap.Int("weeklyShiftCount", weeklyShiftCount))
+		return nil, nil, 0, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Worker already reached shift assignment limit that week")
+	}
+
+	err = ensureWorkerEligibleForShift(ctx, s.cfg, shiftRepo, skillRepo, shiftRequest.UserID, shiftDetail, "ApproveShiftRequest")
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	return shiftRequest, shiftDetail, filledSlotCount, nil
 }
 
-func (s *shiftService) RejectShiftRequest(ctx context.Context, req request.RejectShiftRequestReq) error {
+func (s *shiftService) RejectShiftRequest(ctx context.Context, req request.RejectShiftRequestReq) (*model.ShiftRequest, error) {
 
 	shiftRequest, err := s.shiftRepo.GetShiftRequestByID(req.RequestedShiftID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			s.cfg.Logger().ErrorWithContext(ctx, "[RejectShiftRequest] Shift request not found", zap.Error(err))
-			return oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Shift request not found")
+			return nil, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Shift request not found")
 		}
 
 		s.cfg.Logger().ErrorWithContext(ctx, "[RejectShiftRequest] Failed to fetch shift request by ID", zap.Error(err))
-		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift request by id")
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift request by id")
+	}
+
+	if req.Version != 0 && req.Version != shiftRequest.Version {
+		s.cfg.Logger().ErrorWithContext(ctx, "[RejectShiftRequest] Shift request version does not match If-Match", zap.Int("expected", req.Version), zap.Int("current", shiftRequest.Version))
+		return nil, shiftRequestVersionErr(shiftRequest, response.PreconditionFailed, http.StatusPreconditionFailed)
 	}
 
 	if shiftRequest.Status != constants.SHIFT_REQUEST_STATUS_PENDING {
 		s.cfg.Logger().ErrorWithContext(ctx, "[RejectShiftRequest] Shift request status is not pending", zap.Error(err))
-		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Shift request status is not pending")
+		// the request was already decided, possibly by a concurrent approve or reject
+		return nil, oops.Code(response.Conflict.AsString()).
+			With(httpresp.StatusCodeCtxKey, http.StatusConflict).
+			With(httpresp.CurrentStateCtxKey, shiftRequest).
+			With(httpresp.ETagCtxKey, pkg.FormatETag(shiftRequest.Version)).
+			Errorf("Shift request status is not pending")
 	}
 
+	before := *shiftRequest
+
 	shiftRequest.Status = constants.SHIFT_REQUEST_STATUS_REJECTED
 	shiftRequest.AdminActor = null.StringFrom(req.UserEmail)
-	shiftRequest.RejectionReason = null.StringFrom(req.Reason)
+	shiftRequest.RejectionReason = null.NewString(req.Reason, req.Reason != "")
 	shiftRequest.UpdatedBy = null.StringFrom(req.UserEmail)
 
-	err = s.shiftRepo.UpdateShiftRequestByID(req.RequestedShiftID, shiftRequest)
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		shiftRepo := s.shiftRepo.WithTx(tx)
+
+		err := shiftRepo.UpdateShiftRequestByID(req.RequestedShiftID, shiftRequest)
+		if err != nil {
+			return err
+		}
+
+		shiftRequest, err = shiftRepo.GetShiftRequestByID(req.RequestedShiftID)
+		if err != nil {
+			return err
+		}
+
+		auditLogRepo := s.auditLogRepo.WithTx(tx)
+		err = saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT_REQUEST, shiftRequest.ID, constants.AUDIT_ACTION_REJECT, req.UserEmail, &before, shiftRequest)
+		if err != nil {
+			return err
+		}
+
+		return s.promoteAfterShiftRequestLeft(shiftRepo, auditLogRepo, shiftRequest.ShiftID)
+	})
 	if err != nil {
-		s.cfg.Logger().ErrorWithContext(ctx, "[RejectShiftRequest] Failed to update shift request", zap.Error(err))
-		return err
+		if errors.Is(err, repository.ErrVersionConflict) {
+			s.cfg.Logger().ErrorWithContext(ctx, "[RejectShiftRequest] Shift request was modified concurrently", zap.Int64("id", req.RequestedShiftID))
+			return nil, s.shiftRequestConflictErr(ctx, req.RequestedShiftID, "RejectShiftRequest")
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "[RejectShiftRequest] Failed to reject shift request", zap.Error(err))
+		return nil, err
 	}
 
-	return nil
+	return shiftRequest, nil
+}
+
+// WithdrawShiftRequest lets a worker take back their own pending or waitlisted request. The
+// request moves to CANCELLED, so it no longer counts against them in the overlap check.
+func (s *shiftService) WithdrawShiftRequest(ctx context.Context, req request.WithdrawShiftRequestReq) (*model.ShiftRequest, error) {
+
+	shiftRequest, err := s.shiftRepo.GetShiftRequestByID(req.RequestedShiftID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.cfg.Logger().ErrorWithContext(ctx, "[WithdrawShiftRequest] Shift request not found", zap.Error(err))
+			return nil, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Shift request not found")
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "[WithdrawShiftRequest] Failed to fetch shift request by ID", zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift request by id")
+	}
+
+	if shiftRequest.UserID != req.UserID {
+		s.cfg.Logger().ErrorWithContext(ctx, "[WithdrawShiftRequest] Shift request belongs to another user", zap.Int64("userID", req.UserID), zap.Int64("requestUserID", shiftRequest.UserID))
+		return nil, oops.Code(response.Forbidden.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusForbidden).Errorf("Only the requesting worker can withdraw a shift request")
+	}
+
+	if req.Version != 0 && req.Version != shiftRequest.Version {
+		s.cfg.Logger().ErrorWithContext(ctx, "[WithdrawShiftRequest] Shift request version does not match If-Match", zap.Int("expected", req.Version), zap.Int("current", shiftRequest.Version))
+		return nil, shiftRequestVersionErr(shiftRequest, response.PreconditionFailed, http.StatusPreconditionFailed)
+	}
+
+	if shiftRequest.Status != constants.SHIFT_REQUEST_STATUS_PENDING && shiftRequest.Status != constants.SHIFT_REQUEST_STATUS_WAITLISTED {
+		s.cfg.Logger().ErrorWithContext(ctx, "[WithdrawShiftRequest] Shift request status is not pending or waitlisted", zap.String("status", shiftRequest.Status))
+		return nil, oops.Code(response.Conflict.AsString()).
+			With(httpresp.StatusCodeCtxKey, http.StatusConflict).
+			With(httpresp.CurrentStateCtxKey, shiftRequest).
+			With(httpresp.ETagCtxKey, pkg.FormatETag(shiftRequest.Version)).
+			Errorf("Only a pending or waitlisted shift request can be withdrawn")
+	}
+
+	before := *shiftRequest
+
+	shiftRequest.Status = constants.SHIFT_REQUEST_STATUS_CANCELLED
+	shiftRequest.UpdatedBy = null.StringFrom(req.UserEmail)
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		shiftRepo := s.shiftRepo.WithTx(tx)
+
+		err := shiftRepo.UpdateShiftRequestByID(req.RequestedShiftID, shiftRequest)
+		if err != nil {
+			return err
+		}
+
+		shiftRequest, err = shiftRepo.GetShiftRequestByID(req.RequestedShiftID)
+		if err != nil {
+			return err
+		}
+
+		auditLogRepo := s.auditLogRepo.WithTx(tx)
+		err = saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT_REQUEST, shiftRequest.ID, constants.AUDIT_ACTION_WITHDRAW, req.UserEmail, &before, shiftRequest)
+		if err != nil {
+			return err
+		}
+
+		if before.Status != constants.SHIFT_REQUEST_STATUS_PENDING {
+			return nil
+		}
+
+		return s.promoteAfterShiftRequestLeft(shiftRepo, auditLogRepo, shiftRequest.ShiftID)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			s.cfg.Logger().ErrorWithContext(ctx, "[WithdrawShiftRequest] Shift request was modified concurrently", zap.Int64("id", req.RequestedShiftID))
+			return nil, s.shiftRequestConflictErr(ctx, req.RequestedShiftID, "WithdrawShiftRequest")
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "[WithdrawShiftRequest] Failed to withdraw shift request", zap.Error(err))
+		return nil, err
+	}
+
+	return shiftRequest, nil
+}
+
+// shiftRequestConflictErr reports that a request was decided by someone else between our
+// read and our write, carrying the request as it is now.
+func (s *shiftService) shiftRequestConflictErr(ctx context.Context, id int64, caller string) error {
+	current, err := s.shiftRepo.GetShiftRequestByID(id)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to get current shift request", zap.Int64("id", id), zap.Error(err))
+		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift request by id")
+	}
+
+	return shiftRequestVersionErr(current, response.Conflict, http.StatusConflict)
+}
+
+func shiftRequestVersionErr(current *model.ShiftRequest, code response.Code, statusCode int) error {
+	return oops.Code(code.AsString()).
+		With(httpresp.StatusCodeCtxKey, statusCode).
+		With(httpresp.CurrentStateCtxKey, current).
+		With(httpresp.ETagCtxKey, pkg.FormatETag(current.Version)).
+		Errorf("Shift request was modified by another request, current version is %d", current.Version)
 }
 
 func (s *shiftService) GetShiftAssignmentList(ctx context.Context, req request.GetShiftAssignmentListReq) (resp response.GetShiftAssignmentListResponse, err error) {
@@ -293,6 +1140,11 @@ func (s *shiftService) GetShiftAssignmentList(ctx context.Context, req request.G
 		Offset: req.Offset,
 	}
 
+	filter.After, err = parseListCursor(ctx, s.cfg, req.Cursor, "GetShiftAssignmentList")
+	if err != nil {
+		return resp, err
+	}
+
 	shiftAssignments, pagination, err := s.shiftRepo.GetShiftAssignmentList(filter)
 	if err != nil {
 		s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftAssignmentList] Failed to get shifts assignment list", zap.Any("filter", filter), zap.Error(err))
@@ -305,8 +1157,91 @@ func (s *shiftService) GetShiftAssignmentList(ctx context.Context, req request.G
 			CurrentPage: pagination.CurrentPage,
 			TotalPages:  pagination.TotalPages,
 			TotalItems:  pagination.TotalElements,
+			CursorStart: pagination.CursorStart,
+			CursorEnd:   pagination.CursorEnd,
 		},
 	}
 
 	return resp, nil
 }
+
+// validateShiftWindow checks the shift ends after it starts. Overnight shifts are sent with
+// an end instant on the next day rather than an end time before the start time.
+func validateShiftWindow(start, end time.Time) error {
+	if !end.After(start) {
+		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("end_time must be after start_time")
+	}
+
+	if end.Sub(start) > constants.MAX_SHIFT_DURATION {
+		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("A shift cannot be longer than %s", constants.MAX_SHIFT_DURATION)
+	}
+
+	return nil
+}
+
+// validateShiftBreaks checks every break fits inside the shift. Fixed breaks must lie within
+// the shift and not overlap each other, and all breaks together must leave working time.
+func validateShiftBreaks(start, end time.Time, breaks []model.ShiftBreak) error {
+	var total time.Duration
+	fixed := []model.ShiftBreak{}
+
+	for _, shiftBreak := range breaks {
+		length := time.Duration(shiftBreak.DurationMinutes) * time.Minute
+		if length <= 0 {
+			return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Break duration_minutes must be at least 1")
+		}
+		total += length
+
+		switch shiftBreak.BreakType {
+		case constants.SHIFT_BREAK_TYPE_FIXED:
+			if !shiftBreak.StartTime.Valid {
+				return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("A fixed break needs a start_time")
+			}
+
+			breakStart := shiftBreak.StartTime.Time
+			if breakStart.Before(start) || breakStart.Add(length).After(end) {
+				return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("A fixed break must be within the shift")
+			}
+			fixed = append(fixed, shiftBreak)
+		case constants.SHIFT_BREAK_TYPE_FLOATING:
+			if shiftBreak.StartTime.Valid {
+				return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("A floating break cannot have a start_time")
+			}
+		default:
+			return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Unsupported break type %q", shiftBreak.BreakType)
+		}
+	}
+
+	if len(breaks) > 0 && total >= end.Sub(start) {
+		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Breaks must be shorter than the shift")
+	}
+
+	sort.Slice(fixed, func(i, j int) bool {
+		return fixed[i].StartTime.Time.Before(fixed[j].StartTime.Time)
+	})
+
+	for i := 1; i < len(fixed); i++ {
+		previousEnd := fixed[i-1].StartTime.Time.Add(time.Duration(fixed[i-1].DurationMinutes) * time.Minute)
+		if fixed[i].StartTime.Time.Before(previousEnd) {
+			return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Fixed breaks cannot overlap")
+		}
+	}
+
+	return nil
+}
+
+// parseListCursor decodes the opaque cursor of a list request. An empty cursor keeps the
+// list on offset pagination.
+func parseListCursor(ctx context.Context, cfg config.Config, cursor string, caller string) (*repository.ListCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	sortValue, id, err := pkg.DecodeCursor(cursor)
+	if err != nil {
+		cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Invalid cursor", zap.String("cursor", cursor), zap.Error(err))
+		return nil, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Invalid cursor")
+	}
+
+	return &repository.ListCursor{SortValue: sortValue, ID: id}, nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"github.com/andibalo/payd-test/backend/internal/constants"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/andibalo/payd-test/backend/internal/repository"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"go.uber.org/zap"
+	"time"
+)
+
+// autoApproveShiftRequest approves a newly created request as the system actor when an
+// active auto-approval rule matches it. The approval goes through ApproveShiftRequest, so a
+// matched request that no longer passes its checks is left pending for an admin. Failures
+// here never fail the request itself.
+func (s *shiftService) autoApproveShiftRequest(ctx context.Context, shiftRequest *model.ShiftRequest, shift *model.Shift) {
+	rules, err := s.autoApprovalRuleRepo.GetList(repository.GetAutoApprovalRuleListFilter{ActiveOnly: true})
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[autoApproveShiftRequest] Failed to get active auto-approval rules", zap.Int64("requestID", shiftRequest.ID), zap.Error(err))
+		return
+	}
+
+	if len(rules) == 0 {
+		return
+	}
+
+	completedShiftCount, err := s.shiftRepo.GetUserCompletedShiftCount(shiftRequest.UserID)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[autoApproveShiftRequest] Failed to get user completed shift count", zap.Int64("userID", shiftRequest.UserID), zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	for _, rule := range rules {
+		if !rule.Matches(shift, completedShiftCount, now) {
+			continue
+		}
+
+		s.cfg.Logger().InfoWithContext(ctx, "[autoApproveShiftRequest] Shift request matched auto-approval rule",
+			zap.Int64("requestID", shiftRequest.ID), zap.Int64("ruleID", rule.ID), zap.String("ruleName", rule.Name), zap.Int("completedShiftCount", completedShiftCount))
+
+		approved, err := s.ApproveShiftRequest(ctx, request.ApproveShiftRequestReq{
+			RequestedShiftID: shiftRequest.ID,
+			UserEmail:        constants.SYSTEM_ACTOR,
+		})
+		if err != nil {
+			s.cfg.Logger().WarnWithContext(ctx, "[autoApproveShiftRequest] Auto-approval declined, shift request left pending", zap.Int64("requestID", shiftRequest.ID), zap.Int64("ruleID", rule.ID), zap.Error(err))
+			return
+		}
+
+		s.cfg.Logger().InfoWithContext(ctx, "[autoApproveShiftRequest] Shift request auto-approved", zap.Int64("requestID", approved.ID), zap.Int64("ruleID", rule.ID))
+		return
+	}
+
+	s.cfg.Logger().InfoWithContext(ctx, "[autoApproveShiftRequest] No auto-approval rule matched shift request", zap.Int64("requestID", shiftRequest.ID), zap.Int("ruleCount", len(rules)))
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/andibalo/payd-test/backend/internal/constants"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/andibalo/payd-test/backend/internal/repository"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CopyShiftRoster clones the active shifts of the source range into the target range at the
+// same local times. Shifts and assignments that cannot be copied are reported as skipped
+// instead of failing the whole copy.
+func (s *shiftService) CopyShiftRoster(ctx context.Context, req request.CopyShiftRosterReq) (resp response.CopyShiftRosterResult, err error) {
+
+	dates := make([]time.Time, 3)
+	for i, field := range []struct{ name, value string }{
+		{"source_start_date", req.SourceStartDate},
+		{"source_end_date", req.SourceEndDate},
+		{"target_start_date", req.TargetStartDate},
+	} {
+		dates[i], err = time.Parse(constants.DATE_FORMAT, field.value)
+		if err != nil {
+			s.cfg.Logger().ErrorWithContext(ctx, "[CopyShiftRoster] Invalid date", zap.String(field.name, field.value), zap.Error(err))
+			return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("%s should be formatted as YYYY-MM-DD", field.name)
+		}
+	}
+
+	sourceStart, sourceEnd, targetStart := dates[0], dates[1], dates[2]
+
+	if sourceEnd.Before(sourceStart) {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CopyShiftRoster] Source range ends before it starts", zap.String("source_start_date", req.SourceStartDate), zap.String("source_end_date", req.SourceEndDate))
+		return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("source_end_date cannot be before source_start_date")
+	}
+
+	days := int(sourceEnd.Sub(sourceStart).Hours()/24) + 1
+	if days > constants.MAX_ROSTER_COPY_DAYS {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CopyShiftRoster] Source range is too long", zap.Int("days", days))
+		return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Source range cannot be longer than %d days", constants.MAX_ROSTER_COPY_DAYS)
+	}
+
+	targetEnd := targetStart.AddDate(0, 0, days-1)
+	if !targetStart.After(sourceEnd) && !targetEnd.Before(sourceStart) {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CopyShiftRoster] Target range overlaps source range", zap.String("target_start_date", req.TargetStartDate))
+		return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Target range cannot overlap the source range")
+	}
+
+	offsetDays := int(targetStart.Sub(sourceStart).Hours() / 24)
+
+	sourceShifts, err := s.shiftRepo.GetActiveByDateRange(sourceStart, sourceEnd)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CopyShiftRoster] Failed to get source shifts", zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get source shifts")
+	}
+
+	roles, err := s.shiftRoleRepo.GetList(repository.GetShiftRoleListFilter{IncludeDeleted: true})
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CopyShiftRoster] Failed to get shift role list", zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift role list")
+	}
+
+	locations, err := s.locationRepo.GetList(repository.GetLocationListFilter{IncludeDeleted: true})
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CopyShiftRoster] Failed to get location list", zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get location list")
+	}
+
+	deletedRoleIDs := map[int64]bool{}
+	for _, role := range roles {
+		deletedRoleIDs[role.ID] = role.DeletedAt.Valid
+	}
+
+	deletedLocationIDs := map[int64]bool{}
+	for _, location := range locations {
+		deletedLocationIDs[location.ID] = location.DeletedAt.Valid
+	}
+
+	resp = response.CopyShiftRosterResult{
+		TargetStartDate: targetStart.Format(constants.DATE_FORMAT),
+		TargetEndDate:   targetEnd.Format(constants.DATE_FORMAT),
+		Created:         []response.CopiedShiftData{},
+		Skipped:         []response.SkippedShiftCopyData{},
+	}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		shiftRepo := s.shiftRepo.WithTx(tx)
+		auditLogRepo := s.auditLogRepo.WithTx(tx)
+
+		for _, source := range sourceShifts {
+			skip := func(reason string) {
+				resp.Skipped = append(resp.Skipped, response.SkippedShiftCopyData{SourceShiftID: source.ID, Reason: reason})
+			}
+
+			if deletedRoleIDs[int64(source.RoleID)] {
+				skip("Shift role has been deleted")
+				continue
+			}
+
+			if source.LocationID.Valid && deletedLocationIDs[source.LocationID.Int64] {
+				skip("Location has been deleted")
+				continue
+			}
+
+			loc, err := time.LoadLocation(source.Timezone)
+			if err != nil {
+				skip(fmt.Sprintf("Invalid shift timezone %q", source.Timezone))
+				continue
+			}
+
+			shift := &model.Shift{
+				RoleID:     source.RoleID,
+				LocationID: source.LocationID,
+				Headcount:  source.Headcount,
+				HourlyRate: source.HourlyRate,
+				IsActive:   true,
+				Status:     constants.SHIFT_STATUS_DRAFT,
+				CreatedBy:  req.UserEmail,
+			}
+			shift.SetSchedule(addLocalDays(source.StartTime, offsetDays, loc), addLocalDays(source.EndTime, offsetDays, loc), loc)
+
+			exists, err := shiftRepo.CheckIfSameShiftExists(shift)
+			if err != nil {
+				return err
+			}
+
+			if exists {
+				skip("An identical shift already exists in the target range")
+				continue
+			}
+
+			breaks, err := shiftRepo.GetBreaksByShiftID(int64(source.ID))
+			if err != nil {
+				return err
+			}
+
+			for i := range breaks {
+				breaks[i].CreatedBy = req.UserEmail
+				if breaks[i].StartTime.Valid {
+					breaks[i].StartTime.Time = addLocalDays(breaks[i].StartTime.Time, offsetDays, loc).UTC()
+				}
+			}
+
+			err = shiftRepo.Save(shift)
+			if err != nil {
+				return err
+			}
+
+			err = shiftRepo.SaveBreaks(int64(shift.ID), breaks)
+			if err != nil {
+				return err
+			}
+
+			requiredSkillIDs, err := shiftRepo.GetRequiredSkillIDs(int64(source.ID))
+			if err != nil {
+				return err
+			}
+
+			err = shiftRepo.SaveRequiredSkills(int64(shift.ID), requiredSkillIDs)
+			if err != nil {
+				return err
+			}
+
+			created, err := loadShift(shiftRepo, int64(shift.ID))
+			if err != nil {
+				return err
+			}
+
+			err = saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT, int64(shift.ID), constants.AUDIT_ACTION_CREATE, req.UserEmail, nil, created)
+			if err != nil {
+				return err
+			}
+
+			copied := response.CopiedShiftData{
+				SourceShiftID:   source.ID,
+				ShiftID:         shift.ID,
+				StartTime:       shift.StartTime,
+				EndTime:         shift.EndTime,
+				AssignedUserIDs: []int64{},
+			}
+
+			if req.KeepAssignments {
+				copied.AssignedUserIDs, err = s.copyShiftAssignments(shiftRepo, s.skillRepo.WithTx(tx), auditLogRepo, source, shift, req.UserEmail, &resp)
+				if err != nil {
+					return err
+				}
+			}
+
+			resp.Created = append(resp.Created, copied)
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CopyShiftRoster] Failed to copy shift roster", zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to copy shift roster")
+	}
+
+	resp.CreatedCount = len(resp.Created)
+	resp.SkippedCount = len(resp.Skipped)
+
+	return resp, nil
+}
+
+// copyShiftAssignments re-creates the assignments of source on shift for every worker who
+// still passes the same-day, weekly limit, overlap and skill rules, and reports the rest as
+// skipped.
+func (s *shiftService) copyShiftAssignments(shiftRepo repository.ShiftRepository, skillRepo repository.SkillRepository, auditLogRepo repository.AuditLogRepository, source model.Shift, shift *model.Shift, assignedBy string, resp *response.CopyShiftRosterResult) ([]int64, error) {
+	assignedUserIDs := []int64{}
+
+	userIDs, err := shiftRepo.GetShiftAssigneeIDs(int64(source.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, userID := range userIDs {
+		skip := func(reason string) {
+			resp.Skipped = append(resp.Skipped, response.SkippedShiftCopyData{SourceShiftID: source.ID, UserID: &userID, Reason: reason})
+		}
+
+		hasShiftOnDate, err := shiftRepo.CheckUserAssignedShiftExistsByDate(userID, shift.Date)
+		if err != nil {
+			return nil, err
+		}
+
+		if hasShiftOnDate {
+			skip(fmt.Sprintf("Worker already has an assigned shift on %s", shift.Date.Format(constants.DATE_FORMAT)))
+			continue
+		}
+
+		weeklyShiftCount, err := shiftRepo.GetUserWeeklyAssignedShiftCountByDate(userID, shift.Date)
+		if err != nil {
+			return nil, err
+		}
+
+		if weeklyShiftCount >= constants.MAX_ASSIGNED_SHIFT_PER_WEEK {
+			skip("Worker already reached shift assignment limit that week")
+			continue
+		}
+
+		overlaps, err := shiftRepo.CheckIfAssignedShiftTimeOverlaps(userID, shift.StartTime, shift.EndTime)
+		if err != nil {
+			return nil, err
+		}
+
+		if overlaps {
+			skip("Worker already has an assigned shift overlapping this shift")
+			continue
+		}
+
+		missingSkills, expiredSkills, err := checkWorkerSkills(shiftRepo, skillRepo, userID, shift)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(missingSkills) > 0 {
+			skip(fmt.Sprintf("Worker lacks required skills: %s", strings.Join(missingSkills, ", ")))
+			continue
+		}
+
+		if len(expiredSkills) > 0 {
+			skip(fmt.Sprintf("Worker certifications expired by the shift date: %s", strings.Join(expiredSkills, ", ")))
+			continue
+		}
+
+		workerShift := &model.WorkerShift{
+			UserID:     userID,
+			ShiftID:    int64(shift.ID),
+			AssignedBy: assignedBy,
+			CreatedBy:  assignedBy,
+		}
+
+		err = shiftRepo.SaveWorkerShift(workerShift)
+		if err != nil {
+			return nil, err
+		}
+
+		err = saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT_ASSIGNMENT, workerShift.ID, constants.AUDIT_ACTION_CREATE, assignedBy, nil, workerShift)
+		if err != nil {
+			return nil, err
+		}
+
+		assignedUserIDs = append(assignedUserIDs, userID)
+	}
+
+	return assignedUserIDs, nil
+}
+
+// addLocalDays moves t by the given number of calendar days while keeping its wall-clock
+// time in loc, so a copied shift starts at the same local time across DST changes.
+func addLocalDays(t time.Time, days int, loc *time.Location) time.Time {
+	local := t.In(loc)
+
+	return time.Date(local.Year(), local.Month(), local.Day()+days, local.Hour(), local.Minute(), local.Second(), 0, loc)
+}
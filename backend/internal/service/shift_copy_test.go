@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestAddLocalDays(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatalf("LoadLocation returned error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		t    string
+		days int
+		loc  *time.Location
+		want string
+	}{
+		{"into summer time", "2025-03-28T08:00:00Z", 7, amsterdam, "2025-04-04T07:00:00Z"},
+		{"back into winter time", "2025-04-04T07:00:00Z", -7, amsterdam, "2025-03-28T08:00:00Z"},
+		{"past midnight across the autumn change", "2025-10-24T22:30:00Z", 2, amsterdam, "2025-10-26T23:30:00Z"},
+		{"across a month end in UTC", "2025-01-31T10:00:00Z", 1, time.UTC, "2025-02-01T10:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, err := time.Parse(time.RFC3339, tt.t)
+			if err != nil {
+				t.Fatalf("invalid test time %q: %v", tt.t, err)
+			}
+
+			if got := addLocalDays(in, tt.days, tt.loc).UTC().Format(time.RFC3339); got != tt.want {
+				t.Errorf("addLocalDays() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"github.com/andibalo/payd-test/backend/internal/constants"
+	"github.com/andibalo/payd-test/backend/internal/repository"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"math"
+	"net/http"
+	"time"
+)
+
+// GetShiftCost estimates what the roster in a date range costs. Assigned workers are paid
+// the shift rate, then their own rate, then the role rate; open slots the shift rate, then
+// the role rate. Every slot is paid for its net working time.
+func (s *shiftService) GetShiftCost(ctx context.Context, req request.GetShiftCostReq) (resp response.ShiftCostReport, err error) {
+
+	startDate, err := time.Parse(constants.DATE_FORMAT, req.StartDate)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftCost] Invalid start date", zap.String("start_date", req.StartDate), zap.Error(err))
+		return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("start_date should be formatted as YYYY-MM-DD")
+	}
+
+	endDate, err := time.Parse(constants.DATE_FORMAT, req.EndDate)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftCost] Invalid end date", zap.String("end_date", req.EndDate), zap.Error(err))
+		return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("end_date should be formatted as YYYY-MM-DD")
+	}
+
+	if endDate.Before(startDate) {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftCost] End date is before start date", zap.String("start_date", req.StartDate), zap.String("end_date", req.EndDate))
+		return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("end_date cannot be before start_date")
+	}
+
+	if int(endDate.Sub(startDate).Hours()/24)+1 > constants.MAX_SHIFT_COST_RANGE_DAYS {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftCost] Date range too long", zap.String("start_date", req.StartDate), zap.String("end_date", req.EndDate))
+		return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("The date range cannot be longer than %d days", constants.MAX_SHIFT_COST_RANGE_DAYS)
+	}
+
+	if req.LocationID != 0 {
+		_, err = ensureLocationExists(ctx, s.cfg, s.locationRepo, req.LocationID, "GetShiftCost")
+		if err != nil {
+			return resp, err
+		}
+	}
+
+	lines, err := s.shiftRepo.GetCostLines(repository.GetShiftCostFilter{
+		FromDate:   startDate,
+		ToDate:     endDate,
+		LocationID: req.LocationID,
+	})
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftCost] Failed to get shift cost lines", zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift cost")
+	}
+
+	resp = response.ShiftCostReport{
+		StartDate:  req.StartDate,
+		EndDate:    req.EndDate,
+		ByRole:     []response.ShiftCostByRole{},
+		ByLocation: []response.ShiftCostByLocation{},
+	}
+
+	roleIndex := map[int64]int{}
+	locationIndex := map[int64]int{}
+
+	for _, line := range lines {
+		i, ok := roleIndex[line.RoleID]
+		if !ok {
+			i = len(resp.ByRole)
+			roleIndex[line.RoleID] = i
+			resp.ByRole = append(resp.ByRole, response.ShiftCostByRole{RoleID: line.RoleID, RoleName: line.RoleName})
+		}
+
+		j, ok := locationIndex[line.LocationID.Int64]
+		if !ok {
+			j = len(resp.ByLocation)
+			locationIndex[line.LocationID.Int64] = j
+			resp.ByLocation = append(resp.ByLocation, response.ShiftCostByLocation{LocationID: line.LocationID, LocationName: line.LocationName})
+		}
+
+		addShiftCostLine(&resp.Total, line)
+		addShiftCostLine(&resp.ByRole[i].ShiftCostTotals, line)
+		addShiftCostLine(&resp.ByLocation[j].ShiftCostTotals, line)
+	}
+
+	roundShiftCostTotals(&resp.Total)
+	for i := range resp.ByRole {
+		roundShiftCostTotals(&resp.ByRole[i].ShiftCostTotals)
+	}
+	for i := range resp.ByLocation {
+		roundShiftCostTotals(&resp.ByLocation[i].ShiftCostTotals)
+	}
+
+	return resp, nil
+}
+
+func addShiftCostLine(totals *response.ShiftCostTotals, line repository.ShiftCostLine) {
+	hours := float64(line.Slots*line.NetWorkingMinutes) / 60
+	cost := hours * line.HourlyRate.Float64
+
+	if !line.HourlyRate.Valid {
+		totals.UnpricedSlots += line.Slots
+	}
+
+	if line.UserID.Valid {
+		totals.AssignedSlots += line.Slots
+		totals.AssignedHours += hours
+		totals.AssignedCost += cost
+	} else {
+		totals.OpenSlots += line.Slots
+		totals.OpenHours += hours
+		totals.OpenCost += cost
+	}
+
+	totals.TotalCost += cost
+}
+
+func roundShiftCostTotals(totals *response.ShiftCostTotals) {
+	round := func(v float64) float64 {
+		return math.Round(v*100) / 100
+	}
+
+	totals.AssignedHours = round(totals.AssignedHours)
+	totals.OpenHours = round(totals.OpenHours)
+	totals.AssignedCost = round(totals.AssignedCost)
+	totals.OpenCost = round(totals.OpenCost)
+	totals.TotalCost = round(totals.TotalCost)
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"github.com/andibalo/payd-test/backend/internal/constants"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/guregu/null/v6"
+	"go.uber.org/zap"
+	"time"
+)
+
+// ExpireShiftRequests moves requests nobody decided on in time to EXPIRED, with the system as
+// the actor: pending and waitlisted requests whose shift has started, and pending requests
+// older than the approval deadline when one is configured. It returns the expired requests.
+func (s *shiftService) ExpireShiftRequests(ctx context.Context) ([]model.ShiftRequest, error) {
+	now := time.Now().UTC()
+
+	var createdBefore *time.Time
+	if deadlineHours := s.cfg.GetShiftCfg().RequestApprovalDeadlineHours; deadlineHours > 0 {
+		deadline := now.Add(-time.Duration(deadlineHours) * time.Hour)
+		createdBefore = &deadline
+	}
+
+	var expired []model.ShiftRequest
+
+	err := s.transactor.WithinTx(func(tx *sql.Tx) error {
+		auditLogRepo := s.auditLogRepo.WithTx(tx)
+
+		shiftRepo := s.shiftRepo.WithTx(tx)
+		expired = nil
+
+		// a waitlisted request was never up for a decision, so only the shift start ends it
+		deadlines := []struct {
+			status        string
+			createdBefore *time.Time
+		}{
+			{constants.SHIFT_REQUEST_STATUS_PENDING, createdBefore},
+			{constants.SHIFT_REQUEST_STATUS_WAITLISTED, nil},
+		}
+
+		for _, deadline := range deadlines {
+			shiftRequests, err := shiftRepo.ExpireShiftRequests(deadline.status, now, deadline.createdBefore, constants.SYSTEM_ACTOR)
+			if err != nil {
+				return err
+			}
+
+			for _, shiftRequest := range shiftRequests {
+				// the update only moved the request out of its status and recorded the actor,
+				// which for a waitlisted request already was the system
+				before := shiftRequest
+				before.Status = deadline.status
+				if deadline.status == constants.SHIFT_REQUEST_STATUS_PENDING {
+					before.AdminActor = null.String{}
+				}
+
+				err := saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT_REQUEST, shiftRequest.ID, constants.AUDIT_ACTION_EXPIRE, constants.SYSTEM_ACTOR, before, shiftRequest)
+				if err != nil {
+					return err
+				}
+			}
+
+			expired = append(expired, shiftRequests...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[ExpireShiftRequests] Failed to expire shift requests", zap.Error(err))
+		return nil, err
+	}
+
+	if len(expired) > 0 {
+		s.cfg.Logger().InfoWithContext(ctx, "[ExpireShiftRequests] Expired stale shift requests", zap.Int("count", len(expired)))
+	}
+
+	return expired, nil
+}
+
+// StartShiftRequestExpiryJob sweeps stale requests straight away and then on the configured
+// interval until ctx is done. A failed sweep is logged and retried on the next tick.
+func (s *shiftService) StartShiftRequestExpiryJob(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.cfg.GetShiftCfg().RequestExpiryIntervalMinutes) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		_, _ = s.ExpireShiftRequests(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
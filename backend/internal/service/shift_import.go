@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/andibalo/payd-test/backend/internal/constants"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/andibalo/payd-test/backend/internal/repository"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/guregu/null/v6"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ImportShifts validates every row of an import file and, unless it is a dry run, creates
+// all shifts in one transaction. A row that repeats an earlier row or an existing shift is
+// a row error, and a file with any row error creates nothing.
+func (s *shiftService) ImportShifts(ctx context.Context, req request.ImportShiftReq) (resp response.ImportShiftResult, err error) {
+
+	resp = response.ImportShiftResult{
+		DryRun:          req.DryRun,
+		TotalRows:       len(req.Rows),
+		CreatedShiftIDs: []int{},
+		Errors:          []response.ImportShiftRowError{},
+	}
+
+	if len(req.Rows) == 0 {
+		s.cfg.Logger().ErrorWithContext(ctx, "[ImportShifts] Import file has no shifts")
+		return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Import file has no shifts")
+	}
+
+	if len(req.Rows) > constants.MAX_SHIFT_IMPORT_ROWS {
+		s.cfg.Logger().ErrorWithContext(ctx, "[ImportShifts] Import file has too many shifts", zap.Int("rows", len(req.Rows)))
+		return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Import file cannot have more than %d shifts", constants.MAX_SHIFT_IMPORT_ROWS)
+	}
+
+	roles, err := s.shiftRoleRepo.GetList(repository.GetShiftRoleListFilter{IncludeDeleted: true})
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[ImportShifts] Failed to get shift role list", zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift role list")
+	}
+
+	locations, err := s.locationRepo.GetList(repository.GetLocationListFilter{IncludeDeleted: true})
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[ImportShifts] Failed to get location list", zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get location list")
+	}
+
+	rolesByName := map[string]model.ShiftRoleEnum{}
+	for _, role := range roles {
+		rolesByName[strings.ToLower(role.RoleName)] = role
+	}
+
+	locationsByName := map[string]model.Location{}
+	for _, location := range locations {
+		locationsByName[strings.ToLower(location.Name)] = location
+	}
+
+	shifts := []*model.Shift{}
+	firstRowByShift := map[string]int{}
+	for _, row := range req.Rows {
+		shift, rowErrors := buildImportedShift(row, rolesByName, locationsByName, req.UserEmail)
+		if len(rowErrors) > 0 {
+			resp.Errors = append(resp.Errors, rowErrors...)
+			continue
+		}
+
+		key := fmt.Sprintf("%d|%d|%s|%s", shift.RoleID, shift.LocationID.Int64, shift.StartTime.UTC().Format(constants.DATETIME_FORMAT), shift.EndTime.UTC().Format(constants.DATETIME_FORMAT))
+		if firstRow, ok := firstRowByShift[key]; ok {
+			resp.Errors = append(resp.Errors, response.ImportShiftRowError{Row: row.Row, Field: "shift", Message: fmt.Sprintf("shift duplicates row %d", firstRow)})
+			continue
+		}
+		firstRowByShift[key] = row.Row
+
+		exists, err := s.shiftRepo.CheckIfSameShiftExists(shift)
+		if err != nil {
+			s.cfg.Logger().ErrorWithContext(ctx, "[ImportShifts] Failed to check if same shift exists", zap.Int("row", row.Row), zap.Error(err))
+			return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to check if same shift exists")
+		}
+
+		if exists {
+			resp.Errors = append(resp.Errors, response.ImportShiftRowError{Row: row.Row, Field: "shift", Message: "an identical shift already exists"})
+			continue
+		}
+
+		shifts = append(shifts, shift)
+	}
+
+	resp.ValidRows = len(shifts)
+
+	if len(resp.Errors) > 0 || req.DryRun {
+		return resp, nil
+	}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		shiftRepo := s.shiftRepo.WithTx(tx)
+		auditLogRepo := s.auditLogRepo.WithTx(tx)
+
+		for _, shift := range shifts {
+			err := shiftRepo.Save(shift)
+			if err != nil {
+				return err
+			}
+
+			created, err := loadShift(shiftRepo, int64(shift.ID))
+			if err != nil {
+				return err
+			}
+
+			err = saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT, int64(shift.ID), constants.AUDIT_ACTION_CREATE, req.UserEmail, nil, created)
+			if err != nil {
+				return err
+			}
+
+			resp.CreatedShiftIDs = append(resp.CreatedShiftIDs, shift.ID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[ImportShifts] Failed to import shifts", zap.Int("rows", len(shifts)), zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to import shifts")
+	}
+
+	resp.ImportedCount = len(resp.CreatedShiftIDs)
+
+	return resp, nil
+}
+
+// buildImportedShift turns an import row into a shift, or returns every problem found in the row.
+func buildImportedShift(row request.ImportShiftRow, rolesByName map[string]model.ShiftRoleEnum, locationsByName map[string]model.Location, createdBy string) (*model.Shift, []response.ImportShiftRowError) {
+	rowErrors := []response.ImportShiftRowError{}
+	addError := func(field, format string, args ...interface{}) {
+		rowErrors = append(rowErrors, response.ImportShiftRowError{Row: row.Row, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	date, err := time.Parse(constants.DATE_FORMAT, row.Date)
+	if err != nil {
+		addError("date", "date should be formatted as YYYY-MM-DD")
+	}
+
+	if _, err := time.Parse(constants.TIME_OF_DAY_FORMAT, row.Start); err != nil {
+		addError("start", "start should be formatted as HH:MM")
+	}
+
+	if _, err := time.Parse(constants.TIME_OF_DAY_FORMAT, row.End); err != nil {
+		addError("end", "end should be formatted as HH:MM")
+	}
+
+	role, ok := rolesByName[strings.ToLower(strings.TrimSpace(row.Role))]
+	switch {
+	case strings.TrimSpace(row.Role) == "":
+		addError("role", "role is required")
+	case !ok:
+		addError("role", "role %q does not exist", row.Role)
+	case role.DeletedAt.Valid:
+		addError("role", "role %q has been deleted", row.Role)
+	}
+
+	timezone := row.Timezone
+	locationID := null.Int{}
+
+	if strings.TrimSpace(row.Location) != "" {
+		location, ok := locationsByName[strings.ToLower(strings.TrimSpace(row.Location))]
+		switch {
+		case !ok:
+			addError("location", "location %q does not exist", row.Location)
+		case location.DeletedAt.Valid:
+			addError("location", "location %q has been deleted", row.Location)
+		case timezone != "" && timezone != location.Timezone:
+			addError("timezone", "timezone %q does not match the location timezone %q", timezone, location.Timezone)
+		default:
+			locationID = null.IntFrom(location.ID)
+			timezone = location.Timezone
+		}
+	}
+
+	if timezone == "" {
+		timezone = constants.DEFAULT_LOCATION_TIMEZONE
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "Local" {
+		addError("timezone", "timezone %q is not a valid IANA name", timezone)
+	}
+
+	headcount := row.Headcount
+	if headcount == 0 {
+		headcount = 1
+	}
+
+	if headcount < 0 {
+		addError("headcount", "headcount must be a positive whole number")
+	}
+
+	if len(rowErrors) > 0 {
+		return nil, rowErrors
+	}
+
+	start, end := occurrenceTimes(date, row.Start, row.End, loc)
+
+	shift := &model.Shift{
+		RoleID:     int(role.ID),
+		LocationID: locationID,
+		Headcount:  headcount,
+		IsActive:   true,
+		Status:     constants.SHIFT_STATUS_DRAFT,
+		CreatedBy:  createdBy,
+	}
+	shift.SetSchedule(start, end, loc)
+
+	return shift, nil
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/andibalo/payd-test/backend/internal/constants"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/andibalo/payd-test/backend/internal/repository"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/guregu/null/v6"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+	"sort"
+)
+
+// GetShiftRequestQueue lists the pending and waitlisted requests for a shift in the order
+// they should be decided on.
+func (s *shiftService) GetShiftRequestQueue(ctx context.Context, shiftID int64) (resp response.ShiftRequestQueue, err error) {
+	shift, err := s.shiftRepo.GetByID(shiftID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftRequestQueue] Shift not found", zap.Error(err))
+			return resp, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Shift not found")
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftRequestQueue] Failed to get shift by id", zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift by id")
+	}
+
+	resp = response.ShiftRequestQueue{
+		ShiftID:   shift.ID,
+		Order:     s.cfg.GetShiftCfg().RequestQueueOrder,
+		Headcount: shift.Headcount,
+	}
+
+	resp.FilledSlots, err = s.shiftRepo.GetShiftFilledSlotCount(shiftID)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftRequestQueue] Failed to get shift filled slot count", zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift filled slot count")
+	}
+
+	resp.Requests, err = s.getShiftRequestQueue(s.shiftRepo, shift)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftRequestQueue] Failed to get shift request queue", zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift request queue")
+	}
+
+	return resp, nil
+}
+
+// getShiftRequestQueue ranks the pending and waitlisted requests for a shift. FIRST_COME keeps
+// them in the order they were made; FEWEST_SHIFTS puts workers holding fewer shifts that week
+// first and breaks ties by arrival.
+func (s *shiftService) getShiftRequestQueue(shiftRepo repository.ShiftRepository, shift *model.Shift) ([]response.ShiftRequestQueueItem, error) {
+	shiftRequests, err := shiftRepo.GetShiftRequestQueue(int64(shift.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	queue := make([]response.ShiftRequestQueueItem, 0, len(shiftRequests))
+	for _, shiftRequest := range shiftRequests {
+		weeklyShiftCount, err := shiftRepo.GetUserWeeklyAssignedShiftCountByDate(shiftRequest.UserID, shift.Date)
+		if err != nil {
+			return nil, err
+		}
+
+		queue = append(queue, response.ShiftRequestQueueItem{WeeklyShiftCount: weeklyShiftCount, ShiftRequest: shiftRequest})
+	}
+
+	if s.cfg.GetShiftCfg().RequestQueueOrder == constants.SHIFT_REQUEST_QUEUE_ORDER_FEWEST_SHIFTS {
+		sort.SliceStable(queue, func(i, j int) bool {
+			return queue[i].WeeklyShiftCount < queue[j].WeeklyShiftCount
+		})
+	}
+
+	for i := range queue {
+		queue[i].Position = i + 1
+	}
+
+	return queue, nil
+}
+
+// closeShiftRequestQueue waitlists or rejects, as configured, the pending requests left once
+// the last slot of a shift is filled, with the system as the actor. It returns them.
+func (s *shiftService) closeShiftRequestQueue(shiftRepo repository.ShiftRepository, auditLogRepo repository.AuditLogRepository, shift *model.Shift) ([]model.ShiftRequest, error) {
+	queue, err := s.getShiftRequestQueue(shiftRepo, shift)
+	if err != nil {
+		return nil, err
+	}
+
+	status, action := constants.SHIFT_REQUEST_STATUS_WAITLISTED, constants.AUDIT_ACTION_WAITLIST
+	if s.cfg.GetShiftCfg().RequestOverflowAction == constants.SHIFT_REQUEST_OVERFLOW_REJECT {
+		status, action = constants.SHIFT_REQUEST_STATUS_REJECTED, constants.AUDIT_ACTION_REJECT
+	}
+
+	closed := []model.ShiftRequest{}
+	for _, item := range queue {
+		if item.Status != constants.SHIFT_REQUEST_STATUS_PENDING {
+			continue
+		}
+
+		shiftRequest, err := s.updateQueuedShiftRequest(shiftRepo, auditLogRepo, item.ShiftRequest, status, null.StringFrom(constants.SHIFT_FULL_REASON), action)
+		if err != nil {
+			return nil, err
+		}
+
+		closed = append(closed, *shiftRequest)
+	}
+
+	return closed, nil
+}
+
+// promoteShiftRequestQueue moves waitlisted requests back to PENDING, in queue order, until
+// the pending requests cover the open slots of the shift. A worker who has since taken an
+// overlapping request or another shift that day stays on the waitlist. It returns the
+// promoted requests.
+func (s *shiftService) promoteShiftRequestQueue(shiftRepo repository.ShiftRepository, auditLogRepo repository.AuditLogRepository, shift *model.Shift) ([]model.ShiftRequest, error) {
+	promoted := []model.ShiftRequest{}
+
+	if shift.Status != constants.SHIFT_STATUS_PUBLISHED {
+		return promoted, nil
+	}
+
+	filledSlotCount, err := shiftRepo.GetShiftFilledSlotCount(int64(shift.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	queue, err := s.getShiftRequestQueue(shiftRepo, shift)
+	if err != nil {
+		return nil, err
+	}
+
+	openSlots := shift.Headcount - filledSlotCount
+	for _, item := range queue {
+		if item.Status == constants.SHIFT_REQUEST_STATUS_PENDING {
+			openSlots--
+		}
+	}
+
+	for _, item := range queue {
+		if openSlots <= 0 {
+			break
+		}
+
+		if item.Status != constants.SHIFT_REQUEST_STATUS_WAITLISTED {
+			continue
+		}
+
+		isOverlapping, err := shiftRepo.CheckIfShiftRequestTimeOverlaps(item.UserID, shift.StartTime, shift.EndTime)
+		if err != nil {
+			return nil, err
+		}
+
+		hasShiftOnDate, err := shiftRepo.CheckUserAssignedShiftExistsByDate(item.UserID, shift.Date)
+		if err != nil {
+			return nil, err
+		}
+
+		if isOverlapping || hasShiftOnDate {
+			continue
+		}
+
+		shiftRequest, err := s.updateQueuedShiftRequest(shiftRepo, auditLogRepo, item.ShiftRequest, constants.SHIFT_REQUEST_STATUS_PENDING, null.String{}, constants.AUDIT_ACTION_PROMOTE)
+		if err != nil {
+			return nil, err
+		}
+
+		promoted = append(promoted, *shiftRequest)
+		openSlots--
+	}
+
+	return promoted, nil
+}
+
+// promoteAfterShiftRequestLeft refills the pending requests of a shift from its waitlist once
+// a pending request was rejected or withdrawn.
+func (s *shiftService) promoteAfterShiftRequestLeft(shiftRepo repository.ShiftRepository, auditLogRepo repository.AuditLogRepository, shiftID int64) error {
+	shift, err := shiftRepo.GetByID(shiftID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.promoteShiftRequestQueue(shiftRepo, auditLogRepo, shift)
+
+	return err
+}
+
+// updateQueuedShiftRequest moves a queued request to status on behalf of the system and
+// records the change in the audit trail.
+func (s *shiftService) updateQueuedShiftRequest(shiftRepo repository.ShiftRepository, auditLogRepo repository.AuditLogRepository, shiftRequest model.ShiftRequest, status string, reason null.String, action string) (*model.ShiftRequest, error) {
+	before := shiftRequest
+
+	shiftRequest.Status = status
+	shiftRequest.RejectionReason = reason
+	shiftRequest.AdminActor = null.StringFrom(constants.SYSTEM_ACTOR)
+	if status == constants.SHIFT_REQUEST_STATUS_PENDING {
+		// a promoted request waits for an admin decision again
+		shiftRequest.AdminActor = null.String{}
+	}
+	shiftRequest.UpdatedBy = null.StringFrom(constants.SYSTEM_ACTOR)
+
+	err := shiftRepo.UpdateShiftRequestByID(shiftRequest.ID, &shiftRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := shiftRepo.GetShiftRequestByID(shiftRequest.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT_REQUEST, shiftRequest.ID, action, constants.SYSTEM_ACTOR, before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// ReleaseShiftAssignment takes a worker off a shift. Their approved request is cancelled so
+// it stops blocking them, they are notified, and the freed slot goes to the waitlist.
+func (s *shiftService) ReleaseShiftAssignment(ctx context.Context, req request.ReleaseShiftAssignmentReq) (resp response.ReleaseShiftAssignmentResult, err error) {
+	workerShift, err := s.shiftRepo.GetWorkerShiftByID(req.AssignmentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.cfg.Logger().ErrorWithContext(ctx, "[ReleaseShiftAssignment] Shift assignment not found", zap.Error(err))
+			return resp, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Shift assignment not found")
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "[ReleaseShiftAssignment] Failed to get shift assignment by id", zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift assignment by id")
+	}
+
+	shift, err := loadShift(s.shiftRepo, workerShift.ShiftID)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[ReleaseShiftAssignment] Failed to get shift by id", zap.Int64("shiftID", workerShift.ShiftID), zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift by id")
+	}
+
+	if shift.Status == constants.SHIFT_STATUS_COMPLETED {
+		s.cfg.Logger().ErrorWithContext(ctx, "[ReleaseShiftAssignment] Shift is completed", zap.Int("shiftID", shift.ID))
+		return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Cannot release an assignment of a completed shift")
+	}
+
+	resp = response.ReleaseShiftAssignmentResult{
+		AssignmentID:       workerShift.ID,
+		ShiftID:            workerShift.ShiftID,
+		UserID:             workerShift.UserID,
+		PromotedRequestIDs: []int64{},
+	}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		shiftRepo := s.shiftRepo.WithTx(tx)
+		auditLogRepo := s.auditLogRepo.WithTx(tx)
+
+		err := shiftRepo.EndWorkerShiftByID(workerShift.ID, req.UserEmail)
+		if err != nil {
+			return err
+		}
+
+		err = saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT_ASSIGNMENT, workerShift.ID, constants.AUDIT_ACTION_DELETE, req.UserEmail, workerShift, nil)
+		if err != nil {
+			return err
+		}
+
+		err = saveShiftRemovedEvent(s.eventRepo.WithTx(tx), constants.EVENT_TYPE_SHIFT_RELEASED, workerShift.UserID, shift, constants.EVENT_AFFECTED_ASSIGNMENT, req.Reason, req.UserEmail)
+		if err != nil {
+			return err
+		}
+
+		err = cancelApprovedShiftRequest(shiftRepo, auditLogRepo, workerShift.UserID, workerShift.ShiftID, req.UserEmail)
+		if err != nil {
+			return err
+		}
+
+		promoted, err := s.promoteShiftRequestQueue(shiftRepo, auditLogRepo, shift)
+		if err != nil {
+			return err
+		}
+
+		for _, shiftRequest := range promoted {
+			resp.PromotedRequestIDs = append(resp.PromotedRequestIDs, shiftRequest.ID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[ReleaseShiftAssignment] Failed to release shift assignment", zap.Int64("id", req.AssignmentID), zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to release shift assignment")
+	}
+
+	return resp, nil
+}
+
+// cancelApprovedShiftRequest cancels the approved request behind an assignment that was
+// ended, so it no longer counts in the worker's overlap check.
+func cancelApprovedShiftRequest(shiftRepo repository.ShiftRepository, auditLogRepo repository.AuditLogRepository, userID, shiftID int64, actor string) error {
+	// assignments copied from another roster have no request behind them
+	approved, err := shiftRepo.GetApprovedShiftRequest(userID, shiftID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		return err
+	}
+
+	before := *approved
+
+	approved.Status = constants.SHIFT_REQUEST_STATUS_CANCELLED
+	approved.UpdatedBy = null.StringFrom(actor)
+
+	err = shiftRepo.UpdateShiftRequestByID(approved.ID, approved)
+	if err != nil {
+		return err
+	}
+
+	cancelled, err := shiftRepo.GetShiftRequestByID(approved.ID)
+	if err != nil {
+		return err
+	}
+
+	return saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT_REQUEST, approved.ID, constants.AUDIT_ACTION_CANCEL, actor, before, cancelled)
+}
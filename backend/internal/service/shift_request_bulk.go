@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+)
+
+// BulkApproveShiftRequests approves each request as ApproveShiftRequest would, one at a time
+// and in the order given. Every request is decided in its own transaction, so a failure only
+// affects that request.
+func (s *shiftService) BulkApproveShiftRequests(ctx context.Context, req request.BulkShiftRequestDecisionReq) (response.BulkShiftRequestResult, error) {
+	resp := s.decideShiftRequests(req.RequestIDs, func(id int64) (*model.ShiftRequest, error) {
+		return s.ApproveShiftRequest(ctx, request.ApproveShiftRequestReq{RequestedShiftID: id, UserEmail: req.UserEmail})
+	})
+
+	s.cfg.Logger().InfoWithContext(ctx, "[BulkApproveShiftRequests] Approved shift requests", zap.Int("succeeded", resp.SucceededCount), zap.Int("failed", resp.FailedCount))
+
+	return resp, nil
+}
+
+// BulkRejectShiftRequests rejects each request as RejectShiftRequest would, with the same
+// reason for all of them.
+func (s *shiftService) BulkRejectShiftRequests(ctx context.Context, req request.BulkShiftRequestDecisionReq) (response.BulkShiftRequestResult, error) {
+	resp := s.decideShiftRequests(req.RequestIDs, func(id int64) (*model.ShiftRequest, error) {
+		return s.RejectShiftRequest(ctx, request.RejectShiftRequestReq{RequestedShiftID: id, Reason: req.Reason, UserEmail: req.UserEmail})
+	})
+
+	s.cfg.Logger().InfoWithContext(ctx, "[BulkRejectShiftRequests] Rejected shift requests", zap.Int("succeeded", resp.SucceededCount), zap.Int("failed", resp.FailedCount))
+
+	return resp, nil
+}
+
+// decideShiftRequests runs decide for every distinct id and collects the outcomes. A request
+// listed twice is only decided once.
+func (s *shiftService) decideShiftRequests(ids []int64, decide func(id int64) (*model.ShiftRequest, error)) response.BulkShiftRequestResult {
+	resp := response.BulkShiftRequestResult{
+		Results: []response.BulkShiftRequestOutcome{},
+	}
+
+	seen := map[int64]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		outcome := response.BulkShiftRequestOutcome{RequestID: id}
+
+		shiftRequest, err := decide(id)
+		if err != nil {
+			outcome.Code = response.ServerError.AsString()
+			outcome.Error = "Failed to decide shift request"
+			if oopsErr, ok := oops.AsOops(err); ok {
+				outcome.Code = oopsErr.Code()
+				outcome.Error = err.Error()
+			}
+
+			resp.FailedCount++
+		} else {
+			outcome.Success = true
+			outcome.Status = shiftRequest.Status
+
+			resp.SucceededCount++
+		}
+
+		resp.Results = append(resp.Results, outcome)
+	}
+
+	return resp
+}
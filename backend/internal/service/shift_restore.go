@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/andibalo/payd-test/backend/internal/constants"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/andibalo/payd-test/backend/internal/repository"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/guregu/null/v6"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+)
+
+// RestoreShiftByID takes a shift out of the trash. The pending requests and assignments its
+// deletion undid come back with it as long as the worker still passes the rules that let
+// them in; the rest are reported as skipped.
+func (s *shiftService) RestoreShiftByID(ctx context.Context, req request.RestoreShiftReq) (resp response.RestoreShiftResult, err error) {
+	shift, err := s.shiftRepo.GetDeletedByID(req.ShiftID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.cfg.Logger().ErrorWithContext(ctx, "[RestoreShiftByID] Deleted shift not found", zap.Error(err))
+			return resp, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Deleted shift not found")
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "[RestoreShiftByID] Failed to get deleted shift by id", zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get deleted shift by id")
+	}
+
+	shift.Breaks, err = s.shiftRepo.GetBreaksByShiftID(req.ShiftID)
+	if err == nil {
+		shift.RequiredSkillIDs, err = s.shiftRepo.GetRequiredSkillIDs(req.ShiftID)
+	}
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[RestoreShiftByID] Failed to get shift breaks and skills", zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get deleted shift by id")
+	}
+
+	err = ensureShiftRoleExists(ctx, s.cfg, s.shiftRoleRepo, shift.RoleID, "RestoreShiftByID")
+	if err != nil {
+		return resp, err
+	}
+
+	if shift.LocationID.Valid {
+		_, err = ensureLocationExists(ctx, s.cfg, s.locationRepo, shift.LocationID.Int64, "RestoreShiftByID")
+		if err != nil {
+			return resp, err
+		}
+	}
+
+	isExists, err := s.shiftRepo.CheckIfSameShiftExists(shift)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[RestoreShiftByID] Failed to check if same shift exists", zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to check if same shift exists")
+	}
+
+	if isExists {
+		s.cfg.Logger().ErrorWithContext(ctx, "[RestoreShiftByID] An identical shift already exists", zap.Int64("id", req.ShiftID))
+		return resp, oops.Code(response.Conflict.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusConflict).Errorf("An identical shift already exists")
+	}
+
+	resp = response.RestoreShiftResult{
+		ShiftID:                   shift.ID,
+		RestoredRequestUserIDs:    []int64{},
+		RestoredAssignmentUserIDs: []int64{},
+		Skipped:                   []response.SkippedShiftRestoreData{},
+	}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		shiftRepo := s.shiftRepo.WithTx(tx)
+		eventRepo := s.eventRepo.WithTx(tx)
+		auditLogRepo := s.auditLogRepo.WithTx(tx)
+
+		err := shiftRepo.RestoreByID(req.ShiftID, req.UserEmail)
+		if err != nil {
+			return err
+		}
+
+		restored, err := loadShift(shiftRepo, req.ShiftID)
+		if err != nil {
+			return err
+		}
+
+		err = saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT, req.ShiftID, constants.AUDIT_ACTION_RESTORE, req.UserEmail, shift, restored)
+		if err != nil {
+			return err
+		}
+
+		// the deletion of a cancelled or completed shift had nothing left to undo
+		if restored.Status == constants.SHIFT_STATUS_CANCELLED || restored.Status == constants.SHIFT_STATUS_COMPLETED {
+			return nil
+		}
+
+		err = s.restoreShiftAssignments(shiftRepo, eventRepo, auditLogRepo, shift, restored, req.UserEmail, &resp)
+		if err != nil {
+			return err
+		}
+
+		return s.restoreShiftRequests(shiftRepo, eventRepo, auditLogRepo, shift, restored, req.UserEmail, &resp)
+	})
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[RestoreShiftByID] Failed to restore shift", zap.Int64("id", req.ShiftID), zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to restore shift")
+	}
+
+	return resp, nil
+}
+
+// restoreShiftAssignments brings back the assignments ended when deleted was deleted, for
+// every worker who still passes the headcount, same-day, weekly limit, overlap and skill rules.
+func (s *shiftService) restoreShiftAssignments(shiftRepo repository.ShiftRepository, eventRepo repository.EventRepository, auditLogRepo repository.AuditLogRepository, deleted, shift *model.Shift, actor string, resp *response.RestoreShiftResult) error {
+	workerShifts, err := shiftRepo.GetWorkerShiftsEndedAt(int64(shift.ID), deleted.DeletedBy.String, deleted.DeletedAt.Time)
+	if err != nil {
+		return err
+	}
+
+	filledSlotCount, err := shiftRepo.GetShiftFilledSlotCount(int64(shift.ID))
+	if err != nil {
+		return err
+	}
+
+	for _, workerShift := range workerShifts {
+		skip := func(reason string) {
+			resp.Skipped = append(resp.Skipped, response.SkippedShiftRestoreData{UserID: workerShift.UserID, Affected: constants.EVENT_AFFECTED_ASSIGNMENT, Reason: reason})
+		}
+
+		if filledSlotCount >= shift.Headcount {
+			skip("Shift has no open slots left")
+			continue
+		}
+
+		hasShiftOnDate, err := shiftRepo.CheckUserAssignedShiftExistsByDate(workerShift.UserID, shift.Date)
+		if err != nil {
+			return err
+		}
+
+		if hasShiftOnDate {
+			skip(fmt.Sprintf("Worker already has an assigned shift on %s", shift.Date.Format(constants.DATE_FORMAT)))
+			continue
+		}
+
+		weeklyShiftCount, err := shiftRepo.GetUserWeeklyAssignedShiftCountByDate(workerShift.UserID, shift.Date)
+		if err != nil {
+			return err
+		}
+
+		if weeklyShiftCount >= constants.MAX_ASSIGNED_SHIFT_PER_WEEK {
+			skip("Worker already reached shift assignment limit that week")
+			continue
+		}
+
+		overlaps, err := shiftRepo.CheckIfAssignedShiftTimeOverlaps(workerShift.UserID, shift.StartTime, shift.EndTime)
+		if err != nil {
+			return err
+		}
+
+		if overlaps {
+			skip("Worker already has an assigned shift overlapping this shift")
+			continue
+		}
+
+		missingSkills, expiredSkills, err := checkWorkerSkills(shiftRepo, s.skillRepo, workerShift.UserID, shift)
+		if err != nil {
+			return err
+		}
+
+		if len(missingSkills) > 0 {
+			skip(fmt.Sprintf("Worker lacks required skills: %s", strings.Join(missingSkills, ", ")))
+			continue
+		}
+
+		if len(expiredSkills) > 0 {
+			skip(fmt.Sprintf("Worker certifications expired by the shift date: %s", strings.Join(expiredSkills, ", ")))
+			continue
+		}
+
+		err = shiftRepo.RestoreWorkerShiftByID(workerShift.ID, actor)
+		if err != nil {
+			return err
+		}
+
+		err = saveShiftRestoredEvent(eventRepo, workerShift.UserID, shift, constants.EVENT_AFFECTED_ASSIGNMENT, actor)
+		if err != nil {
+			return err
+		}
+
+		err = saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT_ASSIGNMENT, workerShift.ID, constants.AUDIT_ACTION_RESTORE, actor, nil, workerShift)
+		if err != nil {
+			return err
+		}
+
+		filledSlotCount++
+		resp.RestoredAssignmentUserIDs = append(resp.RestoredAssignmentUserIDs, workerShift.UserID)
+	}
+
+	return nil
+}
+
+// restoreShiftRequests moves the requests cancelled when deleted was deleted back to
+// PENDING, unless the worker has since been assigned that day or requested an overlapping
+// shift.
+func (s *shiftService) restoreShiftRequests(shiftRepo repository.ShiftRepository, eventRepo repository.EventRepository, auditLogRepo repository.AuditLogRepository, deleted, shift *model.Shift, actor string, resp *response.RestoreShiftResult) error {
+	shiftRequests, err := shiftRepo.GetShiftRequestsCancelledAt(int64(shift.ID), deleted.DeletedBy.String, deleted.DeletedAt.Time)
+	if err != nil {
+		return err
+	}
+
+	for _, shiftRequest := range shiftRequests {
+		skip := func(reason string) {
+			resp.Skipped = append(resp.Skipped, response.SkippedShiftRestoreData{UserID: shiftRequest.UserID, Affected: constants.EVENT_AFFECTED_REQUEST, Reason: reason})
+		}
+
+		hasShiftOnDate, err := shiftRepo.CheckUserAssignedShiftExistsByDate(shiftRequest.UserID, shift.Date)
+		if err != nil {
+			return err
+		}
+
+		if hasShiftOnDate {
+			skip(fmt.Sprintf("Worker already has an assigned shift on %s", shift.Date.Format(constants.DATE_FORMAT)))
+			continue
+		}
+
+		isOverlapping, err := shiftRepo.CheckIfShiftRequestTimeOverlaps(shiftRequest.UserID, shift.StartTime, shift.EndTime)
+		if err != nil {
+			return err
+		}
+
+		if isOverlapping {
+			skip("Worker has another request overlapping the shift")
+			continue
+		}
+
+		before := shiftRequest
+		shiftRequest.Status = constants.SHIFT_REQUEST_STATUS_PENDING
+		shiftRequest.AdminActor = null.String{}
+		shiftRequest.UpdatedBy = null.StringFrom(actor)
+
+		err = shiftRepo.UpdateShiftRequestByID(shiftRequest.ID, &shiftRequest)
+		if err != nil {
+			return err
+		}
+
+		after, err := shiftRepo.GetShiftRequestByID(shiftRequest.ID)
+		if err != nil {
+			return err
+		}
+
+		err = saveShiftRestoredEvent(eventRepo, shiftRequest.UserID, shift, constants.EVENT_AFFECTED_REQUEST, actor)
+		if err != nil {
+			return err
+		}
+
+		err = saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT_REQUEST, shiftRequest.ID, constants.AUDIT_ACTION_RESTORE, actor, before, after)
+		if err != nil {
+			return err
+		}
+
+		resp.RestoredRequestUserIDs = append(resp.RestoredRequestUserIDs, shiftRequest.UserID)
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/andibalo/payd-test/backend/internal/repository"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/guregu/null/v6"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+)
+
+type shiftRoleService struct {
+	cfg           config.Config
+	shiftRoleRepo repository.ShiftRoleRepository
+}
+
+func NewShiftRoleService(cfg config.Config, shiftRoleRepo repository.ShiftRoleRepository) ShiftRoleService {
+
+	return &shiftRoleService{
+		cfg:           cfg,
+		shiftRoleRepo: shiftRoleRepo,
+	}
+}
+
+func (s *shiftRoleService) GetShiftRoleList(ctx context.Context, req request.GetShiftRoleListReq) ([]model.ShiftRoleEnum, error) {
+
+	filter := repository.GetShiftRoleListFilter{
+		IncludeDeleted: req.IncludeDeleted,
+	}
+
+	roles, err := s.shiftRoleRepo.GetList(filter)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftRoleList] Failed to get shift role list", zap.Any("filter", filter), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift role list")
+	}
+
+	return roles, nil
+}
+
+func (s *shiftRoleService) CreateShiftRole(ctx context.Context, req request.CreateShiftRoleReq) (*model.ShiftRoleEnum, error) {
+
+	roleName := strings.TrimSpace(req.RoleName)
+	if roleName == "" {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftRole] Role name is empty")
+		return nil, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Role name is required")
+	}
+
+	err := s.checkRoleNameAvailable(ctx, roleName, 0, "CreateShiftRole")
+	if err != nil {
+		return nil, err
+	}
+
+	role := &model.ShiftRoleEnum{
+		RoleName:   roleName,
+		HourlyRate: null.FloatFromPtr(req.HourlyRate),
+		CreatedBy:  req.UserEmail,
+	}
+
+	err = s.shiftRoleRepo.Save(role)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftRole] Failed to create shift role", zap.String("roleName", roleName), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to create shift role")
+	}
+
+	return s.getShiftRole(ctx, role.ID, "CreateShiftRole")
+}
+
+func (s *shiftRoleService) UpdateShiftRoleByID(ctx context.Context, id int64, req request.UpdateShiftRoleReq) (*model.ShiftRoleEnum, error) {
+
+	role, err := s.getShiftRole(ctx, id, "UpdateShiftRoleByID")
+	if err != nil {
+		return nil, err
+	}
+
+	if role.DeletedAt.Valid {
+		s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftRoleByID] Shift role is deleted", zap.Int64("id", id))
+		return nil, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Shift role not found")
+	}
+
+	roleName := strings.TrimSpace(req.RoleName)
+	if roleName == "" {
+		s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftRoleByID] Role name is empty")
+		return nil, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Role name is required")
+	}
+
+	err = s.checkRoleNameAvailable(ctx, roleName, id, "UpdateShiftRoleByID")
+	if err != nil {
+		return nil, err
+	}
+
+	role.RoleName = roleName
+	role.HourlyRate = null.FloatFromPtr(req.HourlyRate)
+	role.UpdatedBy = null.StringFrom(req.UserEmail)
+
+	err = s.shiftRoleRepo.UpdateByID(id, role)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftRoleByID] Failed to update shift role", zap.Int64("id", id), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to update shift role")
+	}
+
+	return s.getShiftRole(ctx, id, "UpdateShiftRoleByID")
+}
+
+func (s *shiftRoleService) DeleteShiftRoleByID(ctx context.Context, id int64, deletedBy string) error {
+
+	role, err := s.getShiftRole(ctx, id, "DeleteShiftRoleByID")
+	if err != nil {
+		return err
+	}
+
+	if role.DeletedAt.Valid {
+		s.cfg.Logger().ErrorWithContext(ctx, "[DeleteShiftRoleByID] Shift role is already deleted", zap.Int64("id", id))
+		return oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Shift role not found")
+	}
+
+	activeShiftCount, err := s.shiftRoleRepo.CountActiveShiftsByRoleID(id)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[DeleteShiftRoleByID] Failed to count active shifts using role", zap.Int64("id", id), zap.Error(err))
+		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to count active shifts using role")
+	}
+
+	if activeShiftCount > 0 {
+		s.cfg.Logger().ErrorWithContext(ctx, "[DeleteShiftRoleByID] Shift role is still used by active shifts", zap.Int64("id", id), zap.Int("activeShiftCount", activeShiftCount))
+		return oops.Code(response.Conflict.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusConflict).Errorf("Shift role is still used by %d active shifts", activeShiftCount)
+	}
+
+	err = s.shiftRoleRepo.DeleteByID(id, deletedBy)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[DeleteShiftRoleByID] Failed to delete shift role", zap.Int64("id", id), zap.String("deletedBy", deletedBy), zap.Error(err))
+		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to delete shift role")
+	}
+
+	return nil
+}
+
+func (s *shiftRoleService) RestoreShiftRoleByID(ctx context.Context, id int64, restoredBy string) (*model.ShiftRoleEnum, error) {
+
+	role, err := s.getShiftRole(ctx, id, "RestoreShiftRoleByID")
+	if err != nil {
+		return nil, err
+	}
+
+	if !role.DeletedAt.Valid {
+		s.cfg.Logger().ErrorWithContext(ctx, "[RestoreShiftRoleByID] Shift role is not deleted", zap.Int64("id", id))
+		return nil, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Shift role is not deleted")
+	}
+
+	err = s.shiftRoleRepo.RestoreByID(id, restoredBy)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[RestoreShiftRoleByID] Failed to restore shift role", zap.Int64("id", id), zap.String("restoredBy", restoredBy), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to restore shift role")
+	}
+
+	return s.getShiftRole(ctx, id, "RestoreShiftRoleByID")
+}
+
+func (s *shiftRoleService) getShiftRole(ctx context.Context, id int64, caller string) (*model.ShiftRoleEnum, error) {
+	role, err := s.shiftRoleRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Shift role not found", zap.Int64("id", id))
+			return nil, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Shift role not found")
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to get shift role by id", zap.Int64("id", id), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift role by id")
+	}
+
+	return role, nil
+}
+
+// checkRoleNameAvailable rejects names that collide case-insensitively with another role.
+// Deleted roles still hold their name, so the caller is pointed at restore instead.
+func (s *shiftRoleService) checkRoleNameAvailable(ctx context.Context, roleName string, excludeID int64, caller string) error {
+	existing, err := s.shiftRoleRepo.GetByName(roleName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to get shift role by name", zap.String("roleName", roleName), zap.Error(err))
+		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift role by name")
+	}
+
+	if existing.ID == excludeID {
+		return nil
+	}
+
+	if existing.DeletedAt.Valid {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Shift role name belongs to a deleted role", zap.String("roleName", roleName), zap.Int64("existingID", existing.ID))
+		return oops.Code(response.Conflict.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusConflict).Errorf("Shift role %q exists but is deleted, restore role %d instead", existing.RoleName, existing.ID)
+	}
+
+	s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Shift role name already exists", zap.String("roleName", roleName), zap.Int64("existingID", existing.ID))
+	return oops.Code(response.Conflict.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusConflict).Errorf("Shift role %q already exists", existing.RoleName)
+}
+
+// ensureShiftRoleExists is shared by the services that accept a role_id, so a shift
+// can never point at a role that was never created or has since been deleted.
+func ensureShiftRoleExists(ctx context.Context, cfg config.Config, shiftRoleRepo repository.ShiftRoleRepository, roleID int, caller string) error {
+	role, err := shiftRoleRepo.GetByID(int64(roleID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Shift role not found", zap.Int("roleID", roleID))
+			return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Shift role %d does not exist", roleID)
+		}
+
+		cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to get shift role by id", zap.Int("roleID", roleID), zap.Error(err))
+		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift role by id")
+	}
+
+	if role.DeletedAt.Valid {
+		cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Shift role is deleted", zap.Int("roleID", roleID))
+		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Shift role %d has been deleted", roleID)
+	}
+
+	return nil
+}
@@ -0,0 +1,857 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/constants"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/andibalo/payd-test/backend/internal/repository"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/andibalo/payd-test/backend/pkg/rrule"
+	"github.com/guregu/null/v6"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+type shiftSeriesService struct {
+	cfg           config.Config
+	transactor    repository.Transactor
+	seriesRepo    repository.ShiftSeriesRepository
+	shiftRepo     repository.ShiftRepository
+	shiftRoleRepo repository.ShiftRoleRepository
+	locationRepo  repository.LocationRepository
+	eventRepo     repository.EventRepository
+	auditLogRepo  repository.AuditLogRepository
+}
+
+func NewShiftSeriesService(cfg config.Config, transactor repository.Transactor, seriesRepo repository.ShiftSeriesRepository, shiftRepo repository.ShiftRepository, shiftRoleRepo repository.ShiftRoleRepository, locationRepo repository.LocationRepository, eventRepo repository.EventRepository, auditLogRepo repository.AuditLogRepository) ShiftSeriesService {
+
+	return &shiftSeriesService{
+		cfg:           cfg,
+		transactor:    transactor,
+		seriesRepo:    seriesRepo,
+		shiftRepo:     shiftRepo,
+		shiftRoleRepo: shiftRoleRepo,
+		locationRepo:  locationRepo,
+		eventRepo:     eventRepo,
+		auditLogRepo:  auditLogRepo,
+	}
+}
+
+func (s *shiftSeriesService) CreateShiftSeries(ctx context.Context, req request.CreateShiftSeriesReq) (resp response.ShiftSeriesChangeResult, err error) {
+
+	rule, err := rrule.Parse(req.RRule)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftSeries] Invalid rrule", zap.String("rrule", req.RRule), zap.Error(err))
+		return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Invalid rrule: %v", err)
+	}
+
+	startDate, err := time.Parse(constants.DATE_FORMAT, req.StartDate)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftSeries] Invalid start date", zap.String("start_date", req.StartDate), zap.Error(err))
+		return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("start_date should be formatted as YYYY-MM-DD")
+	}
+
+	err = validateTimeOfDay(req.StartTime, req.EndTime)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftSeries] Invalid shift time", zap.Error(err))
+		return resp, err
+	}
+
+	err = ensureShiftRoleExists(ctx, s.cfg, s.shiftRoleRepo, req.RoleID, "CreateShiftSeries")
+	if err != nil {
+		return resp, err
+	}
+
+	loc, err := resolveShiftTimezone(ctx, s.cfg, s.locationRepo, req.LocationID, req.Timezone, "CreateShiftSeries")
+	if err != nil {
+		return resp, err
+	}
+
+	series := &model.ShiftSeries{
+		RRule:      rule.String(),
+		StartDate:  startDate,
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		RoleID:     req.RoleID,
+		LocationID: null.NewInt(req.LocationID, req.LocationID != 0),
+		Timezone:   loc.String(),
+		Headcount:  req.Headcount,
+		IsActive:   true,
+		CreatedBy:  req.UserEmail,
+	}
+
+	if series.Headcount == 0 {
+		series.Headcount = 1
+	}
+
+	for _, date := range req.ExceptionDates {
+		exceptionDate, err := time.Parse(constants.DATE_FORMAT, date)
+		if err != nil {
+			s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftSeries] Invalid exception date", zap.String("date", date), zap.Error(err))
+			return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("exception_dates should be formatted as YYYY-MM-DD")
+		}
+
+		series.AddExceptionDate(exceptionDate.Format(constants.DATE_FORMAT))
+	}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		seriesRepo := s.seriesRepo.WithTx(tx)
+		shiftRepo := s.shiftRepo.WithTx(tx)
+
+		err := seriesRepo.Save(series)
+		if err != nil {
+			return err
+		}
+
+		resp.CreatedShiftIDs, err = s.generateOccurrences(shiftRepo, series, rule, series.StartDate, req.UserEmail)
+
+		return err
+	})
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftSeries] Failed to create shift series", zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to create shift series")
+	}
+
+	resp.SeriesID = series.ID
+
+	return resp, nil
+}
+
+func (s *shiftSeriesService) GetShiftSeriesByID(ctx context.Context, id int64) (*model.ShiftSeries, error) {
+
+	series, err := s.getSeries(ctx, id, "GetShiftSeriesByID")
+	if err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+func (s *shiftSeriesService) GetShiftSeriesList(ctx context.Context, req request.GetShiftSeriesListReq) (resp response.GetShiftSeriesListResponse, err error) {
+
+	filter := repository.GetShiftSeriesListFilter{
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}
+
+	seriesList, pagination, err := s.seriesRepo.GetList(filter)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftSeriesList] Failed to get shift series list", zap.Any("filter", filter), zap.Error(err))
+		return resp, err
+	}
+
+	resp = response.GetShiftSeriesListResponse{
+		Data: seriesList,
+		Meta: response.PaginationMeta{
+			CurrentPage: pagination.CurrentPage,
+			TotalPages:  pagination.TotalPages,
+			TotalItems:  pagination.TotalElements,
+		},
+	}
+
+	return resp, nil
+}
+
+func (s *shiftSeriesService) UpdateShiftSeries(ctx context.Context, id int64, req request.UpdateShiftSeriesReq) (resp response.ShiftSeriesChangeResult, err error) {
+
+	series, err := s.getSeries(ctx, id, "UpdateShiftSeries")
+	if err != nil {
+		return resp, err
+	}
+
+	rule, err := rrule.Parse(series.RRule)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftSeries] Stored rrule is invalid", zap.Int64("id", id), zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Stored rrule is invalid")
+	}
+
+	occurrenceDate, err := s.parseScope(ctx, req.Scope, req.OccurrenceDate, "UpdateShiftSeries")
+	if err != nil {
+		return resp, err
+	}
+
+	template := *series
+	newRule := rule
+
+	if req.RRule != "" {
+		newRule, err = rrule.Parse(req.RRule)
+		if err != nil {
+			s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftSeries] Invalid rrule", zap.String("rrule", req.RRule), zap.Error(err))
+			return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Invalid rrule: %v", err)
+		}
+	}
+
+	if req.StartTime != "" {
+		template.StartTime = req.StartTime
+	}
+
+	if req.EndTime != "" {
+		template.EndTime = req.EndTime
+	}
+
+	if req.RoleID != 0 {
+		err = ensureShiftRoleExists(ctx, s.cfg, s.shiftRoleRepo, req.RoleID, "UpdateShiftSeries")
+		if err != nil {
+			return resp, err
+		}
+
+		template.RoleID = req.RoleID
+	}
+
+	if req.LocationID != 0 {
+		template.LocationID = null.IntFrom(req.LocationID)
+	}
+
+	if req.LocationID != 0 || req.Timezone != "" {
+		loc, err := resolveShiftTimezone(ctx, s.cfg, s.locationRepo, template.LocationID.Int64, req.Timezone, "UpdateShiftSeries")
+		if err != nil {
+			return resp, err
+		}
+
+		template.Timezone = loc.String()
+	}
+
+	if req.Headcount != 0 {
+		template.Headcount = req.Headcount
+	}
+
+	err = validateTimeOfDay(template.StartTime, template.EndTime)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftSeries] Invalid shift time", zap.Error(err))
+		return resp, err
+	}
+
+	scope := req.Scope
+	if scope == constants.SHIFT_SERIES_SCOPE_FOLLOWING && !occurrenceDate.After(series.StartDate) {
+		scope = constants.SHIFT_SERIES_SCOPE_ALL
+	}
+
+	resp.SeriesID = series.ID
+
+	switch scope {
+	case constants.SHIFT_SERIES_SCOPE_THIS:
+		shift, err := s.getOccurrence(ctx, series.ID, occurrenceDate, "UpdateShiftSeries")
+		if err != nil {
+			return resp, err
+		}
+
+		updated := *shift
+		s.applyTemplate(&updated, &template, req.UserEmail)
+
+		err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+			conflict, err := occurrenceUpdateConflict(s.shiftRepo.WithTx(tx), shift, &updated)
+			if err != nil {
+				return err
+			}
+
+			if conflict != "" {
+				s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftSeries] Occurrence update conflicts with its assignments", zap.Int("shift_id", shift.ID), zap.String("conflict", conflict))
+				return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("%s", conflict)
+			}
+
+			// the edited occurrence no longer follows the template, so later series edits
+			// leave it as it is
+			series.AddExceptionDate(occurrenceDate.Format(constants.DATE_FORMAT))
+			series.UpdatedBy = null.StringFrom(req.UserEmail)
+
+			err = s.seriesRepo.WithTx(tx).UpdateByID(series.ID, series)
+			if err != nil {
+				return err
+			}
+
+			return s.updateOccurrence(tx, shift, &updated, req.UserEmail)
+		})
+		if err != nil {
+			if _, ok := oops.AsOops(err); ok {
+				return resp, err
+			}
+
+			s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftSeries] Failed to update occurrence", zap.Int("shift_id", shift.ID), zap.Error(err))
+			return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to update occurrence")
+		}
+
+		resp.UpdatedShiftIDs = []int{shift.ID}
+
+	case constants.SHIFT_SERIES_SCOPE_FOLLOWING:
+		err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+			seriesRepo := s.seriesRepo.WithTx(tx)
+
+			newSeries := template
+			newSeries.StartDate = occurrenceDate
+			newSeries.ExceptionDates = null.String{}
+			newSeries.CreatedBy = req.UserEmail
+
+			for _, date := range series.ExceptionDateList() {
+				if date >= occurrenceDate.Format(constants.DATE_FORMAT) {
+					newSeries.AddExceptionDate(date)
+				}
+			}
+
+			newSeriesRule := *newRule
+			if req.RRule == "" && rule.Count > 0 {
+				newSeriesRule.Count = rule.Count - rule.CountBefore(series.StartDate, occurrenceDate)
+			}
+			newSeries.RRule = newSeriesRule.String()
+
+			err := s.endSeriesBefore(seriesRepo, series, rule, occurrenceDate, req.UserEmail)
+			if err != nil {
+				return err
+			}
+
+			err = seriesRepo.Save(&newSeries)
+			if err != nil {
+				return err
+			}
+
+			err = s.shiftRepo.WithTx(tx).ReassignSeries(series.ID, newSeries.ID, occurrenceDate)
+			if err != nil {
+				return err
+			}
+
+			resp.SeriesID = newSeries.ID
+
+			return s.reconcileOccurrences(tx, &newSeries, &newSeriesRule, occurrenceDate, req.UserEmail, &resp)
+		})
+
+	case constants.SHIFT_SERIES_SCOPE_ALL:
+		err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+			seriesRepo := s.seriesRepo.WithTx(tx)
+
+			template.RRule = newRule.String()
+			template.UpdatedBy = null.StringFrom(req.UserEmail)
+
+			err := seriesRepo.UpdateByID(series.ID, &template)
+			if err != nil {
+				return err
+			}
+
+			from := today()
+			if series.StartDate.After(from) {
+				from = series.StartDate
+			}
+
+			return s.reconcileOccurrences(tx, &template, newRule, from, req.UserEmail, &resp)
+		})
+	}
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[UpdateShiftSeries] Failed to update shift series", zap.Int64("id", id), zap.String("scope", scope), zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to update shift series")
+	}
+
+	return resp, nil
+}
+
+func (s *shiftSeriesService) CancelShiftSeries(ctx context.Context, id int64, req request.CancelShiftSeriesReq) (resp response.ShiftSeriesChangeResult, err error) {
+
+	series, err := s.getSeries(ctx, id, "CancelShiftSeries")
+	if err != nil {
+		return resp, err
+	}
+
+	rule, err := rrule.Parse(series.RRule)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CancelShiftSeries] Stored rrule is invalid", zap.Int64("id", id), zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Stored rrule is invalid")
+	}
+
+	occurrenceDate, err := s.parseScope(ctx, req.Scope, req.OccurrenceDate, "CancelShiftSeries")
+	if err != nil {
+		return resp, err
+	}
+
+	scope := req.Scope
+	if scope == constants.SHIFT_SERIES_SCOPE_FOLLOWING && !occurrenceDate.After(series.StartDate) {
+		scope = constants.SHIFT_SERIES_SCOPE_ALL
+	}
+
+	resp.SeriesID = series.ID
+
+	switch scope {
+	case constants.SHIFT_SERIES_SCOPE_THIS:
+		shifts, err := s.shiftRepo.GetBySeriesID(series.ID, occurrenceDate)
+		if err != nil {
+			s.cfg.Logger().ErrorWithContext(ctx, "[CancelShiftSeries] Failed to get series shifts", zap.Int64("id", id), zap.Error(err))
+			return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get series shifts")
+		}
+
+		var occurrence *model.Shift
+		for i := range shifts {
+			if sameDate(shifts[i].Date, occurrenceDate) {
+				occurrence = &shifts[i]
+				break
+			}
+		}
+
+		if occurrence != nil {
+			isAssigned, err := s.shiftRepo.CheckIfShiftIsAlreadyAssigned(int64(occurrence.ID))
+			if err != nil {
+				s.cfg.Logger().ErrorWithContext(ctx, "[CancelShiftSeries] Failed to check if shift already assigned", zap.Error(err))
+				return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to check if shift already assigned")
+			}
+
+			if isAssigned {
+				s.cfg.Logger().ErrorWithContext(ctx, "[CancelShiftSeries] Occurrence is already assigned", zap.Int("shift_id", occurrence.ID))
+				return resp, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Occurrence is already assigned to a worker")
+			}
+		}
+
+		err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+			series.AddExceptionDate(occurrenceDate.Format(constants.DATE_FORMAT))
+			series.UpdatedBy = null.StringFrom(req.UserEmail)
+
+			err := s.seriesRepo.WithTx(tx).UpdateByID(series.ID, series)
+			if err != nil {
+				return err
+			}
+
+			if occurrence == nil {
+				return nil
+			}
+
+			resp.CancelledShiftIDs = []int{occurrence.ID}
+
+			return s.deleteOccurrence(tx, occurrence.ID, req.UserEmail)
+		})
+
+	case constants.SHIFT_SERIES_SCOPE_FOLLOWING:
+		err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+			err := s.endSeriesBefore(s.seriesRepo.WithTx(tx), series, rule, occurrenceDate, req.UserEmail)
+			if err != nil {
+				return err
+			}
+
+			return s.cancelOccurrencesFrom(tx, series.ID, occurrenceDate, req.UserEmail, &resp)
+		})
+
+	case constants.SHIFT_SERIES_SCOPE_ALL:
+		err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+			err := s.cancelOccurrencesFrom(tx, series.ID, today(), req.UserEmail, &resp)
+			if err != nil {
+				return err
+			}
+
+			return s.seriesRepo.WithTx(tx).DeleteByID(series.ID, req.UserEmail)
+		})
+	}
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CancelShiftSeries] Failed to cancel shift series", zap.Int64("id", id), zap.String("scope", scope), zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to cancel shift series")
+	}
+
+	return resp, nil
+}
+
+func (s *shiftSeriesService) GenerateShiftSeries(ctx context.Context, id int64, generatedBy string) (resp response.ShiftSeriesChangeResult, err error) {
+
+	series, err := s.getSeries(ctx, id, "GenerateShiftSeries")
+	if err != nil {
+		return resp, err
+	}
+
+	rule, err := rrule.Parse(series.RRule)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GenerateShiftSeries] Stored rrule is invalid", zap.Int64("id", id), zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Stored rrule is invalid")
+	}
+
+	from := today()
+	if series.StartDate.After(from) {
+		from = series.StartDate
+	}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		resp.CreatedShiftIDs, err = s.generateOccurrences(s.shiftRepo.WithTx(tx), series, rule, from, generatedBy)
+		return err
+	})
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GenerateShiftSeries] Failed to generate shifts", zap.Int64("id", id), zap.Error(err))
+		return resp, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to generate shifts")
+	}
+
+	resp.SeriesID = series.ID
+
+	return resp, nil
+}
+
+func (s *shiftSeriesService) getSeries(ctx context.Context, id int64, caller string) (*model.ShiftSeries, error) {
+	series, err := s.seriesRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Shift series not found", zap.Int64("id", id), zap.Error(err))
+			return nil, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Shift series not found")
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to get shift series by id", zap.Int64("id", id), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift series by id")
+	}
+
+	return series, nil
+}
+
+func (s *shiftSeriesService) getOccurrence(ctx context.Context, seriesID int64, date time.Time, caller string) (*model.Shift, error) {
+	shifts, err := s.shiftRepo.GetBySeriesID(seriesID, date)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to get series shifts", zap.Int64("series_id", seriesID), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get series shifts")
+	}
+
+	for i := range shifts {
+		if sameDate(shifts[i].Date, date) {
+			return &shifts[i], nil
+		}
+	}
+
+	s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Occurrence not found", zap.Int64("series_id", seriesID), zap.Time("date", date))
+	return nil, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Occurrence not found")
+}
+
+func (s *shiftSeriesService) parseScope(ctx context.Context, scope, occurrenceDate, caller string) (time.Time, error) {
+	switch scope {
+	case constants.SHIFT_SERIES_SCOPE_THIS, constants.SHIFT_SERIES_SCOPE_FOLLOWING:
+		date, err := time.Parse(constants.DATE_FORMAT, occurrenceDate)
+		if err != nil {
+			s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Invalid occurrence date", zap.String("occurrence_date", occurrenceDate), zap.Error(err))
+			return time.Time{}, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("occurrence_date is required and should be formatted as YYYY-MM-DD")
+		}
+
+		return date, nil
+	case constants.SHIFT_SERIES_SCOPE_ALL:
+		return time.Time{}, nil
+	}
+
+	s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Invalid scope", zap.String("scope", scope))
+	return time.Time{}, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("scope should be one of THIS, FOLLOWING or ALL")
+}
+
+// endSeriesBefore closes a series the day before date. A COUNT based rule is
+// converted to an UNTIL so the occurrences already generated stay valid.
+func (s *shiftSeriesService) endSeriesBefore(seriesRepo repository.ShiftSeriesRepository, series *model.ShiftSeries, rule *rrule.Rule, date time.Time, updatedBy string) error {
+	until := date.AddDate(0, 0, -1)
+
+	endedRule := *rule
+	endedRule.Count = 0
+	endedRule.Until = &until
+
+	series.RRule = endedRule.String()
+	series.UpdatedBy = null.StringFrom(updatedBy)
+
+	return seriesRepo.UpdateByID(series.ID, series)
+}
+
+// generateOccurrences inserts the shifts of a series from the given date up to the
+// generation horizon, skipping dates that already have a shift row or are excluded.
+func (s *shiftSeriesService) generateOccurrences(shiftRepo repository.ShiftRepository, series *model.ShiftSeries, rule *rrule.Rule, from time.Time, createdBy string) ([]int, error) {
+	createdShiftIDs := []int{}
+
+	existingDates, err := shiftRepo.GetSeriesOccurrenceDates(series.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, date := range series.ExceptionDateList() {
+		existingDates[date] = true
+	}
+
+	for _, date := range rule.Between(series.StartDate, from, s.horizonEnd(series)) {
+		if existingDates[date.Format(constants.DATE_FORMAT)] {
+			continue
+		}
+
+		shift := &model.Shift{
+			Date:      date,
+			SeriesID:  null.IntFrom(series.ID),
+			IsActive:  true,
+			Status:    constants.SHIFT_STATUS_DRAFT,
+			CreatedBy: createdBy,
+		}
+
+		s.applyTemplate(shift, series, "")
+
+		err := shiftRepo.Save(shift)
+		if err != nil {
+			return nil, err
+		}
+
+		createdShiftIDs = append(createdShiftIDs, shift.ID)
+	}
+
+	return createdShiftIDs, nil
+}
+
+// reconcileOccurrences brings the shifts of a series from the given date in line with
+// its template and rule. Occurrences edited on their own sit on an exception date and are
+// left alone. Occurrences that no longer match the rule are cancelled unless a worker is
+// already assigned, in which case they are kept as they are. So are assigned occurrences
+// the new template would leave short of slots or in breach of a worker's daily or weekly
+// limit.
+func (s *shiftSeriesService) reconcileOccurrences(tx *sql.Tx, series *model.ShiftSeries, rule *rrule.Rule, from time.Time, actor string, resp *response.ShiftSeriesChangeResult) error {
+	shiftRepo := s.shiftRepo.WithTx(tx)
+
+	existing, err := shiftRepo.GetBySeriesID(series.ID, from)
+	if err != nil {
+		return err
+	}
+
+	to := s.horizonEnd(series)
+	for _, shift := range existing {
+		if shift.Date.After(to) {
+			to = shift.Date
+		}
+	}
+
+	wanted := map[string]bool{}
+	for _, date := range rule.Between(series.StartDate, from, to) {
+		wanted[date.Format(constants.DATE_FORMAT)] = true
+	}
+
+	exceptions := map[string]bool{}
+	for _, date := range series.ExceptionDateList() {
+		delete(wanted, date)
+		exceptions[date] = true
+	}
+
+	for i := range existing {
+		shift := &existing[i]
+
+		// an occurrence that still exists on an exception date was edited on its own
+		if exceptions[shift.Date.Format(constants.DATE_FORMAT)] {
+			continue
+		}
+
+		if wanted[shift.Date.Format(constants.DATE_FORMAT)] {
+			updated := *shift
+			s.applyTemplate(&updated, series, actor)
+
+			conflict, err := occurrenceUpdateConflict(shiftRepo, shift, &updated)
+			if err != nil {
+				return err
+			}
+
+			if conflict != "" {
+				resp.KeptShiftIDs = append(resp.KeptShiftIDs, shift.ID)
+				continue
+			}
+
+			err = s.updateOccurrence(tx, shift, &updated, actor)
+			if err != nil {
+				return err
+			}
+
+			resp.UpdatedShiftIDs = append(resp.UpdatedShiftIDs, shift.ID)
+			continue
+		}
+
+		isAssigned, err := shiftRepo.CheckIfShiftIsAlreadyAssigned(int64(shift.ID))
+		if err != nil {
+			return err
+		}
+
+		if isAssigned {
+			resp.KeptShiftIDs = append(resp.KeptShiftIDs, shift.ID)
+			continue
+		}
+
+		err = s.deleteOccurrence(tx, shift.ID, actor)
+		if err != nil {
+			return err
+		}
+
+		resp.CancelledShiftIDs = append(resp.CancelledShiftIDs, shift.ID)
+	}
+
+	resp.CreatedShiftIDs, err = s.generateOccurrences(shiftRepo, series, rule, from, actor)
+
+	return err
+}
+
+func (s *shiftSeriesService) cancelOccurrencesFrom(tx *sql.Tx, seriesID int64, from time.Time, actor string, resp *response.ShiftSeriesChangeResult) error {
+	shiftRepo := s.shiftRepo.WithTx(tx)
+
+	shifts, err := shiftRepo.GetBySeriesID(seriesID, from)
+	if err != nil {
+		return err
+	}
+
+	for _, shift := range shifts {
+		isAssigned, err := shiftRepo.CheckIfShiftIsAlreadyAssigned(int64(shift.ID))
+		if err != nil {
+			return err
+		}
+
+		if isAssigned {
+			resp.KeptShiftIDs = append(resp.KeptShiftIDs, shift.ID)
+			continue
+		}
+
+		err = s.deleteOccurrence(tx, shift.ID, actor)
+		if err != nil {
+			return err
+		}
+
+		resp.CancelledShiftIDs = append(resp.CancelledShiftIDs, shift.ID)
+	}
+
+	return nil
+}
+
+// occurrenceUpdateConflict reports why updated, the template applied to current, would
+// break the assignments of current, or returns "" when it would not. The same-day and
+// weekly counts already include the occurrence, so they only need checking when it moves
+// to another date, which happens when the series timezone changes.
+func occurrenceUpdateConflict(shiftRepo repository.ShiftRepository, current, updated *model.Shift) (string, error) {
+	filledSlotCount, err := shiftRepo.GetShiftFilledSlotCount(int64(current.ID))
+	if err != nil || filledSlotCount == 0 {
+		return "", err
+	}
+
+	if updated.Headcount < filledSlotCount {
+		return fmt.Sprintf("Headcount cannot be lower than the %d slots already filled", filledSlotCount), nil
+	}
+
+	if sameDate(current.Date, updated.Date) {
+		return "", nil
+	}
+
+	userIDs, err := shiftRepo.GetShiftAssigneeIDs(int64(current.ID))
+	if err != nil {
+		return "", err
+	}
+
+	for _, userID := range userIDs {
+		hasShiftOnDate, err := shiftRepo.CheckUserAssignedShiftExistsByDate(userID, updated.Date)
+		if err != nil {
+			return "", err
+		}
+
+		if hasShiftOnDate {
+			return fmt.Sprintf("Worker %d already has an assigned shift on %s", userID, updated.Date.Format(constants.DATE_FORMAT)), nil
+		}
+
+		if sameDate(weekStart(current.Date), weekStart(updated.Date)) {
+			continue
+		}
+
+		weeklyShiftCount, err := shiftRepo.GetUserWeeklyAssignedShiftCountByDate(userID, updated.Date)
+		if err != nil {
+			return "", err
+		}
+
+		if weeklyShiftCount >= constants.MAX_ASSIGNED_SHIFT_PER_WEEK {
+			return fmt.Sprintf("Worker %d already reached shift assignment limit that week", userID), nil
+		}
+	}
+
+	return "", nil
+}
+
+// updateOccurrence saves an occurrence the series template was applied to and audits the
+// change.
+func (s *shiftSeriesService) updateOccurrence(tx *sql.Tx, current, updated *model.Shift, actor string) error {
+	err := s.shiftRepo.WithTx(tx).UpdateByID(int64(updated.ID), updated)
+	if err != nil {
+		return err
+	}
+
+	return saveAuditLog(s.auditLogRepo.WithTx(tx), constants.AUDIT_ENTITY_SHIFT, int64(updated.ID), constants.AUDIT_ACTION_UPDATE, actor, current, updated)
+}
+
+// deleteOccurrence removes an occurrence the same way DeleteShiftByID removes a shift, so
+// its requests are cancelled, the workers notified and the removal can be restored.
+func (s *shiftSeriesService) deleteOccurrence(tx *sql.Tx, id int, actor string) error {
+	shiftRepo := s.shiftRepo.WithTx(tx)
+
+	shift, err := loadShift(shiftRepo, int64(id))
+	if err != nil {
+		return err
+	}
+
+	return deleteShift(shiftRepo, s.eventRepo.WithTx(tx), s.auditLogRepo.WithTx(tx), shift, actor)
+}
+
+// applyTemplate copies the series template onto an occurrence, keeping its date.
+func (s *shiftSeriesService) applyTemplate(shift *model.Shift, series *model.ShiftSeries, updatedBy string) {
+	loc := seriesLocation(series)
+	start, end := occurrenceTimes(shift.Date, series.StartTime, series.EndTime, loc)
+	shift.SetSchedule(start, end, loc)
+	shift.RoleID = series.RoleID
+	shift.LocationID = series.LocationID
+	shift.Headcount = series.Headcount
+
+	if updatedBy != "" {
+		shift.UpdatedBy = null.StringFrom(updatedBy)
+	}
+}
+
+func (s *shiftSeriesService) horizonEnd(series *model.ShiftSeries) time.Time {
+	base := today()
+	if series.StartDate.After(base) {
+		base = series.StartDate
+	}
+
+	return base.AddDate(0, 0, s.cfg.GetShiftCfg().SeriesHorizonDays)
+}
+
+// occurrenceTimes combines a date with HH:MM start and end times in the series timezone.
+// An end time that is not after the start time is treated as ending on the following day.
+func occurrenceTimes(date time.Time, startTime, endTime string, loc *time.Location) (time.Time, time.Time) {
+	start := atTimeOfDay(date, startTime, loc)
+	end := atTimeOfDay(date, endTime, loc)
+
+	if !end.After(start) {
+		end = atTimeOfDay(date.AddDate(0, 0, 1), endTime, loc)
+	}
+
+	return start, end
+}
+
+func atTimeOfDay(date time.Time, timeOfDay string, loc *time.Location) time.Time {
+	t, _ := time.Parse(constants.TIME_OF_DAY_FORMAT, timeOfDay)
+
+	return time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+}
+
+// seriesLocation loads the series timezone. It is validated when the series is saved,
+// so UTC is only a fallback for rows written before series had a timezone.
+func seriesLocation(series *model.ShiftSeries) *time.Location {
+	loc, err := time.LoadLocation(series.Timezone)
+	if err != nil || series.Timezone == "" {
+		return time.UTC
+	}
+
+	return loc
+}
+
+func validateTimeOfDay(times ...string) error {
+	for _, t := range times {
+		_, err := time.Parse(constants.TIME_OF_DAY_FORMAT, t)
+		if err != nil {
+			return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("start_time and end_time should be formatted as HH:MM")
+		}
+	}
+
+	return nil
+}
+
+func sameDate(a, b time.Time) bool {
+	return a.Format(constants.DATE_FORMAT) == b.Format(constants.DATE_FORMAT)
+}
+
+// weekStart returns the Monday of the week date falls in, the week the weekly assignment
+// limit counts.
+func weekStart(date time.Time) time.Time {
+	return date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+}
+
+func today() time.Time {
+	now := time.Now().UTC()
+
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestOccurrenceTimes(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatalf("LoadLocation returned error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		date      string
+		startTime string
+		endTime   string
+		loc       *time.Location
+		wantStart string
+		wantEnd   string
+	}{
+		{"day shift in winter time", "2025-03-29", "09:00", "17:00", amsterdam, "2025-03-29T08:00:00Z", "2025-03-29T16:00:00Z"},
+		{"day shift in summer time", "2025-03-30", "09:00", "17:00", amsterdam, "2025-03-30T07:00:00Z", "2025-03-30T15:00:00Z"},
+		{"overnight shift across the spring change", "2025-03-29", "22:00", "06:00", amsterdam, "2025-03-29T21:00:00Z", "2025-03-30T04:00:00Z"},
+		{"overnight shift across the autumn change", "2025-10-25", "22:00", "06:00", amsterdam, "2025-10-25T20:00:00Z", "2025-10-26T05:00:00Z"},
+		{"equal times last a full day", "2025-01-10", "09:00", "09:00", time.UTC, "2025-01-10T09:00:00Z", "2025-01-11T09:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, err := time.Parse("2006-01-02", tt.date)
+			if err != nil {
+				t.Fatalf("invalid test date %q: %v", tt.date, err)
+			}
+
+			start, end := occurrenceTimes(date, tt.startTime, tt.endTime, tt.loc)
+
+			if got := start.UTC().Format(time.RFC3339); got != tt.wantStart {
+				t.Errorf("start = %s, want %s", got, tt.wantStart)
+			}
+
+			if got := end.UTC().Format(time.RFC3339); got != tt.wantEnd {
+				t.Errorf("end = %s, want %s", got, tt.wantEnd)
+			}
+		})
+	}
+}
@@ -0,0 +1,606 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/constants"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/andibalo/payd-test/backend/internal/repository"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/pkg"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/guregu/null/v6"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// errShiftSwapDryRun rolls back a transaction that only checked whether a swap could go
+// through.
+var errShiftSwapDryRun = errors.New("shift swap dry run")
+
+type shiftSwapService struct {
+	cfg           config.Config
+	transactor    repository.Transactor
+	shiftSwapRepo repository.ShiftSwapRepository
+	shiftRepo     repository.ShiftRepository
+	skillRepo     repository.SkillRepository
+	eventRepo     repository.EventRepository
+	auditLogRepo  repository.AuditLogRepository
+}
+
+func NewShiftSwapService(cfg config.Config, transactor repository.Transactor, shiftSwapRepo repository.ShiftSwapRepository, shiftRepo repository.ShiftRepository, skillRepo repository.SkillRepository, eventRepo repository.EventRepository, auditLogRepo repository.AuditLogRepository) ShiftSwapService {
+
+	return &shiftSwapService{
+		cfg:           cfg,
+		transactor:    transactor,
+		shiftSwapRepo: shiftSwapRepo,
+		shiftRepo:     shiftRepo,
+		skillRepo:     skillRepo,
+		eventRepo:     eventRepo,
+		auditLogRepo:  auditLogRepo,
+	}
+}
+
+// CreateShiftSwap offers the caller's assignment in exchange for another worker's. The
+// exchange is checked up front, so a swap that could never go through is not proposed.
+func (s *shiftSwapService) CreateShiftSwap(ctx context.Context, req request.CreateShiftSwapReq) (*model.ShiftSwap, error) {
+	proposerAssignment, err := s.getAssignment(ctx, req.AssignmentID, "CreateShiftSwap")
+	if err != nil {
+		return nil, err
+	}
+
+	if proposerAssignment.UserID != req.UserID {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftSwap] Assignment belongs to another user", zap.Int64("userID", req.UserID), zap.Int64("assignmentUserID", proposerAssignment.UserID))
+		return nil, oops.Code(response.Forbidden.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusForbidden).Errorf("Only the assigned worker can offer a shift for swap")
+	}
+
+	recipientAssignment, err := s.getAssignment(ctx, req.TargetAssignmentID, "CreateShiftSwap")
+	if err != nil {
+		return nil, err
+	}
+
+	if recipientAssignment.UserID == req.UserID {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftSwap] Target assignment belongs to the proposer", zap.Int64("userID", req.UserID))
+		return nil, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Cannot swap shifts with yourself")
+	}
+
+	if recipientAssignment.ShiftID == proposerAssignment.ShiftID {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftSwap] Both assignments are for the same shift", zap.Int64("shiftID", proposerAssignment.ShiftID))
+		return nil, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Both assignments are for the same shift")
+	}
+
+	for _, assignmentID := range []int64{proposerAssignment.ID, recipientAssignment.ID} {
+		hasOpenSwap, err := s.shiftSwapRepo.CheckOpenSwapExistsByAssignmentID(assignmentID)
+		if err != nil {
+			s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftSwap] Failed to check open shift swap exists", zap.Int64("assignmentID", assignmentID), zap.Error(err))
+			return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to check open shift swap exists")
+		}
+
+		if hasOpenSwap {
+			s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftSwap] Assignment already has an open swap", zap.Int64("assignmentID", assignmentID))
+			return nil, oops.Code(response.Conflict.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusConflict).Errorf("Assignment %d already has an open swap", assignmentID)
+		}
+	}
+
+	swap := req.ToModel(proposerAssignment, recipientAssignment)
+
+	err = s.checkShiftSwap(ctx, swap, req.UserEmail, "CreateShiftSwap")
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		shiftSwapRepo := s.shiftSwapRepo.WithTx(tx)
+
+		err := shiftSwapRepo.Save(swap)
+		if err != nil {
+			return err
+		}
+
+		swap, err = shiftSwapRepo.GetByID(swap.ID)
+		if err != nil {
+			return err
+		}
+
+		return saveAuditLog(s.auditLogRepo.WithTx(tx), constants.AUDIT_ENTITY_SHIFT_SWAP, swap.ID, constants.AUDIT_ACTION_CREATE, req.UserEmail, nil, swap)
+	})
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CreateShiftSwap] Failed to create shift swap", zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to create shift swap")
+	}
+
+	return swap, nil
+}
+
+func (s *shiftSwapService) GetShiftSwapList(ctx context.Context, req request.GetShiftSwapListReq) ([]model.ShiftSwap, error) {
+
+	filter := repository.GetShiftSwapListFilter{
+		UserID: req.UserID,
+		Status: req.Status,
+	}
+
+	swaps, err := s.shiftSwapRepo.GetList(filter)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftSwapList] Failed to get shift swap list", zap.Any("filter", filter), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift swap list")
+	}
+
+	return swaps, nil
+}
+
+// GetShiftSwapByID returns the swap to an admin, or to a worker on either side of it.
+// userID is zero for admins.
+func (s *shiftSwapService) GetShiftSwapByID(ctx context.Context, id int64, userID int64) (*model.ShiftSwap, error) {
+	swap, err := s.getShiftSwap(ctx, id, "GetShiftSwapByID")
+	if err != nil {
+		return nil, err
+	}
+
+	if userID != 0 && userID != swap.ProposerID && userID != swap.RecipientID {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetShiftSwapByID] Shift swap belongs to other users", zap.Int64("id", id), zap.Int64("userID", userID))
+		return nil, oops.Code(response.Forbidden.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusForbidden).Errorf("Only the workers of a shift swap can view it")
+	}
+
+	return swap, nil
+}
+
+// AcceptShiftSwap lets the recipient agree to a proposed swap. The assignments are
+// exchanged right away, unless swaps need an admin's approval.
+func (s *shiftSwapService) AcceptShiftSwap(ctx context.Context, req request.ShiftSwapActionReq) (*model.ShiftSwap, error) {
+	swap, err := s.getShiftSwapForAction(ctx, req.SwapID, req.Version, constants.SHIFT_SWAP_STATUS_PROPOSED, "AcceptShiftSwap")
+	if err != nil {
+		return nil, err
+	}
+
+	if swap.RecipientID != req.UserID {
+		s.cfg.Logger().ErrorWithContext(ctx, "[AcceptShiftSwap] Shift swap was offered to another user", zap.Int64("userID", req.UserID), zap.Int64("recipientID", swap.RecipientID))
+		return nil, oops.Code(response.Forbidden.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusForbidden).Errorf("Only the recipient can accept a shift swap")
+	}
+
+	if !s.cfg.GetShiftCfg().SwapRequiresApproval {
+		return s.completeShiftSwap(ctx, swap, constants.AUDIT_ACTION_ACCEPT, req.UserEmail, "AcceptShiftSwap")
+	}
+
+	// an admin still has to approve, so only make sure the exchange can go through
+	err = s.checkShiftSwap(ctx, swap, req.UserEmail, "AcceptShiftSwap")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.updateShiftSwapStatus(ctx, swap, constants.SHIFT_SWAP_STATUS_ACCEPTED, constants.AUDIT_ACTION_ACCEPT, null.String{}, req.UserEmail, "AcceptShiftSwap")
+}
+
+func (s *shiftSwapService) DeclineShiftSwap(ctx context.Context, req request.ShiftSwapActionReq) (*model.ShiftSwap, error) {
+	swap, err := s.getShiftSwapForAction(ctx, req.SwapID, req.Version, constants.SHIFT_SWAP_STATUS_PROPOSED, "DeclineShiftSwap")
+	if err != nil {
+		return nil, err
+	}
+
+	if swap.RecipientID != req.UserID {
+		s.cfg.Logger().ErrorWithContext(ctx, "[DeclineShiftSwap] Shift swap was offered to another user", zap.Int64("userID", req.UserID), zap.Int64("recipientID", swap.RecipientID))
+		return nil, oops.Code(response.Forbidden.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusForbidden).Errorf("Only the recipient can decline a shift swap")
+	}
+
+	return s.updateShiftSwapStatus(ctx, swap, constants.SHIFT_SWAP_STATUS_DECLINED, constants.AUDIT_ACTION_DECLINE, null.String{}, req.UserEmail, "DeclineShiftSwap")
+}
+
+// CancelShiftSwap lets the proposer take back a swap that has not gone through yet.
+func (s *shiftSwapService) CancelShiftSwap(ctx context.Context, req request.ShiftSwapActionReq) (*model.ShiftSwap, error) {
+	swap, err := s.getShiftSwap(ctx, req.SwapID, "CancelShiftSwap")
+	if err != nil {
+		return nil, err
+	}
+
+	if swap.ProposerID != req.UserID {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CancelShiftSwap] Shift swap was proposed by another user", zap.Int64("userID", req.UserID), zap.Int64("proposerID", swap.ProposerID))
+		return nil, oops.Code(response.Forbidden.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusForbidden).Errorf("Only the proposer can cancel a shift swap")
+	}
+
+	if req.Version != 0 && req.Version != swap.Version {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CancelShiftSwap] Shift swap version does not match If-Match", zap.Int("expected", req.Version), zap.Int("current", swap.Version))
+		return nil, shiftSwapVersionErr(swap, response.PreconditionFailed, http.StatusPreconditionFailed)
+	}
+
+	if swap.Status != constants.SHIFT_SWAP_STATUS_PROPOSED && swap.Status != constants.SHIFT_SWAP_STATUS_ACCEPTED {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CancelShiftSwap] Shift swap is no longer open", zap.String("status", swap.Status))
+		return nil, shiftSwapStatusErr(swap, "Only a proposed or accepted shift swap can be cancelled")
+	}
+
+	return s.updateShiftSwapStatus(ctx, swap, constants.SHIFT_SWAP_STATUS_CANCELLED, constants.AUDIT_ACTION_CANCEL, null.String{}, req.UserEmail, "CancelShiftSwap")
+}
+
+// ApproveShiftSwap exchanges the assignments of a swap the recipient accepted.
+func (s *shiftSwapService) ApproveShiftSwap(ctx context.Context, req request.ShiftSwapActionReq) (*model.ShiftSwap, error) {
+	swap, err := s.getShiftSwapForAction(ctx, req.SwapID, req.Version, constants.SHIFT_SWAP_STATUS_ACCEPTED, "ApproveShiftSwap")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.completeShiftSwap(ctx, swap, constants.AUDIT_ACTION_APPROVE, req.UserEmail, "ApproveShiftSwap")
+}
+
+func (s *shiftSwapService) RejectShiftSwap(ctx context.Context, req request.RejectShiftSwapReq) (*model.ShiftSwap, error) {
+	swap, err := s.getShiftSwapForAction(ctx, req.SwapID, req.Version, constants.SHIFT_SWAP_STATUS_ACCEPTED, "RejectShiftSwap")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.updateShiftSwapStatus(ctx, swap, constants.SHIFT_SWAP_STATUS_REJECTED, constants.AUDIT_ACTION_REJECT, null.StringFrom(req.Reason), req.UserEmail, "RejectShiftSwap")
+}
+
+// getShiftSwapForAction returns the swap when it is at the If-Match version and in the
+// status the action needs.
+func (s *shiftSwapService) getShiftSwapForAction(ctx context.Context, id int64, version int, status string, caller string) (*model.ShiftSwap, error) {
+	swap, err := s.getShiftSwap(ctx, id, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != 0 && version != swap.Version {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Shift swap version does not match If-Match", zap.Int("expected", version), zap.Int("current", swap.Version))
+		return nil, shiftSwapVersionErr(swap, response.PreconditionFailed, http.StatusPreconditionFailed)
+	}
+
+	if swap.Status != status {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Shift swap is not "+status, zap.String("status", swap.Status))
+		return nil, shiftSwapStatusErr(swap, "Shift swap status is not "+status)
+	}
+
+	return swap, nil
+}
+
+// updateShiftSwapStatus moves a swap to a status that does not touch any assignment.
+func (s *shiftSwapService) updateShiftSwapStatus(ctx context.Context, swap *model.ShiftSwap, status, action string, reason null.String, actor string, caller string) (*model.ShiftSwap, error) {
+	before := *swap
+
+	swap.Status = status
+	swap.RejectionReason = reason
+	swap.UpdatedBy = null.StringFrom(actor)
+
+	if status == constants.SHIFT_SWAP_STATUS_REJECTED {
+		swap.AdminActor = null.StringFrom(actor)
+	}
+
+	err := s.transactor.WithinTx(func(tx *sql.Tx) error {
+		shiftSwapRepo := s.shiftSwapRepo.WithTx(tx)
+
+		err := shiftSwapRepo.UpdateByID(swap.ID, swap)
+		if err != nil {
+			return err
+		}
+
+		swap, err = shiftSwapRepo.GetByID(swap.ID)
+		if err != nil {
+			return err
+		}
+
+		return saveAuditLog(s.auditLogRepo.WithTx(tx), constants.AUDIT_ENTITY_SHIFT_SWAP, swap.ID, action, actor, &before, swap)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Shift swap was modified concurrently", zap.Int64("id", before.ID))
+			return nil, s.shiftSwapConflictErr(ctx, before.ID, caller)
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to update shift swap", zap.Int64("id", before.ID), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to update shift swap")
+	}
+
+	return swap, nil
+}
+
+// checkShiftSwap runs the exchange in a transaction that is always rolled back, so the
+// caller learns whether it would pass without anything changing.
+func (s *shiftSwapService) checkShiftSwap(ctx context.Context, swap *model.ShiftSwap, actor string, caller string) error {
+	err := s.transactor.WithinTx(func(tx *sql.Tx) error {
+		_, _, err := s.exchangeShiftAssignments(ctx, tx, swap, actor, caller)
+		if err != nil {
+			return err
+		}
+
+		return errShiftSwapDryRun
+	})
+	if errors.Is(err, errShiftSwapDryRun) {
+		return nil
+	}
+
+	return s.shiftSwapExchangeErr(ctx, err, caller)
+}
+
+// completeShiftSwap exchanges the assignments and marks the swap COMPLETED in one
+// transaction, then tells both workers about their new shift.
+func (s *shiftSwapService) completeShiftSwap(ctx context.Context, swap *model.ShiftSwap, action, actor string, caller string) (*model.ShiftSwap, error) {
+	before := *swap
+
+	err := s.transactor.WithinTx(func(tx *sql.Tx) error {
+		shiftSwapRepo := s.shiftSwapRepo.WithTx(tx)
+		eventRepo := s.eventRepo.WithTx(tx)
+
+		proposerShift, recipientShift, err := s.exchangeShiftAssignments(ctx, tx, swap, actor, caller)
+		if err != nil {
+			return err
+		}
+
+		swap.Status = constants.SHIFT_SWAP_STATUS_COMPLETED
+		swap.UpdatedBy = null.StringFrom(actor)
+
+		if action == constants.AUDIT_ACTION_APPROVE {
+			swap.AdminActor = null.StringFrom(actor)
+		}
+
+		err = shiftSwapRepo.UpdateByID(swap.ID, swap)
+		if err != nil {
+			return err
+		}
+
+		swap, err = shiftSwapRepo.GetByID(swap.ID)
+		if err != nil {
+			return err
+		}
+
+		err = saveAuditLog(s.auditLogRepo.WithTx(tx), constants.AUDIT_ENTITY_SHIFT_SWAP, swap.ID, action, actor, &before, swap)
+		if err != nil {
+			return err
+		}
+
+		err = saveShiftSwappedEvent(eventRepo, swap.ID, swap.ProposerID, proposerShift, recipientShift, actor)
+		if err != nil {
+			return err
+		}
+
+		return saveShiftSwappedEvent(eventRepo, swap.ID, swap.RecipientID, recipientShift, proposerShift, actor)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Shift swap was modified concurrently", zap.Int64("id", before.ID))
+			return nil, s.shiftSwapConflictErr(ctx, before.ID, caller)
+		}
+
+		return nil, s.shiftSwapExchangeErr(ctx, err, caller)
+	}
+
+	s.cfg.Logger().InfoWithContext(ctx, "["+caller+"] Completed shift swap", zap.Int64("id", swap.ID), zap.Int64("proposerID", swap.ProposerID), zap.Int64("recipientID", swap.RecipientID))
+
+	return swap, nil
+}
+
+// exchangeShiftAssignments ends both assignments of the swap and gives each worker the
+// other's shift, once both new schedules pass the same rules an approval does. It returns
+// the shifts the proposer and the recipient gave up.
+func (s *shiftSwapService) exchangeShiftAssignments(ctx context.Context, tx *sql.Tx, swap *model.ShiftSwap, actor string, caller string) (*model.Shift, *model.Shift, error) {
+	shiftRepo := s.shiftRepo.WithTx(tx)
+	skillRepo := s.skillRepo.WithTx(tx)
+	auditLogRepo := s.auditLogRepo.WithTx(tx)
+
+	proposerAssignment, proposerShift, err := s.getSwappableAssignment(ctx, shiftRepo, swap.ProposerAssignmentID, swap.ProposerID, caller)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	recipientAssignment, recipientShift, err := s.getSwappableAssignment(ctx, shiftRepo, swap.RecipientAssignmentID, swap.RecipientID, caller)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, assignment := range []*model.WorkerShift{proposerAssignment, recipientAssignment} {
+		err = shiftRepo.EndWorkerShiftByID(assignment.ID, actor)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT_ASSIGNMENT, assignment.ID, constants.AUDIT_ACTION_DELETE, actor, assignment, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = cancelApprovedShiftRequest(shiftRepo, auditLogRepo, assignment.UserID, assignment.ShiftID, actor)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// both old assignments are gone, so each side is checked against the schedule it ends up with
+	err = s.validateSwappedAssignment(ctx, shiftRepo, skillRepo, swap.ProposerID, recipientShift, "Proposer", caller)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = s.validateSwappedAssignment(ctx, shiftRepo, skillRepo, swap.RecipientID, proposerShift, "Recipient", caller)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newAssignments := []*model.WorkerShift{
+		{UserID: swap.ProposerID, ShiftID: swap.RecipientShiftID, AssignedBy: actor, CreatedBy: actor},
+		{UserID: swap.RecipientID, ShiftID: swap.ProposerShiftID, AssignedBy: actor, CreatedBy: actor},
+	}
+
+	for _, assignment := range newAssignments {
+		err = shiftRepo.SaveWorkerShift(assignment)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = saveAuditLog(auditLogRepo, constants.AUDIT_ENTITY_SHIFT_ASSIGNMENT, assignment.ID, constants.AUDIT_ACTION_CREATE, actor, nil, assignment)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return proposerShift, recipientShift, nil
+}
+
+// getSwappableAssignment returns the assignment and its shift while the assignment is still
+// held by the worker and its shift is published and has not started.
+func (s *shiftSwapService) getSwappableAssignment(ctx context.Context, shiftRepo repository.ShiftRepository, assignmentID, userID int64, caller string) (*model.WorkerShift, *model.Shift, error) {
+	assignment, err := shiftRepo.GetWorkerShiftByID(assignmentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Shift assignment no longer exists", zap.Int64("assignmentID", assignmentID))
+			return nil, nil, oops.Code(response.Conflict.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusConflict).Errorf("Shift assignment %d no longer exists", assignmentID)
+		}
+
+		return nil, nil, err
+	}
+
+	if assignment.UserID != userID {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Shift assignment changed hands", zap.Int64("assignmentID", assignmentID), zap.Int64("userID", assignment.UserID))
+		return nil, nil, oops.Code(response.Conflict.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusConflict).Errorf("Shift assignment %d no longer belongs to the worker", assignmentID)
+	}
+
+	shift, err := loadShift(shiftRepo, assignment.ShiftID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if shift.Status != constants.SHIFT_STATUS_PUBLISHED || !shift.StartTime.After(time.Now()) {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Shift can no longer be swapped", zap.Int("shiftID", shift.ID), zap.String("status", shift.Status), zap.Time("startTime", shift.StartTime))
+		return nil, nil, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Shift %d can no longer be swapped", shift.ID)
+	}
+
+	return assignment, shift, nil
+}
+
+// validateSwappedAssignment checks the worker can take the shift: no other assigned shift
+// that day or overlapping it, room under the weekly limit and the required skills. side
+// names the worker in the error.
+func (s *shiftSwapService) validateSwappedAssignment(ctx context.Context, shiftRepo repository.ShiftRepository, skillRepo repository.SkillRepository, userID int64, shift *model.Shift, side string, caller string) error {
+	hasShiftOnDate, err := shiftRepo.CheckUserAssignedShiftExistsByDate(userID, shift.Date)
+	if err != nil {
+		return err
+	}
+
+	if hasShiftOnDate {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Worker already has an assigned shift on this day", zap.Int64("userID", userID), zap.Int("shiftID", shift.ID))
+		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("%s already has an assigned shift on %s", side, shift.Date.Format(constants.DATE_FORMAT))
+	}
+
+	overlaps, err := shiftRepo.CheckIfAssignedShiftTimeOverlaps(userID, shift.StartTime, shift.EndTime)
+	if err != nil {
+		return err
+	}
+
+	if overlaps {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Swapped shift overlaps an assigned shift", zap.Int64("userID", userID), zap.Int("shiftID", shift.ID))
+		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("%s has an assigned shift overlapping shift %d", side, shift.ID)
+	}
+
+	weeklyShiftCount, err := shiftRepo.GetUserWeeklyAssignedShiftCountByDate(userID, shift.Date)
+	if err != nil {
+		return err
+	}
+
+	if weeklyShiftCount >= constants.MAX_ASSIGNED_SHIFT_PER_WEEK {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Worker already reached shift assignment limit that week", zap.Int64("userID", userID), zap.Int("weeklyShiftCount", weeklyShiftCount))
+		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("%s already reached shift assignment limit that week", side)
+	}
+
+	return ensureWorkerEligibleForShift(ctx, s.cfg, shiftRepo, skillRepo, userID, shift, caller)
+}
+
+// shiftSwapExchangeErr passes on the rule an exchange broke, and reports anything else as a
+// server error.
+func (s *shiftSwapService) shiftSwapExchangeErr(ctx context.Context, err error, caller string) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, repository.ErrAssignmentExists) {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Worker is already assigned to the swapped shift", zap.Error(err))
+		return oops.Code(response.Conflict.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusConflict).Errorf("Worker is already assigned to the swapped shift")
+	}
+
+	if _, ok := oops.AsOops(err); ok {
+		return err
+	}
+
+	s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to exchange shift assignments", zap.Error(err))
+	return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to exchange shift assignments")
+}
+
+func (s *shiftSwapService) getAssignment(ctx context.Context, id int64, caller string) (*model.WorkerShift, error) {
+	assignment, err := s.shiftRepo.GetWorkerShiftByID(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Shift assignment not found", zap.Int64("id", id))
+			return nil, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Shift assignment %d not found", id)
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to get shift assignment by id", zap.Int64("id", id), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift assignment by id")
+	}
+
+	return assignment, nil
+}
+
+func (s *shiftSwapService) getShiftSwap(ctx context.Context, id int64, caller string) (*model.ShiftSwap, error) {
+	swap, err := s.shiftSwapRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Shift swap not found", zap.Int64("id", id))
+			return nil, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Shift swap not found")
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to get shift swap by id", zap.Int64("id", id), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get shift swap by id")
+	}
+
+	return swap, nil
+}
+
+// shiftSwapConflictErr reports that a swap changed between our read and our write,
+// carrying the swap as it is now.
+func (s *shiftSwapService) shiftSwapConflictErr(ctx context.Context, id int64, caller string) error {
+	current, err := s.getShiftSwap(ctx, id, caller)
+	if err != nil {
+		return err
+	}
+
+	return shiftSwapVersionErr(current, response.Conflict, http.StatusConflict)
+}
+
+func shiftSwapVersionErr(current *model.ShiftSwap, code response.Code, statusCode int) error {
+	return oops.Code(code.AsString()).
+		With(httpresp.StatusCodeCtxKey, statusCode).
+		With(httpresp.CurrentStateCtxKey, current).
+		With(httpresp.ETagCtxKey, pkg.FormatETag(current.Version)).
+		Errorf("Shift swap was modified by another request, current version is %d", current.Version)
+}
+
+func shiftSwapStatusErr(current *model.ShiftSwap, msg string) error {
+	return oops.Code(response.Conflict.AsString()).
+		With(httpresp.StatusCodeCtxKey, http.StatusConflict).
+		With(httpresp.CurrentStateCtxKey, current).
+		With(httpresp.ETagCtxKey, pkg.FormatETag(current.Version)).
+		Errorf("%s", msg)
+}
+
+// saveShiftSwappedEvent writes an outbox event telling a worker which shift they received
+// for the one they gave up.
+func saveShiftSwappedEvent(eventRepo repository.EventRepository, swapID, userID int64, given, received *model.Shift, actor string) error {
+	payload, err := json.Marshal(model.ShiftSwappedPayload{
+		SwapID:          swapID,
+		GivenShiftID:    int64(given.ID),
+		ReceivedShiftID: int64(received.ID),
+		StartTime:       received.StartTime,
+		EndTime:         received.EndTime,
+		Timezone:        received.Timezone,
+	})
+	if err != nil {
+		return err
+	}
+
+	return eventRepo.Save(&model.Event{
+		EventType: constants.EVENT_TYPE_SHIFT_SWAPPED,
+		UserID:    userID,
+		ShiftID:   null.IntFrom(int64(received.ID)),
+		Payload:   string(payload),
+		CreatedBy: actor,
+	})
+}
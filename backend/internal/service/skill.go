@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/constants"
+	"github.com/andibalo/payd-test/backend/internal/model"
+	"github.com/andibalo/payd-test/backend/internal/repository"
+	"github.com/andibalo/payd-test/backend/internal/request"
+	"github.com/andibalo/payd-test/backend/internal/response"
+	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/guregu/null/v6"
+	"github.com/samber/oops"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type skillService struct {
+	cfg       config.Config
+	skillRepo repository.SkillRepository
+	userRepo  repository.UserRepository
+}
+
+func NewSkillService(cfg config.Config, skillRepo repository.SkillRepository, userRepo repository.UserRepository) SkillService {
+
+	return &skillService{
+		cfg:       cfg,
+		skillRepo: skillRepo,
+		userRepo:  userRepo,
+	}
+}
+
+func (s *skillService) GetSkillList(ctx context.Context, req request.GetSkillListReq) ([]model.Skill, error) {
+
+	filter := repository.GetSkillListFilter{
+		IncludeDeleted: req.IncludeDeleted,
+	}
+
+	skills, err := s.skillRepo.GetList(filter)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetSkillList] Failed to get skill list", zap.Any("filter", filter), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get skill list")
+	}
+
+	return skills, nil
+}
+
+func (s *skillService) CreateSkill(ctx context.Context, req request.CreateSkillReq) (*model.Skill, error) {
+
+	skill := req.ToModel()
+
+	err := s.validateSkillName(ctx, skill.Name, 0, "CreateSkill")
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.skillRepo.Save(skill)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[CreateSkill] Failed to create skill", zap.String("name", skill.Name), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to create skill")
+	}
+
+	return s.getSkill(ctx, skill.ID, "CreateSkill")
+}
+
+func (s *skillService) UpdateSkillByID(ctx context.Context, id int64, req request.UpdateSkillReq) (*model.Skill, error) {
+
+	_, err := s.getLiveSkill(ctx, id, "UpdateSkillByID")
+	if err != nil {
+		return nil, err
+	}
+
+	skill := req.ToModel()
+
+	err = s.validateSkillName(ctx, skill.Name, id, "UpdateSkillByID")
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.skillRepo.UpdateByID(id, skill)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[UpdateSkillByID] Failed to update skill", zap.Int64("id", id), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to update skill")
+	}
+
+	return s.getSkill(ctx, id, "UpdateSkillByID")
+}
+
+// DeleteSkillByID soft deletes a skill. Shifts that still list it no longer require it,
+// since eligibility only checks skills that have not been deleted.
+func (s *skillService) DeleteSkillByID(ctx context.Context, id int64, deletedBy string) error {
+
+	_, err := s.getLiveSkill(ctx, id, "DeleteSkillByID")
+	if err != nil {
+		return err
+	}
+
+	err = s.skillRepo.DeleteByID(id, deletedBy)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[DeleteSkillByID] Failed to delete skill", zap.Int64("id", id), zap.String("deletedBy", deletedBy), zap.Error(err))
+		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to delete skill")
+	}
+
+	return nil
+}
+
+func (s *skillService) GetWorkerSkills(ctx context.Context, userID int64) ([]model.WorkerSkill, error) {
+
+	_, err := s.getWorker(ctx, userID, "GetWorkerSkills")
+	if err != nil {
+		return nil, err
+	}
+
+	workerSkills, err := s.skillRepo.GetWorkerSkillsByUserID(userID)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[GetWorkerSkills] Failed to get worker skills", zap.Int64("userID", userID), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get worker skills")
+	}
+
+	return workerSkills, nil
+}
+
+// AssignWorkerSkill grants a skill to a worker. Granting a skill the worker already holds
+// renews its expiry date.
+func (s *skillService) AssignWorkerSkill(ctx context.Context, req request.AssignWorkerSkillReq) ([]model.WorkerSkill, error) {
+
+	_, err := s.getWorker(ctx, req.UserID, "AssignWorkerSkill")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.getLiveSkill(ctx, req.SkillID, "AssignWorkerSkill")
+	if err != nil {
+		return nil, err
+	}
+
+	workerSkill := &model.WorkerSkill{
+		UserID:    req.UserID,
+		SkillID:   req.SkillID,
+		CreatedBy: req.UserEmail,
+	}
+
+	if req.ExpiresAt != "" {
+		expiresAt, err := time.Parse(constants.DATE_FORMAT, req.ExpiresAt)
+		if err != nil {
+			s.cfg.Logger().ErrorWithContext(ctx, "[AssignWorkerSkill] Invalid expiry date", zap.String("expiresAt", req.ExpiresAt), zap.Error(err))
+			return nil, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("expires_at must be a date in YYYY-MM-DD format")
+		}
+
+		workerSkill.ExpiresAt = null.TimeFrom(expiresAt)
+	}
+
+	err = s.skillRepo.SaveWorkerSkill(workerSkill)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[AssignWorkerSkill] Failed to save worker skill", zap.Int64("userID", req.UserID), zap.Int64("skillID", req.SkillID), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to save worker skill")
+	}
+
+	return s.GetWorkerSkills(ctx, req.UserID)
+}
+
+func (s *skillService) RemoveWorkerSkill(ctx context.Context, userID, skillID int64, deletedBy string) error {
+
+	removed, err := s.skillRepo.DeleteWorkerSkill(userID, skillID, deletedBy)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[RemoveWorkerSkill] Failed to remove worker skill", zap.Int64("userID", userID), zap.Int64("skillID", skillID), zap.Error(err))
+		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to remove worker skill")
+	}
+
+	if !removed {
+		s.cfg.Logger().ErrorWithContext(ctx, "[RemoveWorkerSkill] Worker does not hold skill", zap.Int64("userID", userID), zap.Int64("skillID", skillID))
+		return oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Worker skill not found")
+	}
+
+	return nil
+}
+
+func (s *skillService) getSkill(ctx context.Context, id int64, caller string) (*model.Skill, error) {
+	skill, err := s.skillRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Skill not found", zap.Int64("id", id))
+			return nil, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Skill not found")
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to get skill by id", zap.Int64("id", id), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get skill by id")
+	}
+
+	return skill, nil
+}
+
+func (s *skillService) getLiveSkill(ctx context.Context, id int64, caller string) (*model.Skill, error) {
+	skill, err := s.getSkill(ctx, id, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	if skill.DeletedAt.Valid {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Skill is deleted", zap.Int64("id", id))
+		return nil, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("Skill not found")
+	}
+
+	return skill, nil
+}
+
+func (s *skillService) getWorker(ctx context.Context, userID int64, caller string) (*model.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] User not found", zap.Int64("userID", userID))
+			return nil, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("User not found")
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to get user by id", zap.Int64("userID", userID), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get user by id")
+	}
+
+	if user.Role != constants.WORKER_ROLE {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] User is not a worker", zap.Int64("userID", userID), zap.String("role", user.Role))
+		return nil, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("User %d is not a worker", userID)
+	}
+
+	return user, nil
+}
+
+// validateSkillName checks the name is set and does not collide case-insensitively with
+// another skill, deleted ones included.
+func (s *skillService) validateSkillName(ctx context.Context, name string, excludeID int64, caller string) error {
+	if name == "" {
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Skill name is empty")
+		return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Skill name is required")
+	}
+
+	existing, err := s.skillRepo.GetByName(name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to get skill by name", zap.String("name", name), zap.Error(err))
+		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get skill by name")
+	}
+
+	if existing.ID == excludeID {
+		return nil
+	}
+
+	s.cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Skill name already exists", zap.String("name", name), zap.Int64("existingID", existing.ID))
+	return oops.Code(response.Conflict.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusConflict).Errorf("Skill %q already exists", existing.Name)
+}
+
+// ensureSkillsExist is used by the shift service so a shift can only require skills that
+// exist and have not been deleted.
+func ensureSkillsExist(ctx context.Context, cfg config.Config, skillRepo repository.SkillRepository, skillIDs []int64, caller string) error {
+	for _, skillID := range skillIDs {
+		skill, err := skillRepo.GetByID(skillID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Skill not found", zap.Int64("skillID", skillID))
+				return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Skill %d does not exist", skillID)
+			}
+
+			cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to get skill by id", zap.Int64("skillID", skillID), zap.Error(err))
+			return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get skill by id")
+		}
+
+		if skill.DeletedAt.Valid {
+			cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Skill is deleted", zap.Int64("skillID", skillID))
+			return oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("Skill %d has been deleted", skillID)
+		}
+	}
+
+	return nil
+}
+
+// ensureWorkerEligibleForShift checks the worker holds every live skill the shift requires
+// and that none of those certifications has expired by the shift date. A missing skill is
+// reported with the MissingSkill code and an expired one with the ExpiredSkill code.
+func ensureWorkerEligibleForShift(ctx context.Context, cfg config.Config, shiftRepo repository.ShiftRepository, skillRepo repository.SkillRepository, userID int64, shift *model.Shift, caller string) error {
+	missing, expired, err := checkWorkerSkills(shiftRepo, skillRepo, userID, shift)
+	if err != nil {
+		cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Failed to check worker skills", zap.Int64("userID", userID), zap.Int("shiftID", shift.ID), zap.Error(err))
+		return oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to check worker skills")
+	}
+
+	if len(missing) > 0 {
+		cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Worker lacks required skills", zap.Int64("userID", userID), zap.Strings("missing", missing))
+		return oops.Code(response.MissingSkill.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnprocessableEntity).Errorf("Worker lacks required skills: %s", strings.Join(missing, ", "))
+	}
+
+	if len(expired) > 0 {
+		cfg.Logger().ErrorWithContext(ctx, "["+caller+"] Worker certifications expired", zap.Int64("userID", userID), zap.Strings("expired", expired))
+		return oops.Code(response.ExpiredSkill.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusUnprocessableEntity).Errorf("Worker certifications expired by the shift date: %s", strings.Join(expired, ", "))
+	}
+
+	return nil
+}
+
+// checkWorkerSkills returns the names of the live skills required by the shift that the
+// worker does not hold, and of those whose certification expires before the shift date.
+func checkWorkerSkills(shiftRepo repository.ShiftRepository, skillRepo repository.SkillRepository, userID int64, shift *model.Shift) (missing []string, expired []string, err error) {
+	requiredSkillIDs, err := shiftRepo.GetRequiredSkillIDs(int64(shift.ID))
+	if err != nil || len(requiredSkillIDs) == 0 {
+		return nil, nil, err
+	}
+
+	workerSkills, err := skillRepo.GetWorkerSkillsByUserID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	heldSkills := map[int64]model.WorkerSkill{}
+	for _, workerSkill := range workerSkills {
+		heldSkills[workerSkill.SkillID] = workerSkill
+	}
+
+	for _, skillID := range requiredSkillIDs {
+		skill, err := skillRepo.GetByID(skillID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if skill.DeletedAt.Valid {
+			continue
+		}
+
+		workerSkill, ok := heldSkills[skillID]
+		if !ok {
+			missing = append(missing, skill.Name)
+			continue
+		}
+
+		if !workerSkill.IsValidOn(shift.Date) {
+			expired = append(expired, skill.Name)
+		}
+	}
+
+	return missing, expired, nil
+}
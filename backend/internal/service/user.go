@@ -11,6 +11,7 @@ import (
 	"github.com/andibalo/payd-test/backend/internal/request"
 	"github.com/andibalo/payd-test/backend/internal/response"
 	"github.com/andibalo/payd-test/backend/pkg/httpresp"
+	"github.com/guregu/null/v6"
 	"github.com/samber/oops"
 	"go.uber.org/zap"
 	"net/http"
@@ -39,5 +40,44 @@ func (s *userService) GetWorkerList(ctx context.Context, req request.GetWorkerLi
 		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get user devices")
 	}
 
+	if !req.IncludePayRates {
+		for i := range workers {
+			workers[i].HourlyRate = null.Float{}
+		}
+	}
+
 	return workers, nil
 }
+
+func (s *userService) SetWorkerPayRate(ctx context.Context, req request.SetWorkerPayRateReq) (*model.User, error) {
+
+	user, err := s.userRepo.GetByID(req.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.cfg.Logger().ErrorWithContext(ctx, "[SetWorkerPayRate] User not found", zap.Int64("userID", req.UserID))
+			return nil, oops.Code(response.NotFound.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusNotFound).Errorf("User not found")
+		}
+
+		s.cfg.Logger().ErrorWithContext(ctx, "[SetWorkerPayRate] Failed to get user by id", zap.Int64("userID", req.UserID), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get user by id")
+	}
+
+	if user.Role != constants.WORKER_ROLE {
+		s.cfg.Logger().ErrorWithContext(ctx, "[SetWorkerPayRate] User is not a worker", zap.Int64("userID", req.UserID), zap.String("role", user.Role))
+		return nil, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("User %d is not a worker", req.UserID)
+	}
+
+	err = s.userRepo.UpdateHourlyRateByID(req.UserID, null.FloatFromPtr(req.HourlyRate), req.UserEmail)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[SetWorkerPayRate] Failed to update worker pay rate", zap.Int64("userID", req.UserID), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to update worker pay rate")
+	}
+
+	user, err = s.userRepo.GetByID(req.UserID)
+	if err != nil {
+		s.cfg.Logger().ErrorWithContext(ctx, "[SetWorkerPayRate] Failed to get user by id", zap.Int64("userID", req.UserID), zap.Error(err))
+		return nil, oops.Code(response.ServerError.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusInternalServerError).Errorf("Failed to get user by id")
+	}
+
+	return user, nil
+}
@@ -1,6 +1,6 @@
 package apperr
 
-var (
+const (
 	ErrBadRequest          = "request is incomplete or invalid"
 	ErrUnprocessable       = "request is complete but invalid "
 	ErrInvalidParam        = "invalid input param(s)"
@@ -18,6 +18,6 @@ var (
 )
 
 // User
-var (
+const (
 	ErrDuplicateUser = "user already exists"
 )
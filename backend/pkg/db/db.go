@@ -4,15 +4,29 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/andibalo/payd-test/backend/internal/config"
+	"github.com/andibalo/payd-test/backend/internal/constants"
 	"github.com/andibalo/payd-test/backend/pkg"
 	"go.uber.org/zap"
 	_ "modernc.org/sqlite"
+	"strings"
+	"time"
 )
 
 func InitDB(cfg config.Config) *sql.DB {
 
 	var err error
-	db, err := sql.Open("sqlite", cfg.DBConnString())
+	dsn := cfg.DBConnString()
+	// write time.Time values in a format SQLite's date and time functions understand, so
+	// dates can be compared in SQL
+	dsn = withSQLiteParam(dsn, "_time_format=", "_time_format=sqlite")
+	// wait for a concurrent writer instead of failing with SQLITE_BUSY, so racing updates
+	// reach the version check and surface as conflicts
+	dsn = withSQLiteParam(dsn, "busy_timeout", "_pragma=busy_timeout(5000)")
+	// take the write lock when a transaction begins rather than on its first write, so
+	// checks inside it see every committed write and no other writer commits until it ends
+	dsn = withSQLiteParam(dsn, "_txlock=", "_txlock=immediate")
+
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		cfg.Logger().Error("Failed to connect to db", zap.Error(err))
 		panic("Failed to connect to db")
@@ -36,6 +50,21 @@ func InitDB(cfg config.Config) *sql.DB {
 	return db
 }
 
+// withSQLiteParam appends param to the DSN unless the DSN already sets key, so a value
+// given in the configured connection string wins.
+func withSQLiteParam(dsn, key, param string) string {
+	if strings.Contains(dsn, key) {
+		return dsn
+	}
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+
+	return dsn + separator + param
+}
+
 func initDbTables(db *sql.DB, cfg config.Config) error {
 
 	createUserTableQuery := `CREATE TABLE IF NOT EXISTS users (
@@ -150,6 +179,362 @@ func initDbTables(db *sql.DB, cfg config.Config) error {
 		return err
 	}
 
+	err = uniqueLiveWorkerShiftAssignments(db)
+	if err != nil {
+		cfg.Logger().Error("Error add unique live assignment index to worker_shift_assignments table", zap.Error(err))
+		return err
+	}
+
+	createShiftSeriesTableQuery := `CREATE TABLE IF NOT EXISTS shift_series (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		rrule VARCHAR(255) NOT NULL,
+		start_date DATE NOT NULL,
+		start_time VARCHAR(5) NOT NULL,
+		end_time VARCHAR(5) NOT NULL,
+		role_id INTEGER NOT NULL,
+		location VARCHAR(255),
+		exception_dates TEXT,
+		is_active BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		created_by VARCHAR(100) NOT NULL,
+		updated_at TIMESTAMP,
+		updated_by VARCHAR(100),
+		deleted_at TIMESTAMP,
+		deleted_by VARCHAR(100),
+		FOREIGN KEY (role_id) REFERENCES shift_role_enum(id)
+	);`
+
+	_, err = db.Exec(createShiftSeriesTableQuery)
+
+	if err != nil {
+		cfg.Logger().Error("Error create shift_series table", zap.Error(err))
+		return err
+	}
+
+	err = addColumnIfNotExists(db, "shifts", "series_id", "INTEGER REFERENCES shift_series(id)")
+	if err != nil {
+		cfg.Logger().Error("Error add series_id column to shifts table", zap.Error(err))
+		return err
+	}
+
+	err = addColumnIfNotExists(db, "shifts", "headcount", "INTEGER NOT NULL DEFAULT 1")
+	if err != nil {
+		cfg.Logger().Error("Error add headcount column to shifts table", zap.Error(err))
+		return err
+	}
+
+	err = addColumnIfNotExists(db, "shift_series", "headcount", "INTEGER NOT NULL DEFAULT 1")
+	if err != nil {
+		cfg.Logger().Error("Error add headcount column to shift_series table", zap.Error(err))
+		return err
+	}
+
+	createLocationTableQuery := `CREATE TABLE IF NOT EXISTS locations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name VARCHAR(255) NOT NULL UNIQUE COLLATE NOCASE,
+		address TEXT,
+		latitude REAL,
+		longitude REAL,
+		timezone VARCHAR(64) NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		created_by VARCHAR(100) NOT NULL,
+		updated_at TIMESTAMP,
+		updated_by VARCHAR(100),
+		deleted_at TIMESTAMP,
+		deleted_by VARCHAR(100)
+	);`
+
+	_, err = db.Exec(createLocationTableQuery)
+
+	if err != nil {
+		cfg.Logger().Error("Error create locations table", zap.Error(err))
+		return err
+	}
+
+	err = addColumnIfNotExists(db, "shifts", "location_id", "INTEGER REFERENCES locations(id)")
+	if err != nil {
+		cfg.Logger().Error("Error add location_id column to shifts table", zap.Error(err))
+		return err
+	}
+
+	err = addColumnIfNotExists(db, "shift_series", "location_id", "INTEGER REFERENCES locations(id)")
+	if err != nil {
+		cfg.Logger().Error("Error add location_id column to shift_series table", zap.Error(err))
+		return err
+	}
+
+	err = migrateFreeTextLocations(db)
+	if err != nil {
+		cfg.Logger().Error("Error migrate free-text locations", zap.Error(err))
+		return err
+	}
+
+	err = addColumnIfNotExists(db, "shifts", "timezone", "VARCHAR(64)")
+	if err != nil {
+		cfg.Logger().Error("Error add timezone column to shifts table", zap.Error(err))
+		return err
+	}
+
+	err = addColumnIfNotExists(db, "shift_series", "timezone", "VARCHAR(64)")
+	if err != nil {
+		cfg.Logger().Error("Error add timezone column to shift_series table", zap.Error(err))
+		return err
+	}
+
+	err = migrateShiftSchedules(db)
+	if err != nil {
+		cfg.Logger().Error("Error migrate shift schedules", zap.Error(err))
+		return err
+	}
+
+	err = addColumnIfNotExists(db, "shifts", "status", fmt.Sprintf("VARCHAR(20) NOT NULL DEFAULT '%s'", constants.SHIFT_STATUS_PUBLISHED))
+	if err != nil {
+		cfg.Logger().Error("Error add status column to shifts table", zap.Error(err))
+		return err
+	}
+
+	err = addColumnIfNotExists(db, "shifts", "published_at", "TIMESTAMP")
+	if err != nil {
+		cfg.Logger().Error("Error add published_at column to shifts table", zap.Error(err))
+		return err
+	}
+
+	err = addColumnIfNotExists(db, "shifts", "published_by", "VARCHAR(100)")
+	if err != nil {
+		cfg.Logger().Error("Error add published_by column to shifts table", zap.Error(err))
+		return err
+	}
+
+	err = migrateShiftPublication(db)
+	if err != nil {
+		cfg.Logger().Error("Error migrate shift publication", zap.Error(err))
+		return err
+	}
+
+	err = addColumnIfNotExists(db, "shifts", "cancelled_at", "TIMESTAMP")
+	if err != nil {
+		cfg.Logger().Error("Error add cancelled_at column to shifts table", zap.Error(err))
+		return err
+	}
+
+	err = addColumnIfNotExists(db, "shifts", "cancelled_by", "VARCHAR(100)")
+	if err != nil {
+		cfg.Logger().Error("Error add cancelled_by column to shifts table", zap.Error(err))
+		return err
+	}
+
+	err = addColumnIfNotExists(db, "shifts", "cancel_reason", "VARCHAR(255)")
+	if err != nil {
+		cfg.Logger().Error("Error add cancel_reason column to shifts table", zap.Error(err))
+		return err
+	}
+
+	for _, table := range []string{"shifts", "shift_requests"} {
+		err = addColumnIfNotExists(db, table, "version", "INTEGER NOT NULL DEFAULT 1")
+		if err != nil {
+			cfg.Logger().Error("Error add version column to "+table+" table", zap.Error(err))
+			return err
+		}
+	}
+
+	for _, table := range []string{"shift_role_enum", "users", "shifts"} {
+		err = addColumnIfNotExists(db, table, "hourly_rate", "REAL")
+		if err != nil {
+			cfg.Logger().Error("Error add hourly_rate column to "+table+" table", zap.Error(err))
+			return err
+		}
+	}
+
+	createShiftBreaksTableQuery := `CREATE TABLE IF NOT EXISTS shift_breaks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		shift_id INTEGER NOT NULL,
+		break_type VARCHAR(20) NOT NULL,
+		start_time TIMESTAMP,
+		duration_minutes INTEGER NOT NULL,
+		is_paid BOOLEAN NOT NULL DEFAULT FALSE,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		created_by VARCHAR(100) NOT NULL,
+		FOREIGN KEY (shift_id) REFERENCES shifts(id)
+	);`
+
+	_, err = db.Exec(createShiftBreaksTableQuery)
+
+	if err != nil {
+		cfg.Logger().Error("Error create shift_breaks table", zap.Error(err))
+		return err
+	}
+
+	createSkillsTableQuery := `CREATE TABLE IF NOT EXISTS skills (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name VARCHAR(255) NOT NULL UNIQUE COLLATE NOCASE,
+		description TEXT,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		created_by VARCHAR(100) NOT NULL,
+		updated_at TIMESTAMP,
+		updated_by VARCHAR(100),
+		deleted_at TIMESTAMP,
+		deleted_by VARCHAR(100)
+	);`
+
+	_, err = db.Exec(createSkillsTableQuery)
+
+	if err != nil {
+		cfg.Logger().Error("Error create skills table", zap.Error(err))
+		return err
+	}
+
+	createWorkerSkillsTableQuery := `CREATE TABLE IF NOT EXISTS worker_skills (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		skill_id INTEGER NOT NULL,
+		expires_at DATE,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		created_by VARCHAR(100) NOT NULL,
+		updated_at TIMESTAMP,
+		updated_by VARCHAR(100),
+		deleted_at TIMESTAMP,
+		deleted_by VARCHAR(100),
+		FOREIGN KEY (user_id) REFERENCES users(id),
+		FOREIGN KEY (skill_id) REFERENCES skills(id)
+	);`
+
+	_, err = db.Exec(createWorkerSkillsTableQuery)
+
+	if err != nil {
+		cfg.Logger().Error("Error create worker_skills table", zap.Error(err))
+		return err
+	}
+
+	createShiftRequiredSkillsTableQuery := `CREATE TABLE IF NOT EXISTS shift_required_skills (
+		shift_id INTEGER NOT NULL,
+		skill_id INTEGER NOT NULL,
+		PRIMARY KEY (shift_id, skill_id),
+		FOREIGN KEY (shift_id) REFERENCES shifts(id),
+		FOREIGN KEY (skill_id) REFERENCES skills(id)
+	);`
+
+	_, err = db.Exec(createShiftRequiredSkillsTableQuery)
+
+	if err != nil {
+		cfg.Logger().Error("Error create shift_required_skills table", zap.Error(err))
+		return err
+	}
+
+	createOutboxEventsTableQuery := `CREATE TABLE IF NOT EXISTS outbox_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_type VARCHAR(50) NOT NULL,
+		user_id INTEGER NOT NULL,
+		shift_id INTEGER,
+		payload TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		created_by VARCHAR(100) NOT NULL,
+		dispatched_at TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users(id),
+		FOREIGN KEY (shift_id) REFERENCES shifts(id)
+	);`
+
+	_, err = db.Exec(createOutboxEventsTableQuery)
+
+	if err != nil {
+		cfg.Logger().Error("Error create outbox_events table", zap.Error(err))
+		return err
+	}
+
+	createAuditLogsTableQuery := `CREATE TABLE IF NOT EXISTS audit_logs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		entity_type VARCHAR(50) NOT NULL,
+		entity_id INTEGER NOT NULL,
+		action VARCHAR(50) NOT NULL,
+		actor VARCHAR(100) NOT NULL,
+		changes TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	_, err = db.Exec(createAuditLogsTableQuery)
+
+	if err != nil {
+		cfg.Logger().Error("Error create audit_logs table", zap.Error(err))
+		return err
+	}
+
+	// the audit trail is append-only, so the database refuses to rewrite or drop history
+	auditLogsQueries := []string{
+		`CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs (entity_type, entity_id)`,
+		`CREATE TRIGGER IF NOT EXISTS audit_logs_no_update BEFORE UPDATE ON audit_logs
+		BEGIN
+			SELECT RAISE(ABORT, 'audit_logs is append-only');
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS audit_logs_no_delete BEFORE DELETE ON audit_logs
+		BEGIN
+			SELECT RAISE(ABORT, 'audit_logs is append-only');
+		END`,
+	}
+
+	for _, query := range auditLogsQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			cfg.Logger().Error("Error set up audit_logs table", zap.Error(err))
+			return err
+		}
+	}
+
+	createAutoApprovalRulesTableQuery := `CREATE TABLE IF NOT EXISTS auto_approval_rules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name VARCHAR(255) NOT NULL,
+		role_id INTEGER,
+		location_id INTEGER,
+		min_completed_shifts INTEGER NOT NULL DEFAULT 0,
+		max_hours_before_start INTEGER,
+		is_active BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		created_by VARCHAR(100) NOT NULL,
+		updated_at TIMESTAMP,
+		updated_by VARCHAR(100),
+		deleted_at TIMESTAMP,
+		deleted_by VARCHAR(100),
+		FOREIGN KEY (role_id) REFERENCES shift_role_enum(id),
+		FOREIGN KEY (location_id) REFERENCES locations(id)
+	);`
+
+	_, err = db.Exec(createAutoApprovalRulesTableQuery)
+
+	if err != nil {
+		cfg.Logger().Error("Error create auto_approval_rules table", zap.Error(err))
+		return err
+	}
+
+	createShiftSwapsTableQuery := `CREATE TABLE IF NOT EXISTS shift_swaps (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		proposer_id INTEGER NOT NULL,
+		proposer_assignment_id INTEGER NOT NULL,
+		proposer_shift_id INTEGER NOT NULL,
+		recipient_id INTEGER NOT NULL,
+		recipient_assignment_id INTEGER NOT NULL,
+		recipient_shift_id INTEGER NOT NULL,
+		status VARCHAR(100) NOT NULL,
+		note VARCHAR(255),
+		admin_actor VARCHAR(100),
+		rejection_reason VARCHAR(255),
+		version INTEGER NOT NULL DEFAULT 1,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		created_by VARCHAR(100) NOT NULL,
+		updated_at TIMESTAMP,
+		updated_by VARCHAR(100),
+		FOREIGN KEY (proposer_id) REFERENCES users(id),
+		FOREIGN KEY (proposer_assignment_id) REFERENCES worker_shift_assignments(id),
+		FOREIGN KEY (proposer_shift_id) REFERENCES shifts(id),
+		FOREIGN KEY (recipient_id) REFERENCES users(id),
+		FOREIGN KEY (recipient_assignment_id) REFERENCES worker_shift_assignments(id),
+		FOREIGN KEY (recipient_shift_id) REFERENCES shifts(id)
+	);`
+
+	_, err = db.Exec(createShiftSwapsTableQuery)
+
+	if err != nil {
+		cfg.Logger().Error("Error create shift_swaps table", zap.Error(err))
+		return err
+	}
+
 	createWorkerShiftAvailabilitiesTableQuery := `CREATE TABLE IF NOT EXISTS worker_shift_availabilites (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		user_id INTEGER NOT NULL, 
@@ -170,6 +555,199 @@ func initDbTables(db *sql.DB, cfg config.Config) error {
 	return nil
 }
 
+// addColumnIfNotExists adds a column to an existing table. CREATE TABLE IF NOT EXISTS
+// does not alter tables created by an older version, so new columns are added here.
+func addColumnIfNotExists(db *sql.DB, table, column, definition string) error {
+	exists, err := columnExists(db, table, column)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return nil
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+
+	return err
+}
+
+// uniqueLiveWorkerShiftAssignments lets a worker hold at most one live assignment per shift.
+// Duplicates written before the index existed are ended first, keeping the oldest one.
+func uniqueLiveWorkerShiftAssignments(db *sql.DB) error {
+	endDuplicatesQuery := `
+		UPDATE worker_shift_assignments 
+		SET deleted_at = CURRENT_TIMESTAMP, deleted_by = 'system'
+		WHERE deleted_at IS NULL
+		AND id NOT IN (
+			SELECT MIN(id) FROM worker_shift_assignments 
+			WHERE deleted_at IS NULL 
+			GROUP BY shift_id, user_id
+		)
+	`
+
+	_, err := db.Exec(endDuplicatesQuery)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_worker_shift_assignments_live 
+		ON worker_shift_assignments (shift_id, user_id) 
+		WHERE deleted_at IS NULL
+	`)
+
+	return err
+}
+
+// migrateFreeTextLocations turns the legacy free-text location column of shifts and
+// shift_series into location rows, matching names case-insensitively, and points
+// location_id at them. The legacy column is kept but no longer written.
+func migrateFreeTextLocations(db *sql.DB) error {
+	insertLocationsQuery := `
+		INSERT OR IGNORE INTO locations (name, timezone, created_by, created_at)
+		SELECT name, ?, 'system', CURRENT_TIMESTAMP
+		FROM (
+			SELECT TRIM(location) AS name, 0 AS source, id FROM shifts 
+			WHERE location_id IS NULL AND TRIM(COALESCE(location, '')) != ''
+			UNION ALL
+			SELECT TRIM(location) AS name, 1 AS source, id FROM shift_series 
+			WHERE location_id IS NULL AND TRIM(COALESCE(location, '')) != ''
+		)
+		ORDER BY source, id
+	`
+
+	_, err := db.Exec(insertLocationsQuery, constants.DEFAULT_LOCATION_TIMEZONE)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []string{"shifts", "shift_series"} {
+		_, err = db.Exec(fmt.Sprintf(`
+			UPDATE %s 
+			SET location_id = (SELECT id FROM locations WHERE locations.name = TRIM(%s.location) COLLATE NOCASE)
+			WHERE location_id IS NULL AND TRIM(COALESCE(location, '')) != ''
+		`, table, table))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateShiftSchedules brings shifts written before shifts had a timezone in line with
+// the absolute start/end model. Time-only start/end values are combined with the shift
+// date (an end before the start moves to the next day), the timezone is taken from the
+// location or UTC, and the date is recomputed as the local day the shift starts on.
+func migrateShiftSchedules(db *sql.DB) error {
+	fixTimeOnlyQuery := `
+		UPDATE shifts 
+		SET start_time = strftime('%Y-%m-%d %H:%M:%S', date(date) || ' ' || start_time) || '+00:00',
+			end_time = strftime('%Y-%m-%d %H:%M:%S', date(date) || ' ' || end_time, 
+				CASE WHEN time(end_time) <= time(start_time) THEN '+1 day' ELSE '+0 days' END) || '+00:00'
+		WHERE start_time NOT LIKE '____-__-__%'
+	`
+
+	_, err := db.Exec(fixTimeOnlyQuery)
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.Query(`
+		SELECT shifts.id, shifts.start_time, COALESCE(locations.timezone, ?)
+		FROM shifts
+		LEFT JOIN locations ON shifts.location_id = locations.id
+		WHERE shifts.timezone IS NULL
+	`, constants.DEFAULT_LOCATION_TIMEZONE)
+	if err != nil {
+		return err
+	}
+
+	type shiftSchedule struct {
+		id        int64
+		startTime time.Time
+		timezone  string
+	}
+
+	var schedules []shiftSchedule
+	for rows.Next() {
+		var schedule shiftSchedule
+		err := rows.Scan(&schedule.id, &schedule.startTime, &schedule.timezone)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		schedules = append(schedules, schedule)
+	}
+	rows.Close()
+
+	for _, schedule := range schedules {
+		loc, err := time.LoadLocation(schedule.timezone)
+		if err != nil {
+			return err
+		}
+
+		localStart := schedule.startTime.In(loc)
+		date := time.Date(localStart.Year(), localStart.Month(), localStart.Day(), 0, 0, 0, 0, time.UTC)
+
+		_, err = db.Exec(`UPDATE shifts SET date = ?, timezone = ? WHERE id = ?`, date, loc.String(), schedule.id)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = db.Exec(`
+		UPDATE shift_series 
+		SET timezone = COALESCE((SELECT timezone FROM locations WHERE locations.id = shift_series.location_id), ?)
+		WHERE timezone IS NULL
+	`, constants.DEFAULT_LOCATION_TIMEZONE)
+
+	return err
+}
+
+// migrateShiftPublication marks shifts that existed before the roster lifecycle as published
+// by their creator, since workers could already see them.
+func migrateShiftPublication(db *sql.DB) error {
+	_, err := db.Exec(`
+		UPDATE shifts 
+		SET published_at = created_at, published_by = created_by 
+		WHERE status = ? AND published_at IS NULL
+	`, constants.SHIFT_STATUS_PUBLISHED)
+
+	return err
+}
+
+func columnExists(db *sql.DB, table, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			pk         int
+		)
+
+		err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &pk)
+		if err != nil {
+			return false, err
+		}
+
+		if name == column {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
+}
+
 func seedDatabase(db *sql.DB, cfg config.Config) error {
 	roles := []struct {
 		RoleName  string
@@ -249,6 +827,40 @@ func seedDatabase(db *sql.DB, cfg config.Config) error {
 		cfg.Logger().Info("Users already exist.")
 	}
 
+	var existingLocationCount int
+	err = db.QueryRow(`SELECT COUNT(*) FROM locations`).Scan(&existingLocationCount)
+	if err != nil {
+		cfg.Logger().Error("Error checking for existing locations:", zap.Error(err))
+		return err
+	}
+
+	if existingLocationCount == 0 {
+		locations := []struct {
+			Name     string
+			Address  string
+			Timezone string
+		}{
+			{"Location A", "Jl. Sudirman No. 1, Jakarta", "Asia/Jakarta"},
+			{"Location B", "Jl. Asia Afrika No. 8, Bandung", "Asia/Jakarta"},
+			{"Location C", "Jl. Sunset Road No. 3, Bali", "Asia/Makassar"},
+		}
+
+		for _, location := range locations {
+			locationQuery := `
+				INSERT INTO locations (name, address, timezone, created_at, created_by)
+				VALUES (?, ?, ?, CURRENT_TIMESTAMP, ?)
+			`
+			_, err := db.Exec(locationQuery, location.Name, location.Address, location.Timezone, "system")
+			if err != nil {
+				cfg.Logger().Error("Error inserting location:", zap.Error(err))
+				return err
+			}
+			cfg.Logger().Info("Successfully inserted location: " + location.Name)
+		}
+	} else {
+		cfg.Logger().Info("Locations already exist.")
+	}
+
 	var existingShiftCount int
 	err = db.QueryRow(`SELECT COUNT(*) FROM shifts`).Scan(&existingShiftCount)
 	if err != nil {
@@ -264,17 +876,37 @@ func seedDatabase(db *sql.DB, cfg config.Config) error {
 			RoleID    int
 			Location  string
 		}{
-			{"2025-05-01", "08:00:00", "16:00:00", 1, "Location A"},
-			{"2025-05-02", "09:00:00", "17:00:00", 2, "Location B"},
-			{"2025-05-03", "10:00:00", "18:00:00", 3, "Location C"},
+			{"2025-05-01", "08:00", "16:00", 1, "Location A"},
+			{"2025-05-02", "09:00", "17:00", 2, "Location B"},
+			{"2025-05-03", "22:00", "06:00", 3, "Location C"},
 		}
 
 		for _, shift := range shifts {
+			var timezone string
+			err := db.QueryRow(`SELECT timezone FROM locations WHERE name = ?`, shift.Location).Scan(&timezone)
+			if err != nil {
+				cfg.Logger().Error("Error getting location timezone:", zap.Error(err))
+				return err
+			}
+
+			loc, err := time.LoadLocation(timezone)
+			if err != nil {
+				cfg.Logger().Error("Error loading location timezone:", zap.Error(err))
+				return err
+			}
+
+			date, _ := time.Parse(constants.DATE_FORMAT, shift.Date)
+			startTime, _ := time.ParseInLocation(constants.DATE_FORMAT+" "+constants.TIME_OF_DAY_FORMAT, shift.Date+" "+shift.StartTime, loc)
+			endTime, _ := time.ParseInLocation(constants.DATE_FORMAT+" "+constants.TIME_OF_DAY_FORMAT, shift.Date+" "+shift.EndTime, loc)
+			if !endTime.After(startTime) {
+				endTime = endTime.AddDate(0, 0, 1)
+			}
+
 			shiftQuery := `
-				INSERT INTO shifts (date, start_time, end_time, role_id, location, is_active, created_at, created_by, updated_at, updated_by, deleted_at, deleted_by)
-				VALUES (?, ?, ?, ?, ?, TRUE, CURRENT_TIMESTAMP, ?, ?, ?, ?, ?)
+				INSERT INTO shifts (date, start_time, end_time, timezone, role_id, location_id, is_active, status, published_at, published_by, created_at, created_by, updated_at, updated_by, deleted_at, deleted_by)
+				VALUES (?, ?, ?, ?, ?, (SELECT id FROM locations WHERE name = ?), TRUE, ?, CURRENT_TIMESTAMP, ?, CURRENT_TIMESTAMP, ?, ?, ?, ?, ?)
 			`
-			_, err := db.Exec(shiftQuery, shift.Date, shift.StartTime, shift.EndTime, shift.RoleID, shift.Location, "system", nil, nil, nil, nil)
+			_, err = db.Exec(shiftQuery, date, startTime.UTC(), endTime.UTC(), timezone, shift.RoleID, shift.Location, constants.SHIFT_STATUS_PUBLISHED, "system", "system", nil, nil, nil, nil)
 			if err != nil {
 				cfg.Logger().Error("Error inserting shift:", zap.Error(err))
 				return err
@@ -11,7 +11,9 @@ import (
 )
 
 const (
-	StatusCodeCtxKey = "httpStatusCode"
+	StatusCodeCtxKey   = "httpStatusCode"
+	CurrentStateCtxKey = "currentState"
+	ETagCtxKey         = "etag"
 )
 
 type Meta struct {
@@ -42,14 +44,16 @@ type Pagination struct {
 
 // HTTPErrResp http error response
 type HTTPErrResp struct {
-	Meta    Meta   `json:"metadata"`
-	Success string `json:"success"`
+	Meta    Meta        `json:"metadata"`
+	Data    interface{} `json:"data,omitempty"`
+	Success string      `json:"success"`
 }
 
 func HttpRespError(c *gin.Context, err error) {
 
 	statusCode := http.StatusInternalServerError
 	respCode := response.ServerError.AsString()
+	var currentState interface{}
 
 	oopsErr, ok := oops.AsOops(err)
 
@@ -61,6 +65,12 @@ func HttpRespError(c *gin.Context, err error) {
 		if exists {
 			statusCode = sc.(int)
 		}
+
+		// conflicting writes carry the row as it is now so the client can retry against it
+		currentState = errCtx[CurrentStateCtxKey]
+		if etag, ok := errCtx[ETagCtxKey].(string); ok {
+			c.Header("ETag", etag)
+		}
 	}
 
 	jsonErrResp := &HTTPErrResp{
@@ -73,6 +83,7 @@ func HttpRespError(c *gin.Context, err error) {
 			Error:      err.Error(),
 			Timestamp:  time.Now().Format(time.RFC3339),
 		},
+		Data:    currentState,
 		Success: "false",
 	}
 
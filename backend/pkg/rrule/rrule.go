@@ -0,0 +1,239 @@
+// Package rrule implements the subset of RFC 5545 recurrence rules used for
+// shift series: FREQ=DAILY|WEEKLY with INTERVAL, BYDAY, COUNT and UNTIL.
+package rrule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FreqDaily  = "DAILY"
+	FreqWeekly = "WEEKLY"
+
+	// maxIterationDays guards against rules that never produce an occurrence.
+	maxIterationDays = 366 * 10
+)
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+type Rule struct {
+	Freq     string
+	Interval int
+	ByDay    []time.Weekday
+	Count    int
+	Until    *time.Time
+}
+
+// Parse parses a rule such as "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;COUNT=20".
+// An optional "RRULE:" prefix is accepted.
+func Parse(s string) (*Rule, error) {
+	rule := &Rule{Interval: 1}
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, fmt.Errorf("rrule is empty")
+	}
+
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid rrule part %q", part)
+		}
+
+		key, value := strings.ToUpper(strings.TrimSpace(kv[0])), strings.ToUpper(strings.TrimSpace(kv[1]))
+
+		switch key {
+		case "FREQ":
+			if value != FreqDaily && value != FreqWeekly {
+				return nil, fmt.Errorf("unsupported FREQ %q", value)
+			}
+			rule.Freq = value
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", value)
+			}
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("invalid COUNT %q", value)
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, err := parseDate(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid UNTIL %q", value)
+			}
+			rule.Until = &until
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				wd, ok := weekdays[strings.TrimSpace(day)]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY %q", day)
+				}
+				rule.ByDay = append(rule.ByDay, wd)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rrule part %q", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, fmt.Errorf("COUNT and UNTIL cannot be used together")
+	}
+
+	return rule, nil
+}
+
+// String formats the rule back into its RRULE representation.
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, wd := range r.ByDay {
+			for code, d := range weekdays {
+				if d == wd {
+					days = append(days, code)
+				}
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Between returns the occurrence dates of the rule, starting at dtStart, that
+// fall within [from, to]. All dates are calendar dates at midnight UTC.
+// COUNT is applied from dtStart, so the window does not change which
+// occurrences exist.
+func (r *Rule) Between(dtStart, from, to time.Time) []time.Time {
+	dtStart = truncateDate(dtStart)
+	from = truncateDate(from)
+	to = truncateDate(to)
+
+	occurrences := []time.Time{}
+	produced := 0
+
+	for day := dtStart; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if r.Until != nil && day.After(*r.Until) {
+			break
+		}
+
+		if r.Count > 0 && produced >= r.Count {
+			break
+		}
+
+		if day.Sub(dtStart) > maxIterationDays*24*time.Hour {
+			break
+		}
+
+		if !r.matches(dtStart, day) {
+			continue
+		}
+
+		produced++
+
+		if !day.Before(from) {
+			occurrences = append(occurrences, day)
+		}
+	}
+
+	return occurrences
+}
+
+// CountBefore returns how many occurrences, starting at dtStart, fall strictly before date.
+func (r *Rule) CountBefore(dtStart, date time.Time) int {
+	date = truncateDate(date)
+	if !date.After(truncateDate(dtStart)) {
+		return 0
+	}
+
+	return len(r.Between(dtStart, dtStart, date.AddDate(0, 0, -1)))
+}
+
+func (r *Rule) matches(dtStart, day time.Time) bool {
+	byDay := r.ByDay
+	if len(byDay) == 0 && r.Freq == FreqWeekly {
+		byDay = []time.Weekday{dtStart.Weekday()}
+	}
+
+	if len(byDay) > 0 && !containsWeekday(byDay, day.Weekday()) {
+		return false
+	}
+
+	switch r.Freq {
+	case FreqDaily:
+		days := int(day.Sub(dtStart).Hours() / 24)
+		return days%r.Interval == 0
+	case FreqWeekly:
+		weeks := int(startOfWeek(day).Sub(startOfWeek(dtStart)).Hours() / (24 * 7))
+		return weeks%r.Interval == 0
+	}
+
+	return false
+}
+
+func containsWeekday(days []time.Weekday, wd time.Weekday) bool {
+	for _, d := range days {
+		if d == wd {
+			return true
+		}
+	}
+
+	return false
+}
+
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+
+	return t.AddDate(0, 0, -offset)
+}
+
+func truncateDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func parseDate(s string) (time.Time, error) {
+	for _, layout := range []string{"20060102", "2006-01-02", "20060102T150405Z"} {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return truncateDate(t), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
@@ -0,0 +1,202 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func date(t *testing.T, s string) time.Time {
+	t.Helper()
+
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t.Fatalf("invalid test date %q: %v", s, err)
+	}
+
+	return d
+}
+
+func formatDates(dates []time.Time) []string {
+	formatted := make([]string, 0, len(dates))
+	for _, d := range dates {
+		formatted = append(formatted, d.Format("2006-01-02"))
+	}
+
+	return formatted
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"weekly with days and count", "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=6", "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=6"},
+		{"prefix and lower case", "RRULE:freq=daily;interval=2", "FREQ=DAILY;INTERVAL=2"},
+		{"interval of one is dropped", "FREQ=DAILY;INTERVAL=1", "FREQ=DAILY"},
+		{"dashed until", "FREQ=DAILY;UNTIL=2025-01-31", "FREQ=DAILY;UNTIL=20250131"},
+		{"timestamp until", "FREQ=WEEKLY;UNTIL=20250131T235959Z", "FREQ=WEEKLY;UNTIL=20250131"},
+		{"trailing separator", "FREQ=DAILY;COUNT=3;", "FREQ=DAILY;COUNT=3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.in)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.in, err)
+			}
+
+			if got := rule.String(); got != tt.want {
+				t.Errorf("Parse(%q).String() = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"empty", ""},
+		{"missing freq", "INTERVAL=2"},
+		{"unsupported freq", "FREQ=MONTHLY"},
+		{"part without value", "FREQ"},
+		{"zero interval", "FREQ=DAILY;INTERVAL=0"},
+		{"zero count", "FREQ=DAILY;COUNT=0"},
+		{"invalid until", "FREQ=DAILY;UNTIL=tomorrow"},
+		{"invalid day", "FREQ=WEEKLY;BYDAY=MO,XX"},
+		{"unsupported part", "FREQ=DAILY;BYMONTH=1"},
+		{"count and until", "FREQ=DAILY;COUNT=2;UNTIL=20250101"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.in); err == nil {
+				t.Errorf("Parse(%q) returned no error", tt.in)
+			}
+		})
+	}
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		dtStart string
+		from    string
+		to      string
+		want    []string
+	}{
+		{
+			name:    "weekly days with count",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=4",
+			dtStart: "2025-01-06",
+			from:    "2025-01-06",
+			to:      "2025-01-31",
+			want:    []string{"2025-01-06", "2025-01-08", "2025-01-10", "2025-01-13"},
+		},
+		{
+			name:    "count is applied from the start, not the window",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=4",
+			dtStart: "2025-01-06",
+			from:    "2025-01-08",
+			to:      "2025-01-31",
+			want:    []string{"2025-01-08", "2025-01-10", "2025-01-13"},
+		},
+		{
+			name:    "daily interval",
+			rule:    "FREQ=DAILY;INTERVAL=2",
+			dtStart: "2025-01-01",
+			from:    "2025-01-01",
+			to:      "2025-01-07",
+			want:    []string{"2025-01-01", "2025-01-03", "2025-01-05", "2025-01-07"},
+		},
+		{
+			name:    "daily until is inclusive",
+			rule:    "FREQ=DAILY;UNTIL=20250103",
+			dtStart: "2025-01-01",
+			from:    "2025-01-01",
+			to:      "2025-01-31",
+			want:    []string{"2025-01-01", "2025-01-02", "2025-01-03"},
+		},
+		{
+			name:    "weekly without days repeats the start weekday",
+			rule:    "FREQ=WEEKLY;INTERVAL=2",
+			dtStart: "2025-01-01",
+			from:    "2025-01-01",
+			to:      "2025-02-01",
+			want:    []string{"2025-01-01", "2025-01-15", "2025-01-29"},
+		},
+		{
+			name:    "weekly interval counts weeks from the start week",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TU",
+			dtStart: "2025-01-01",
+			from:    "2025-01-01",
+			to:      "2025-01-20",
+			want:    []string{"2025-01-13", "2025-01-14"},
+		},
+		{
+			name:    "window before the start",
+			rule:    "FREQ=DAILY",
+			dtStart: "2025-01-10",
+			from:    "2025-01-01",
+			to:      "2025-01-09",
+			want:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.rule, err)
+			}
+
+			got := formatDates(rule.Between(date(t, tt.dtStart), date(t, tt.from), date(t, tt.to)))
+			if !equalStrings(got, tt.want) {
+				t.Errorf("Between() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountBefore(t *testing.T) {
+	rule, err := Parse("FREQ=WEEKLY;BYDAY=MO,WE,FR")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	tests := []struct {
+		date string
+		want int
+	}{
+		{"2025-01-01", 0},
+		{"2025-01-06", 0},
+		{"2025-01-07", 1},
+		{"2025-01-10", 2},
+		{"2025-01-13", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			if got := rule.CountBefore(date(t, "2025-01-06"), date(t, tt.date)); got != tt.want {
+				t.Errorf("CountBefore(%s) = %d, want %d", tt.date, got, tt.want)
+			}
+		})
+	}
+}
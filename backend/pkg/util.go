@@ -1,6 +1,8 @@
 package pkg
 
 import (
+	"encoding/base64"
+	"fmt"
 	"github.com/andibalo/payd-test/backend/internal/response"
 	"github.com/andibalo/payd-test/backend/pkg/httpresp"
 	"github.com/gin-gonic/gin"
@@ -36,11 +38,38 @@ func ToPointer[K any](val K) *K {
 	return &val
 }
 
+// GetCursorData splits a "<sort value>_<id>" cursor on its last underscore, so sort values
+// that contain underscores themselves survive the round trip.
 func GetCursorData(cursor string) (string, string) {
 
-	splitCursor := strings.Split(cursor, "_")
+	separatorIdx := strings.LastIndex(cursor, "_")
+	if separatorIdx < 0 {
+		return cursor, ""
+	}
+
+	return cursor[:separatorIdx], cursor[separatorIdx+1:]
+}
 
-	return splitCursor[0], splitCursor[1]
+// EncodeCursor builds an opaque keyset cursor from the sort value and id of a list row.
+func EncodeCursor(sortValue string, id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(sortValue + "_" + strconv.FormatInt(id, 10)))
+}
+
+// DecodeCursor reverses EncodeCursor.
+func DecodeCursor(cursor string) (string, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, err
+	}
+
+	sortValue, rawID := GetCursorData(string(raw))
+
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return sortValue, id, nil
 }
 
 func NullStrToStr(s *string) string {
@@ -63,3 +92,26 @@ func GetIntParam(c *gin.Context, key string) (int64, error) {
 
 	return int64(val), nil
 }
+
+// FormatETag renders a row version as a strong entity tag.
+func FormatETag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// GetIfMatchVersion reads the row version from the If-Match header. It returns 0 when the
+// header is absent or "*", meaning the write is unconditional.
+func GetIfMatchVersion(c *gin.Context) (int, error) {
+	val := strings.TrimSpace(c.GetHeader("If-Match"))
+	if val == "" || val == "*" {
+		return 0, nil
+	}
+
+	val = strings.Trim(strings.TrimPrefix(val, "W/"), `"`)
+
+	version, err := strconv.Atoi(val)
+	if err != nil || version < 1 {
+		return 0, oops.Code(response.BadRequest.AsString()).With(httpresp.StatusCodeCtxKey, http.StatusBadRequest).Errorf("If-Match should be a version ETag, got %q", c.GetHeader("If-Match"))
+	}
+
+	return version, nil
+}
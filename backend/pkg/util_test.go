@@ -0,0 +1,50 @@
+package pkg
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		sortValue string
+		id        int64
+	}{
+		{"time sort value", "2025-01-06 09:00:00", 42},
+		{"sort value with underscores", "night_shift_b", 7},
+		{"empty sort value", "", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortValue, id, err := DecodeCursor(EncodeCursor(tt.sortValue, tt.id))
+			if err != nil {
+				t.Fatalf("DecodeCursor returned error: %v", err)
+			}
+
+			if sortValue != tt.sortValue || id != tt.id {
+				t.Errorf("DecodeCursor() = (%q, %d), want (%q, %d)", sortValue, id, tt.sortValue, tt.id)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "%%%"},
+		{"missing id", base64.RawURLEncoding.EncodeToString([]byte("2025-01-06"))},
+		{"id is not a number", base64.RawURLEncoding.EncodeToString([]byte("2025-01-06_x"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := DecodeCursor(tt.cursor); err == nil {
+				t.Errorf("DecodeCursor(%q) returned no error", tt.cursor)
+			}
+		})
+	}
+}
@@ -15,8 +15,10 @@ import (
 )
 
 type Server struct {
-	gin *gin.Engine
-	srv *http.Server
+	gin          *gin.Engine
+	srv          *http.Server
+	shiftSvc     service.ShiftService
+	retentionSvc service.RetentionService
 }
 
 func NewServer(cfg config.Config, db *sql.DB) *Server {
@@ -28,26 +30,56 @@ func NewServer(cfg config.Config, db *sql.DB) *Server {
 	corsConfig := cors.DefaultConfig()
 
 	corsConfig.AllowAllOrigins = true
-	corsConfig.AllowHeaders = append(corsConfig.AllowHeaders, "Authorization")
+	corsConfig.AllowHeaders = append(corsConfig.AllowHeaders, "Authorization", "If-Match")
+	corsConfig.ExposeHeaders = append(corsConfig.ExposeHeaders, "ETag")
 
 	router.Use(cors.New(corsConfig))
 	router.Use(gin.Recovery())
 
+	transactor := repository.NewTransactor(db)
 	userRepo := repository.NewUserRepository(db)
 	shiftRepo := repository.NewShiftRepository(db)
+	shiftSeriesRepo := repository.NewShiftSeriesRepository(db)
+	shiftRoleRepo := repository.NewShiftRoleRepository(db)
+	locationRepo := repository.NewLocationRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	skillRepo := repository.NewSkillRepository(db)
+	auditLogRepo := repository.NewAuditLogRepository(db)
+	retentionRepo := repository.NewRetentionRepository(db)
+	autoApprovalRuleRepo := repository.NewAutoApprovalRuleRepository(db)
+	shiftSwapRepo := repository.NewShiftSwapRepository(db)
 
 	userSvc := service.NewUserService(cfg, userRepo)
-	authSvc := service.NewAuthService(cfg, userRepo)
-	shiftSvc := service.NewShiftService(cfg, shiftRepo)
+	authSvc := service.NewAuthService(cfg, transactor, userRepo, auditLogRepo)
+	shiftSvc := service.NewShiftService(cfg, transactor, shiftRepo, shiftRoleRepo, locationRepo, eventRepo, skillRepo, auditLogRepo, autoApprovalRuleRepo)
+	shiftSeriesSvc := service.NewShiftSeriesService(cfg, transactor, shiftSeriesRepo, shiftRepo, shiftRoleRepo, locationRepo, eventRepo, auditLogRepo)
+	shiftRoleSvc := service.NewShiftRoleService(cfg, shiftRoleRepo)
+	locationSvc := service.NewLocationService(cfg, locationRepo)
+	eventSvc := service.NewEventService(cfg, eventRepo)
+	skillSvc := service.NewSkillService(cfg, skillRepo, userRepo)
+	auditLogSvc := service.NewAuditLogService(cfg, auditLogRepo)
+	retentionSvc := service.NewRetentionService(cfg, retentionRepo)
+	autoApprovalRuleSvc := service.NewAutoApprovalRuleService(cfg, autoApprovalRuleRepo, shiftRoleRepo, locationRepo)
+	shiftSwapSvc := service.NewShiftSwapService(cfg, transactor, shiftSwapRepo, shiftRepo, skillRepo, eventRepo, auditLogRepo)
 
 	uc := v1.NewUserController(cfg, userSvc)
 	ac := v1.NewAuthController(cfg, authSvc)
 	sc := v1.NewShiftController(cfg, shiftSvc)
+	ssc := v1.NewShiftSeriesController(cfg, shiftSeriesSvc)
+	src := v1.NewShiftRoleController(cfg, shiftRoleSvc)
+	lc := v1.NewLocationController(cfg, locationSvc)
+	ec := v1.NewEventController(cfg, eventSvc)
+	skc := v1.NewSkillController(cfg, skillSvc)
+	alc := v1.NewAuditLogController(cfg, auditLogSvc)
+	aarc := v1.NewAutoApprovalRuleController(cfg, autoApprovalRuleSvc)
+	swc := v1.NewShiftSwapController(cfg, shiftSwapSvc)
 
-	registerHandlers(router, &api.HealthCheck{}, uc, ac, sc)
+	registerHandlers(router, &api.HealthCheck{}, uc, ac, sc, ssc, src, lc, ec, skc, alc, aarc, swc)
 
 	return &Server{
-		gin: router,
+		gin:          router,
+		shiftSvc:     shiftSvc,
+		retentionSvc: retentionSvc,
 	}
 }
 
@@ -67,6 +99,17 @@ func (s *Server) GetGin() *gin.Engine {
 	return s.gin
 }
 
+// StartShiftRequestExpiryJob expires stale pending shift requests on the configured
+// interval until ctx is done.
+func (s *Server) StartShiftRequestExpiryJob(ctx context.Context) {
+	s.shiftSvc.StartShiftRequestExpiryJob(ctx)
+}
+
+// StartRetentionJob purges and archives old data on the configured interval until ctx is done.
+func (s *Server) StartRetentionJob(ctx context.Context) {
+	s.retentionSvc.StartRetentionJob(ctx)
+}
+
 func (s *Server) Shutdown(ctx context.Context) error {
 
 	return s.srv.Shutdown(ctx)
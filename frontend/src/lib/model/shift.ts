@@ -1,3 +1,12 @@
+export interface ShiftLocation {
+  id: number;
+  name: string;
+  address: string | null;
+  latitude: number | null;
+  longitude: number | null;
+  timezone: string;
+}
+
 export interface Shift {
   id: number;
   date: string;
@@ -5,7 +14,7 @@ export interface Shift {
   end_time: string;
   role_id: number;
   role_name: string;
-  location: string;
+  location: ShiftLocation | null;
   is_active: boolean;
   created_at: string;
   created_by: string;
@@ -4,7 +4,7 @@ export interface CreateShiftReq {
     start_time: string;
     end_time: string;
     role_id: number;
-    location?: string;
+    location_id?: number;
 }
 
 export interface GetShiftListReq {